@@ -0,0 +1,163 @@
+package goakeneo
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"go.uber.org/ratelimit"
+)
+
+// FeedbackLimiter is a rate limiter receiving the outcome of the requests,
+// the client reports every response to the limiter set by WithRateLimiter when it implements it
+type FeedbackLimiter interface {
+	ratelimit.Limiter
+	// OnSuccess is called for each successful response
+	OnSuccess()
+	// OnThrottle is called for each throttled response, retryAfter is zero when the server did not send it
+	OnThrottle(retryAfter time.Duration)
+}
+
+// AdaptiveLimiterConfig configures an AdaptiveLimiter, rates are in requests per second
+type AdaptiveLimiterConfig struct {
+	InitialRate    float64       // InitialRate is the starting rate
+	MinRate        float64       // MinRate is the lowest rate the limiter backs off to
+	MaxRate        float64       // MaxRate is the highest rate the limiter ramps up to
+	IncreaseStep   float64       // IncreaseStep is added to the rate after each success
+	DecreaseFactor float64       // DecreaseFactor multiplies the rate after a throttled response, between 0 and 1
+	Cooldown       time.Duration // Cooldown is the minimum time between two decreases, throttled bursts count once
+}
+
+// DefaultAdaptiveLimiterConfig returns a config starting at the default rate limit
+func DefaultAdaptiveLimiterConfig() AdaptiveLimiterConfig {
+	return AdaptiveLimiterConfig{
+		InitialRate:    defaultRateLimit,
+		MinRate:        1,
+		MaxRate:        4 * defaultRateLimit,
+		IncreaseStep:   0.05, // +1 request per second every 20 successes
+		DecreaseFactor: 0.5,
+		Cooldown:       time.Second,
+	}
+}
+
+func (cfg AdaptiveLimiterConfig) validate() error {
+	switch {
+	case cfg.MinRate <= 0 || cfg.MaxRate < cfg.MinRate:
+		return errors.New("adaptive limiter rates must be positive and max rate greater than min rate")
+	case cfg.InitialRate < cfg.MinRate || cfg.InitialRate > cfg.MaxRate:
+		return errors.New("adaptive limiter initial rate must be between min rate and max rate")
+	case cfg.IncreaseStep < 0:
+		return errors.New("adaptive limiter increase step must be positive")
+	case cfg.DecreaseFactor <= 0 || cfg.DecreaseFactor >= 1:
+		return errors.New("adaptive limiter decrease factor must be between 0 and 1")
+	default:
+		return nil
+	}
+}
+
+// AdaptiveLimiter is a rate limiter backing off on throttled responses and Retry-After,
+// and slowly ramping up on success.
+// It is safe for concurrent use and can be shared by several clients pointing to the same PIM
+type AdaptiveLimiter struct {
+	mu            sync.Mutex
+	cfg           AdaptiveLimiterConfig
+	rate          float64
+	last          time.Time // last is the time of the last granted request
+	pausedUntil   time.Time // pausedUntil is set from the Retry-After header
+	lastDecreased time.Time
+	now           func() time.Time
+	sleep         func(time.Duration)
+}
+
+// NewAdaptiveLimiter creates an adaptive limiter
+func NewAdaptiveLimiter(cfg AdaptiveLimiterConfig) (*AdaptiveLimiter, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &AdaptiveLimiter{
+		cfg:   cfg,
+		rate:  cfg.InitialRate,
+		now:   time.Now,
+		sleep: time.Sleep,
+	}, nil
+}
+
+// WithRateLimiter sets the rate limiter of the client, it can be shared by several clients.
+// The limiter gets the outcome of the requests when it implements FeedbackLimiter
+func WithRateLimiter(l ratelimit.Limiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// Take blocks until the next request is allowed
+func (l *AdaptiveLimiter) Take() time.Time {
+	l.mu.Lock()
+	now := l.now()
+	next := l.last.Add(time.Duration(float64(time.Second) / l.rate))
+	if next.Before(l.pausedUntil) {
+		next = l.pausedUntil
+	}
+	if next.Before(now) {
+		next = now
+	}
+	l.last = next
+	l.mu.Unlock()
+	if wait := next.Sub(now); wait > 0 {
+		l.sleep(wait)
+	}
+	return next
+}
+
+// OnSuccess ramps up the rate
+func (l *AdaptiveLimiter) OnSuccess() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate += l.cfg.IncreaseStep
+	if l.rate > l.cfg.MaxRate {
+		l.rate = l.cfg.MaxRate
+	}
+}
+
+// OnThrottle backs off the rate and pauses the requests for retryAfter
+func (l *AdaptiveLimiter) OnThrottle(retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if retryAfter > 0 && now.Add(retryAfter).After(l.pausedUntil) {
+		l.pausedUntil = now.Add(retryAfter)
+	}
+	if now.Sub(l.lastDecreased) < l.cfg.Cooldown {
+		return
+	}
+	l.lastDecreased = now
+	l.rate *= l.cfg.DecreaseFactor
+	if l.rate < l.cfg.MinRate {
+		l.rate = l.cfg.MinRate
+	}
+}
+
+// Rate returns the current rate in requests per second
+func (l *AdaptiveLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// limiterFeedback reports a response to the limiter when it is a FeedbackLimiter,
+// execute reports the last response of a request and onRetry the retried ones
+func (c *Client) limiterFeedback(resp *resty.Response, _ error) {
+	fl, ok := c.limiter.(FeedbackLimiter)
+	if !ok || resp == nil || resp.RawResponse == nil {
+		return
+	}
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header().Get("Retry-After"), time.Now())
+	switch {
+	case resp.StatusCode() == http.StatusTooManyRequests || hasRetryAfter:
+		fl.OnThrottle(retryAfter)
+	case !resp.IsError():
+		fl.OnSuccess()
+	}
+}
@@ -0,0 +1,102 @@
+package goakeneo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdaptiveLimiter(t *testing.T, cfg AdaptiveLimiterConfig) (*AdaptiveLimiter, *time.Time, *time.Duration) {
+	t.Helper()
+	l, err := NewAdaptiveLimiter(cfg)
+	assert.NoError(t, err)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	var slept time.Duration
+	l.now = func() time.Time { return now }
+	l.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+	return l, &now, &slept
+}
+
+func TestAdaptiveLimiter_Feedback(t *testing.T) {
+	cfg := DefaultAdaptiveLimiterConfig()
+	l, now, _ := newTestAdaptiveLimiter(t, cfg)
+	assert.Equal(t, float64(defaultRateLimit), l.Rate())
+
+	l.OnThrottle(0)
+	assert.Equal(t, 2.5, l.Rate())
+	// throttled bursts within the cooldown count once
+	l.OnThrottle(0)
+	assert.Equal(t, 2.5, l.Rate())
+	*now = now.Add(cfg.Cooldown)
+	l.OnThrottle(0)
+	l.OnThrottle(0)
+	*now = now.Add(cfg.Cooldown)
+	l.OnThrottle(0)
+	assert.Equal(t, cfg.MinRate, l.Rate())
+
+	for i := 0; i < 20; i++ {
+		l.OnSuccess()
+	}
+	assert.InDelta(t, 2, l.Rate(), 1e-9)
+	for i := 0; i < 1000; i++ {
+		l.OnSuccess()
+	}
+	assert.Equal(t, cfg.MaxRate, l.Rate())
+}
+
+func TestAdaptiveLimiter_Take(t *testing.T) {
+	cfg := DefaultAdaptiveLimiterConfig()
+	cfg.InitialRate = 10
+	l, _, slept := newTestAdaptiveLimiter(t, cfg)
+	l.Take()
+	l.Take()
+	l.Take()
+	assert.Equal(t, 200*time.Millisecond, *slept)
+
+	l.OnThrottle(3 * time.Second)
+	l.Take()
+	assert.Equal(t, 3200*time.Millisecond, *slept)
+}
+
+func TestAdaptiveLimiter_Validate(t *testing.T) {
+	_, err := NewAdaptiveLimiter(AdaptiveLimiterConfig{})
+	assert.Error(t, err)
+	cfg := DefaultAdaptiveLimiterConfig()
+	cfg.DecreaseFactor = 1
+	_, err = NewAdaptiveLimiter(cfg)
+	assert.Error(t, err)
+}
+
+func TestWithRateLimiter_SharedAdaptiveLimiter(t *testing.T) {
+	throttle := true
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if throttle {
+			throttle = false
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":"shoes"}`))
+	})
+	cfg := DefaultAdaptiveLimiterConfig()
+	cfg.InitialRate = 100
+	cfg.MaxRate = 200
+	l, err := NewAdaptiveLimiter(cfg)
+	assert.NoError(t, err)
+	c1 := newTestClient(t, srv, WithRateLimiter(l), WithRetryPolicy(testRetryPolicy()))
+	c2 := newTestClient(t, srv, WithRateLimiter(l), WithRetryPolicy(testRetryPolicy()))
+
+	_, err = c1.Family.GetFamily("shoes", nil)
+	assert.NoError(t, err)
+	// throttled once then one success
+	assert.InDelta(t, 50.05, l.Rate(), 1e-9)
+	_, err = c2.Family.GetFamily("shoes", nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 50.1, l.Rate(), 1e-9)
+}
@@ -2,14 +2,17 @@ package goakeneo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/go-resty/resty/v2"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -31,24 +34,29 @@ func (c Connector) NewClient(opts ...Option) (*Client, error) {
 
 // Client is the main struct to use to interact with the Akeneo API
 type Client struct {
-	connector    Connector
-	baseURL      *url.URL
-	httpClient   *http.Client
-	token        string            // token is the access token
-	refreshToken string            // refreshToken is the refresh token
-	tokenExp     time.Time         // tokenExp is the token expiration time,5 minutes before the actual expiration
-	osVersion    int               // osVersion is the version of the OS,default pim 6
-	retryCNT     int               // retryCNT is the retry count
-	limiter      ratelimit.Limiter // limiter, default 5 requests per second
-	Auth         AuthService
-	Product      ProductService
-	Family       FamilyService
-	Attribute    AttributeService
-	Category     CategoryService
-	Channel      ChannelService
-	Locale       LocaleService
-	MediaFile    MediaFileService
-	ProductModel ProductModelService
+	connector      Connector
+	baseURL        *url.URL
+	httpClient     *http.Client
+	osVersion      int               // osVersion is the version of the OS,default pim 6
+	retry          RetryPolicy       // retry is the retry policy, see DefaultRetryPolicy
+	limiter        ratelimit.Limiter // limiter, default 5 requests per second
+	logger         *slog.Logger      // logger, discards every record by default
+	hooks          Instrumentation   // hooks receive metrics and spans, no-op by default
+	breaker        *CircuitBreaker   // breaker fails the requests fast while the PIM is down, nil by default
+	recorder       *recorder         // recorder records or replays the requests, nil by default
+	catalogTTL     time.Duration     // catalogTTL is the lifetime of the Catalog entries
+	Auth           AuthService
+	Product        ProductService
+	Family         FamilyService
+	Attribute      AttributeService
+	AttributeGroup AttributeGroupService
+	Category       CategoryService
+	Channel        ChannelService
+	Locale         LocaleService
+	Currency       CurrencyService
+	MediaFile      MediaFileService
+	ProductModel   ProductModelService
+	Catalog        *Catalog // Catalog caches the definitions of the catalog
 }
 
 func (c *Client) validate() error {
@@ -69,6 +77,12 @@ func (c *Client) validate() error {
 	if _, ok := pimVersionMap[c.osVersion]; !ok {
 		return errors.Errorf("invalid osVersion %d", c.osVersion)
 	}
+	if err := c.retry.validate(); err != nil {
+		return err
+	}
+	if c.recorder != nil && c.recorder.err != nil {
+		return c.recorder.err
+	}
 	return nil
 }
 
@@ -92,9 +106,12 @@ func NewClient(con Connector, opts ...Option) (*Client, error) {
 				MaxIdleConns: 10,
 			},
 		},
-		connector: con,
-		osVersion: defaultVersion,
-		retryCNT:  defaultRetry,
+		connector:  con,
+		osVersion:  defaultVersion,
+		retry:      DefaultRetryPolicy(),
+		logger:     slog.New(discardHandler{}),
+		hooks:      nopInstrumentation{},
+		catalogTTL: defaultCatalogTTL,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -102,16 +119,41 @@ func NewClient(con Connector, opts ...Option) (*Client, error) {
 	if err := c.validate(); err != nil {
 		return nil, err
 	}
-	// Set services
-	c.Auth = &authOp{c}
-	c.Product = &productOp{c}
-	c.Family = &familyOp{c}
-	c.Attribute = &attributeOp{c}
-	c.Category = &categoryOp{c}
-	c.Channel = &channelOp{c}
-	c.Locale = &localeOp{c}
-	c.MediaFile = &mediaOp{c}
-	c.ProductModel = &productModelOp{c}
+	// Set the services which are not replaced by an option
+	if c.Auth == nil {
+		c.Auth = &authOp{client: c}
+	}
+	if c.Product == nil {
+		c.Product = &productOp{c}
+	}
+	if c.Family == nil {
+		c.Family = &familyOp{c}
+	}
+	if c.Attribute == nil {
+		c.Attribute = &attributeOp{c}
+	}
+	if c.AttributeGroup == nil {
+		c.AttributeGroup = &attributeGroupOp{c}
+	}
+	if c.Category == nil {
+		c.Category = &categoryOp{c}
+	}
+	if c.Channel == nil {
+		c.Channel = &channelOp{c}
+	}
+	if c.Locale == nil {
+		c.Locale = &localeOp{c}
+	}
+	if c.Currency == nil {
+		c.Currency = &currencyOp{c}
+	}
+	if c.MediaFile == nil {
+		c.MediaFile = &mediaOp{c}
+	}
+	if c.ProductModel == nil {
+		c.ProductModel = &productModelOp{c}
+	}
+	c.Catalog = newCatalog(c, c.catalogTTL)
 	if err := c.init(); err != nil {
 		return nil, err
 	}
@@ -142,11 +184,53 @@ func WithVersion(v int) Option {
 	}
 }
 
-// WithRetry sets the retry count of the Akeneo API
+// WithRetry sets the retry count of the Akeneo API,
+// use WithRetryPolicy for the other retry settings
 func WithRetry(cnt int) Option {
 	return func(c *Client) {
-		c.retryCNT = cnt
+		c.retry.MaxAttempts = cnt + 1
+	}
+}
+
+// WithCatalogTTL sets the lifetime of the Catalog entries, they never expire when ttl is not positive
+func WithCatalogTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.catalogTTL = ttl
+	}
+}
+
+// accessToken returns the token of the default auth service, a replaced AuthService sets no token
+func (c *Client) accessToken() string {
+	if a, ok := c.Auth.(*authOp); ok {
+		return a.accessToken()
 	}
+	return ""
+}
+
+// restyClient creates a resty client with the retry policy of the client
+func (c *Client) restyClient() *resty.Client {
+	return c.retry.apply(resty.NewWithClient(c.httpClient)).
+		AddRetryHook(c.onRetry).
+		OnBeforeRequest(c.limitRetry)
+}
+
+// limitRetry waits for the rate limit before a retry, execute waits before the first attempt
+func (c *Client) limitRetry(_ *resty.Client, r *resty.Request) error {
+	if r.Attempt > 1 {
+		c.limiter.Take()
+	}
+	return nil
+}
+
+// onRetry logs and instruments a retry and gives the response to the limiter, it is used as a resty retry hook.
+// resty also calls the hooks after the last attempt, which is not retried, they are skipped then
+func (c *Client) onRetry(resp *resty.Response, err error) {
+	if resp != nil && resp.Request != nil && resp.Request.Attempt >= c.retry.MaxAttempts {
+		return
+	}
+	c.logRetry(resp, err)
+	c.instrumentRetry(resp, err)
+	c.limiterFeedback(resp, err)
 }
 
 // createAndDoGetHeaders create a request and get the headers
@@ -162,18 +246,12 @@ func (c *Client) createAndDoGetHeaders(method, relPath string, opts, data, resul
 	u := c.baseURL.ResolveReference(rel)
 
 	var errResp ErrorResponse
-	client := resty.NewWithClient(c.httpClient).
-		SetRetryCount(c.retryCNT).
-		SetRetryWaitTime(defaultRetryWaitTime).
-		SetRetryMaxWaitTime(defaultRetryMaxWaitTime).
-		AddRetryCondition(func(r *resty.Response, err error) bool {
-			return r.StatusCode() == http.StatusTooManyRequests
-		})
+	client := c.restyClient()
 	request := client.R().
 		SetHeader("Content-Type", defaultContentType).
 		SetHeader("Accept", defaultAccept).
 		SetHeader("User-Agent", defaultUserAgent).
-		SetAuthToken(c.token).
+		SetAuthToken(c.accessToken()).
 		SetError(&errResp)
 	if result != nil {
 		request.SetResult(result)
@@ -210,9 +288,7 @@ func (c *Client) createAndDoGetHeaders(method, relPath string, opts, data, resul
 	if data != nil {
 		request.SetBody(data)
 	}
-	// rate limit
-	c.limiter.Take()
-	resp, err := request.Execute(method, u.String())
+	resp, err := c.execute(context.Background(), request, method, u)
 	if err != nil {
 		return http.Header{}, errors.Wrap(err, "resty execute error")
 	}
@@ -223,77 +299,180 @@ func (c *Client) createAndDoGetHeaders(method, relPath string, opts, data, resul
 	return resp.Header(), nil
 }
 
-func (c *Client) download(downloadURL string, fp string) error {
+// download streams the resource at u to w, an interrupted transfer is resumed with a Range request
+// as long as the previous attempt received some bytes. The size announced by the responses is verified,
+// and size when it is not 0
+func (c *Client) download(ctx context.Context, u *url.URL, w io.Writer, size int64) error {
+	sink := &downloadSink{w: w, size: -1}
+	for stalled := 0; ; {
+		err := c.downloadFrom(ctx, u, sink, sink.n)
+		var readErr *downloadReadError
+		if !errors.As(err, &readErr) {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "download interrupted")
+		}
+		if readErr.received > 0 {
+			stalled = 0
+		}
+		if stalled++; stalled > defaultDownloadResumes {
+			return errors.Wrapf(readErr.err, "download interrupted after %d bytes", sink.n)
+		}
+		c.logger.Warn("akeneo download resumed", slog.String("path", u.Path), slog.Int64("offset", sink.n), slog.Any("error", readErr.err))
+	}
+	if sink.size >= 0 && sink.n != sink.size {
+		return errors.Wrapf(ErrSizeMismatch, "downloaded %d bytes, the response announced %d", sink.n, sink.size)
+	}
+	if size > 0 && sink.n != size {
+		return errors.Wrapf(ErrSizeMismatch, "downloaded %d bytes, expected %d", sink.n, size)
+	}
+	return nil
+}
+
+// closeRetriedBody closes the body of a download response which is retried, it is used as a resty retry hook.
+// The bodies are not read by resty with SetDoNotParseResponse, the body of the last attempt is left to the caller
+func (c *Client) closeRetriedBody(resp *resty.Response, _ error) {
+	if resp == nil || resp.RawResponse == nil || resp.Request == nil || resp.Request.Attempt >= c.retry.MaxAttempts {
+		return
+	}
+	_ = resp.RawResponse.Body.Close()
+}
+
+// contentSize returns the size of the whole file from the Content-Length of a full response
+// or from the Content-Range of a partial one, -1 when it is unknown
+func contentSize(resp *http.Response) int64 {
+	if resp.StatusCode != http.StatusPartialContent {
+		return resp.ContentLength
+	}
+	contentRange := resp.Header.Get("Content-Range")
+	if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+		if size, err := strconv.ParseInt(contentRange[i+1:], 10, 64); err == nil {
+			return size
+		}
+	}
+	return -1
+}
+
+// downloadFrom sends one download request starting at offset and copies the body to sink,
+// a failure while reading the body is returned as a *downloadReadError
+func (c *Client) downloadFrom(ctx context.Context, u *url.URL, sink *downloadSink, offset int64) error {
 	if err := c.Auth.AutoRefreshToken(); err != nil {
 		return err
 	}
-	client := resty.NewWithClient(c.httpClient).
-		SetRetryCount(c.retryCNT).
-		SetRetryWaitTime(defaultRetryWaitTime).
-		SetRetryMaxWaitTime(defaultRetryMaxWaitTime).
-		AddRetryCondition(func(r *resty.Response, err error) bool {
-			return r.StatusCode() == http.StatusTooManyRequests
-		})
-	request := client.R().
+	request := c.restyClient().AddRetryHook(c.closeRetriedBody).R().
 		SetHeader("User-Agent", defaultUserAgent).
-		SetAuthToken(c.token)
-	// rate limit
-	c.limiter.Take()
-	resp, err := request.
-		Get(downloadURL)
+		SetAuthToken(c.accessToken()).
+		SetDoNotParseResponse(true)
+	if offset > 0 {
+		request.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := c.execute(ctx, request, http.MethodGet, u)
 	if err != nil {
+		if resp != nil && resp.RawResponse != nil {
+			_ = resp.RawResponse.Body.Close()
+		}
 		return errors.Wrap(err, "resty execute get error")
 	}
-	// 如果是404，说明文件不存在
+	body := resp.RawBody()
+	defer body.Close()
 	if resp.StatusCode() == http.StatusNotFound {
-		return errors.Errorf("file not found : %s", downloadURL)
+		return errors.Errorf("file not found : %s", u.Path)
 	}
 	if resp.IsError() {
 		var errResp ErrorResponse
-		if err := json.Unmarshal(resp.Body(), &errResp); err != nil {
+		if err := json.NewDecoder(body).Decode(&errResp); err != nil {
 			return errors.Wrap(err, "unmarshal error")
 		}
 		return errors.Errorf("request error :error Code: %d, error message: %s", errResp.Code, errResp.Message)
 	}
-	dir := filepath.Dir(fp)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return errors.Wrapf(err, "failed to create dir, path: %s", dir)
+	size := contentSize(resp.RawResponse)
+	if offset == 0 {
+		sink.size = size
+	} else if size >= 0 && sink.size >= 0 && size != sink.size {
+		return errors.Wrapf(ErrSizeMismatch, "the size changed from %d to %d bytes during the download", sink.size, size)
+	}
+	if offset > 0 && resp.StatusCode() != http.StatusPartialContent {
+		// the server ignored the range and sends the whole file again
+		if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+			return &downloadReadError{err: err}
 		}
 	}
-	file, err := os.Create(fp)
-	if err != nil {
-		return errors.Wrapf(err, "failed to create file, path: %s", fp)
+	n, err := io.Copy(sink, body)
+	if sink.err != nil {
+		return errors.Wrap(sink.err, "failed to write file")
 	}
-	defer file.Close()
-	if _, err := io.Copy(file, bytes.NewReader(resp.Body())); err != nil {
-		return errors.Wrap(err, "failed to copy file")
+	if err != nil {
+		return &downloadReadError{err: err, received: n}
 	}
 	return nil
 }
 
-func (c *Client) upload(endpoint string, data any) (string, error) {
-	if err := c.Auth.AutoRefreshToken(); err != nil {
-		return "", err
+// downloadReadError is a failure while reading a download body, the transfer can be resumed
+type downloadReadError struct {
+	err      error
+	received int64
+}
+
+func (e *downloadReadError) Error() string {
+	return e.err.Error()
+}
+
+// downloadSink counts the written bytes and keeps the write error apart from the read errors of io.Copy
+type downloadSink struct {
+	w    io.Writer
+	n    int64
+	size int64 // size is the size announced by the first response, -1 when it is unknown
+	err  error
+}
+
+func (ds *downloadSink) Write(p []byte) (int, error) {
+	n, err := ds.w.Write(p)
+	ds.n += int64(n)
+	if err != nil {
+		ds.err = err
 	}
+	return n, err
+}
+
+// uploadBody returns the content type and a new streamed body of an upload, the body is closed once sent
+type uploadBody func() (contentType string, body io.ReadCloser, err error)
+
+// upload posts a streamed body and returns the Location of the created resource.
+// The request is retried by the retry policy when replayable, newBody is then called for every attempt
+func (c *Client) upload(ctx context.Context, endpoint string, newBody uploadBody, replayable bool) (string, error) {
 	pathURL, _ := url.Parse(endpoint)
-	uploadURL := c.baseURL.ResolveReference(pathURL).String()
-	client := resty.NewWithClient(c.httpClient).
-		SetRetryCount(c.retryCNT).
-		SetRetryWaitTime(defaultRetryWaitTime).
-		SetRetryMaxWaitTime(defaultRetryMaxWaitTime).
-		AddRetryCondition(func(r *resty.Response, err error) bool {
-			return r.StatusCode() == http.StatusTooManyRequests
-		})
-	request := client.R().
-		SetHeader("User-Agent", defaultUserAgent).
-		SetAuthToken(c.token).
-		SetHeader("Content-Type", defaultUploadContentType)
-	// rate limit
-	c.limiter.Take()
-	resp, err := request.
-		SetBody(data).
-		Post(uploadURL)
+	u := c.baseURL.ResolveReference(pathURL)
+	attempts := 1
+	if replayable {
+		attempts = c.retry.MaxAttempts
+	}
+	var resp *resty.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		contentType, body, bodyErr := newBody()
+		if bodyErr != nil {
+			return "", bodyErr
+		}
+		resp, err = c.uploadOnce(ctx, u, contentType, body)
+		if attempt >= attempts || !c.retry.shouldRetry(resp, err) {
+			break
+		}
+		if resp != nil && resp.Request != nil {
+			// resty counts the attempts of its own retries only
+			resp.Request.Attempt = attempt
+		}
+		c.logRetry(resp, err)
+		c.instrumentRetry(resp, err)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(c.retry.backoff(resp)):
+		}
+	}
 	if err != nil {
 		return "", errors.Wrap(err, "resty execute post error")
 	}
@@ -307,6 +486,121 @@ func (c *Client) upload(endpoint string, data any) (string, error) {
 	return resp.Header().Get("Location"), nil
 }
 
+// uploadOnce sends one attempt of an upload, body is closed once sent
+func (c *Client) uploadOnce(ctx context.Context, u *url.URL, contentType string, body io.ReadCloser) (*resty.Response, error) {
+	// unblock a body writer when the request stopped before reading the whole body
+	defer body.Close()
+	if err := c.Auth.AutoRefreshToken(); err != nil {
+		return nil, err
+	}
+	request := resty.NewWithClient(c.httpClient).R().
+		SetHeader("User-Agent", defaultUserAgent).
+		SetAuthToken(c.accessToken()).
+		SetHeader("Content-Type", contentType).
+		SetBody(body)
+	return c.execute(ctx, request, http.MethodPost, u)
+}
+
+// patchCollection updates or creates several resources at once,
+// items must marshal to a JSON array, see:
+// https://api.akeneo.com/documentation/update.html#patch-multiple-resources
+func (c *Client) patchCollection(relPath string, items any) (PatchProductResponse, error) {
+	if err := c.Auth.AutoRefreshToken(); err != nil {
+		return nil, err
+	}
+	body, err := collectionBody(items)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := url.Parse(relPath)
+	if err != nil {
+		return nil, err
+	}
+	u := c.baseURL.ResolveReference(rel)
+	request := c.restyClient().R().
+		SetHeader("Content-Type", defaultPatchContentType).
+		SetHeader("Accept", defaultAccept).
+		SetHeader("User-Agent", defaultUserAgent).
+		SetAuthToken(c.accessToken()).
+		SetBody(body)
+	resp, err := c.execute(context.Background(), request, http.MethodPatch, u)
+	if err != nil {
+		return nil, errors.Wrap(err, "resty execute patch error")
+	}
+	if resp.IsError() {
+		var errResp ErrorResponse
+		if err := json.Unmarshal(resp.Body(), &errResp); err != nil {
+			return nil, errors.Wrap(err, "unmarshal error")
+		}
+		return nil, errors.Errorf("request error :error Code: %d, error message: %s", errResp.Code, errResp.Message)
+	}
+	// the response holds one json object per line
+	var result PatchProductResponse
+	decoder := json.NewDecoder(bytes.NewReader(resp.Body()))
+	for decoder.More() {
+		var line PatchProductResponseLine
+		if err := decoder.Decode(&line); err != nil {
+			return nil, errors.Wrap(err, "unmarshal patch response line error")
+		}
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+// collectionBody converts a slice of resources to one json object per line
+func collectionBody(items any) (string, error) {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal collection error")
+	}
+	var lines []json.RawMessage
+	if err := json.Unmarshal(b, &lines); err != nil {
+		return "", errors.Wrap(err, "collection must be a slice")
+	}
+	var body bytes.Buffer
+	for _, line := range lines {
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+	return body.String(), nil
+}
+
+// execute executes the request under the rate limit, logs and instruments it
+func (c *Client) execute(ctx context.Context, request *resty.Request, method string, u *url.URL) (resp *resty.Response, err error) {
+	if c.breaker != nil {
+		done, openErr := c.breaker.allow()
+		if openErr != nil {
+			return nil, openErr
+		}
+		defer func() {
+			done(isFailure(resp, err))
+		}()
+	}
+	info := newRequestInfo(method, u)
+	ctx = c.hooks.OnRequestStart(ctx, info)
+	request.SetContext(ctx)
+	// rate limit
+	wait := time.Now()
+	c.limiter.Take()
+	start := time.Now()
+	resp, err = request.Execute(method, u.String())
+	c.logRequest(method, u, resp, start, err)
+	// the retried responses have already been reported by onRetry, the last one is not
+	c.limiterFeedback(resp, err)
+	result := RequestResult{
+		Duration:      time.Since(start),
+		RateLimitWait: start.Sub(wait),
+		Err:           err,
+	}
+	if resp != nil {
+		result.StatusCode = resp.StatusCode()
+		result.Attempts = resp.Request.Attempt
+		result.Bytes = resp.Size()
+	}
+	c.hooks.OnRequestEnd(ctx, info, result)
+	return resp, err
+}
+
 // GET creates a get request and execute it
 // result must be a pointer to a struct
 func (c *Client) GET(relPath string, ops, data, result any) error {
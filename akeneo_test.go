@@ -0,0 +1,51 @@
+package goakeneo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ezifyio/go-akeneo/akeneotest"
+)
+
+// newFakeClient creates a client for a fake PIM seeded from testdata
+func newFakeClient(t *testing.T, opts ...Option) (*Client, *akeneotest.Server) {
+	t.Helper()
+	srv := akeneotest.NewServer()
+	t.Cleanup(srv.Close)
+	if err := srv.LoadFixtures("testdata"); err != nil {
+		t.Fatal(err)
+	}
+	con := Connector{ClientID: srv.ClientID, Secret: srv.Secret, UserName: srv.Username, Password: srv.Password}
+	c, err := con.NewClient(append([]Option{WithBaseURL(srv.URL)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, srv
+}
+
+// newTestServer starts a server answering the token endpoint and delegating the other requests to h
+func newTestServer(t *testing.T, h http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+authBasePath {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"secret-access","refresh_token":"secret-refresh","expires_in":3600}`))
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestClient creates a client for the test server
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	con := Connector{ClientID: "id", Secret: "client-secret", UserName: "user", Password: "user-password"}
+	c, err := con.NewClient(append([]Option{WithBaseURL(srv.URL)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
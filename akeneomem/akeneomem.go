@@ -0,0 +1,226 @@
+// Package akeneomem provides in-memory implementations of the goakeneo service interfaces backed by maps,
+// to test code depending on a *goakeneo.Client without any HTTP server.
+//
+//	b := akeneomem.New()
+//	b.Products.Put(goakeneo.Product{Identifier: "sku-1", Family: "shoes"})
+//	client, err := b.Client()
+//
+// List methods honor the page and limit options, the other options such as search filters are ignored.
+package akeneomem
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/google/go-querystring/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+	baseURL      = "http://akeneomem.invalid"
+)
+
+// ErrNotFound is returned when a resource is not in the backend
+var ErrNotFound = errors.New("resource not found")
+
+// Backend holds one in-memory service per resource
+type Backend struct {
+	Auth            *AuthService
+	Products        *ProductService
+	ProductModels   *ProductModelService
+	Families        *FamilyService
+	Attributes      *AttributeService
+	AttributeGroups *AttributeGroupService
+	Categories      *CategoryService
+	Channels        *ChannelService
+	Locales         *LocaleService
+	Currencies      *CurrencyService
+	MediaFiles      *MediaFileService
+}
+
+// New returns an empty backend
+func New() *Backend {
+	b := &Backend{
+		Auth:            &AuthService{},
+		Products:        NewProductService(),
+		ProductModels:   NewProductModelService(),
+		Families:        NewFamilyService(),
+		Attributes:      NewAttributeService(),
+		AttributeGroups: NewAttributeGroupService(),
+		Categories:      NewCategoryService(),
+		Channels:        NewChannelService(),
+		Locales:         NewLocaleService(),
+		Currencies:      NewCurrencyService(),
+		MediaFiles:      NewMediaFileService(),
+	}
+	// created media files are linked to the products of the backend
+	b.MediaFiles.products = b.Products
+	b.MediaFiles.models = b.ProductModels
+	return b
+}
+
+// Options returns the client options replacing every service with the backend ones
+func (b *Backend) Options() []goakeneo.Option {
+	return []goakeneo.Option{
+		goakeneo.WithBaseURL(baseURL),
+		goakeneo.WithAuthService(b.Auth),
+		goakeneo.WithProductService(b.Products),
+		goakeneo.WithProductModelService(b.ProductModels),
+		goakeneo.WithFamilyService(b.Families),
+		goakeneo.WithAttributeService(b.Attributes),
+		goakeneo.WithAttributeGroupService(b.AttributeGroups),
+		goakeneo.WithCategoryService(b.Categories),
+		goakeneo.WithChannelService(b.Channels),
+		goakeneo.WithLocaleService(b.Locales),
+		goakeneo.WithCurrencyService(b.Currencies),
+		goakeneo.WithMediaFileService(b.MediaFiles),
+	}
+}
+
+// Client returns a client served by the backend, opts are applied after the backend options
+func (b *Backend) Client(opts ...goakeneo.Option) (*goakeneo.Client, error) {
+	con := goakeneo.Connector{
+		ClientID: "akeneomem",
+		Secret:   "akeneomem",
+		UserName: "akeneomem",
+		Password: "akeneomem",
+	}
+	return con.NewClient(append(b.Options(), opts...)...)
+}
+
+// AuthService is an auth service which never calls the PIM
+type AuthService struct{}
+
+// GrantByPassword does nothing
+func (a *AuthService) GrantByPassword() error { return nil }
+
+// GrantByRefreshToken does nothing
+func (a *AuthService) GrantByRefreshToken() error { return nil }
+
+// ShouldRefreshToken always returns false
+func (a *AuthService) ShouldRefreshToken() bool { return false }
+
+// AutoRefreshToken does nothing
+func (a *AuthService) AutoRefreshToken() error { return nil }
+
+// collection is a concurrency safe map of resources listed in key order
+type collection[T any] struct {
+	mu    sync.RWMutex
+	key   func(T) string
+	items map[string]T
+}
+
+func newCollection[T any](key func(T) string) *collection[T] {
+	return &collection[T]{key: key, items: make(map[string]T)}
+}
+
+func (c *collection[T]) put(items ...T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, item := range items {
+		c.items[c.key(item)] = item
+	}
+}
+
+func (c *collection[T]) get(key string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.items[key]
+	return item, ok
+}
+
+func (c *collection[T]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+func (c *collection[T]) list() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.items))
+	for k := range c.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	items := make([]T, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, c.items[k])
+	}
+	return items
+}
+
+// page lists one page of the collection
+func (c *collection[T]) page(path string, options any) ([]T, goakeneo.Links, error) {
+	return paginate(c.list(), path, options)
+}
+
+// paginate cuts one page of items according to the page and limit options,
+// the next link keeps the other options so Links.NextOptions can be passed back
+func paginate[T any](items []T, path string, options any) ([]T, goakeneo.Links, error) {
+	values, err := optionValues(options)
+	if err != nil {
+		return nil, goakeneo.Links{}, err
+	}
+	page, limit := 1, defaultLimit
+	if v := values.Get("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return nil, goakeneo.Links{}, errors.Errorf("invalid page %q", v)
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxLimit {
+			return nil, goakeneo.Links{}, errors.Errorf("invalid limit %q", v)
+		}
+	}
+	link := func(p int) goakeneo.Link {
+		q := url.Values{}
+		for k, v := range values {
+			q[k] = v
+		}
+		q.Set("page", strconv.Itoa(p))
+		q.Set("limit", strconv.Itoa(limit))
+		return goakeneo.Link{Href: baseURL + path + "?" + q.Encode()}
+	}
+	links := goakeneo.Links{Self: link(page), First: link(1)}
+	if page > 1 {
+		links.Previous = link(page - 1)
+	}
+	start := (page - 1) * limit
+	if start >= len(items) {
+		return []T{}, links, nil
+	}
+	end := start + limit
+	if end < len(items) {
+		links.Next = link(page + 1)
+	} else {
+		end = len(items)
+	}
+	return items[start:end], links, nil
+}
+
+// optionValues converts the list options, url.Values or a struct with url tags, to url.Values
+func optionValues(options any) (url.Values, error) {
+	switch o := options.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		return o, nil
+	default:
+		v, err := query.Values(options)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to convert options to url.Values")
+		}
+		return v, nil
+	}
+}
+
+// notFound wraps ErrNotFound with the resource description
+func notFound(format string, args ...any) error {
+	return errors.Wrapf(ErrNotFound, format, args...)
+}
@@ -0,0 +1,158 @@
+package akeneomem
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackendClient(t *testing.T) {
+	b := New()
+	b.Families.Put(goakeneo.Family{Code: "shoes"})
+	b.Products.Put(
+		goakeneo.Product{Identifier: "sku-1", Family: "shoes"},
+		goakeneo.Product{Identifier: "sku-2", Family: "shoes"},
+		goakeneo.Product{Identifier: "sku-3", Family: "shoes"},
+	)
+	c, err := b.Client()
+	assert.NoError(t, err)
+	assert.Same(t, b.Products, c.Product)
+
+	p, err := c.Product.GetProduct("sku-2", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "shoes", p.Family)
+	_, err = c.Product.GetProduct("unknown", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	prods, links, err := c.Product.ListWithPagination(goakeneo.ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, prods, 2)
+	assert.True(t, links.HasNext())
+	prods, links, err = c.Product.ListWithPagination(links.NextOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, "sku-3", prods[0].Identifier)
+	assert.False(t, links.HasNext())
+
+	var identifiers []string
+	prodChan, errChan := c.Product.GetAllProducts(context.Background(), onePerPage())
+	for p := range prodChan {
+		identifiers = append(identifiers, p.Identifier)
+	}
+	assert.NoError(t, <-errChan)
+	assert.Equal(t, []string{"sku-1", "sku-2", "sku-3"}, identifiers)
+}
+
+// onePerPage lists one product per page
+func onePerPage() goakeneo.ListOptions {
+	return goakeneo.ListOptions{Limit: 1}
+}
+
+func TestProductService_UpdateOrCreateProducts(t *testing.T) {
+	s := NewProductService(goakeneo.Product{
+		Identifier: "sku-1",
+		Family:     "shoes",
+		Values: map[string][]goakeneo.ProductValue{
+			"name":  {{Locale: "en_US", Data: "Sneaker"}, {Locale: "fr_FR", Data: "Basket"}},
+			"color": {{Data: "red"}},
+		},
+	})
+	resp, err := s.UpdateOrCreateProducts([]goakeneo.Product{
+		{Identifier: "sku-1", Values: map[string][]goakeneo.ProductValue{
+			"name":  {{Locale: "en_US", Data: "Runner"}},
+			"color": {{Data: nil}},
+		}},
+		{Identifier: "sku-2", Family: "shoes"},
+		{Family: "shoes"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{http.StatusNoContent, http.StatusCreated, http.StatusUnprocessableEntity},
+		[]int{resp[0].StatusCode, resp[1].StatusCode, resp[2].StatusCode})
+
+	p, err := s.GetProduct("sku-1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "shoes", p.Family)
+	assert.NotContains(t, p.Values, "color")
+	assert.ElementsMatch(t, []goakeneo.ProductValue{{Locale: "fr_FR", Data: "Basket"}, {Locale: "en_US", Data: "Runner"}}, p.Values["name"])
+}
+
+func TestFamilyService(t *testing.T) {
+	s := NewFamilyService()
+	assert.NoError(t, s.CreateFamily(goakeneo.Family{Code: "shoes"}))
+	assert.Error(t, s.CreateFamily(goakeneo.Family{Code: "shoes"}))
+	assert.ErrorIs(t, s.UpdateOrCreate("unknown", "by_size", goakeneo.FamilyVariant{}), ErrNotFound)
+	assert.NoError(t, s.UpdateOrCreate("shoes", "by_size", goakeneo.FamilyVariant{Lables: map[string]string{"en_US": "By size"}}))
+	variants, err := s.GetFamilyVariants("shoes", nil)
+	assert.NoError(t, err)
+	assert.Len(t, variants, 1)
+	assert.Equal(t, "by_size", variants[0].Code)
+}
+
+func TestMediaFileService_Create(t *testing.T) {
+	b := New()
+	b.Products.Put(goakeneo.Product{Identifier: "sku-1"})
+	filePath := filepath.Join(t.TempDir(), "image.png")
+	assert.NoError(t, os.WriteFile(filePath, []byte("png"), 0644))
+
+	code, err := b.MediaFiles.Create(filePath, goakeneo.AssociatedProduct{Identifier: "sku-1", Attribute: "image"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]/[0-9a-f]/[0-9a-f]/[0-9a-f]/[0-9a-f]{40}_image\.png$`, code)
+	p, err := b.Products.GetProduct("sku-1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, code, p.Values["image"][0].Data)
+
+	out := filepath.Join(t.TempDir(), "out.png")
+	assert.NoError(t, b.MediaFiles.Download(code, out, nil))
+	content, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "png", string(content))
+}
+
+func TestProductService_PatchProducts(t *testing.T) {
+	old := goakeneo.Product{
+		Identifier: "sku-1",
+		Family:     "shoes",
+		Values: map[string][]goakeneo.ProductValue{
+			"name":  {{Locale: "en_US", Data: "Sneaker"}},
+			"color": {{Data: "red"}},
+		},
+	}
+	s := NewProductService(old)
+	patch := old.Patch().SetFamily("").ClearValue("color", "", "").Build()
+	resp, err := s.PatchProducts([]goakeneo.ProductPatch{patch, {"identifier": "sku-2", "family": "shoes"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{http.StatusNoContent, http.StatusCreated}, []int{resp[0].StatusCode, resp[1].StatusCode})
+
+	p, err := s.GetProduct("sku-1", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, p.Family)
+	assert.NotContains(t, p.Values, "color")
+	assert.Equal(t, "Sneaker", p.Values["name"][0].Data)
+	p, err = s.GetProduct("sku-2", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "shoes", p.Family)
+}
+
+func TestProductService_PatchProductsByUUID(t *testing.T) {
+	old := goakeneo.Product{UUID: "5c8a3f1e-2b4d-4e6f-8a9b-0c1d2e3f4a5b", Identifier: "sku-1", Family: "shoes"}
+	b := New()
+	b.Products.Put(old)
+	c, err := b.Client()
+	assert.NoError(t, err)
+	renamed := old
+	renamed.Identifier = "sku-1-eu"
+	resp, err := c.PatchProducts([]goakeneo.ProductPatch{goakeneo.DiffProducts(old, renamed)})
+	assert.NoError(t, err)
+	assert.Equal(t, goakeneo.PatchProductResponse{{Line: 1, UUID: old.UUID, StatusCode: http.StatusNoContent}}, resp)
+
+	_, err = c.Product.GetProduct("sku-1", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	p, err := c.Product.GetProduct("sku-1-eu", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, old.UUID, p.UUID)
+	assert.Equal(t, "shoes", p.Family)
+}
@@ -0,0 +1,86 @@
+package akeneomem
+
+import (
+	"path"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+
+// AttributeService is an in-memory goakeneo.AttributeService, attributes and their options are keyed by code
+type AttributeService struct {
+	items   *collection[goakeneo.Attribute]
+	options *collection[goakeneo.AttributeOption]
+}
+
+// NewAttributeService returns an attribute service holding attributes
+func NewAttributeService(attributes ...goakeneo.Attribute) *AttributeService {
+	s := &AttributeService{
+		items:   newCollection(func(a goakeneo.Attribute) string { return a.Code }),
+		options: newCollection(func(o goakeneo.AttributeOption) string { return o.Attribute + "/" + o.Code }),
+	}
+	s.items.put(attributes...)
+	return s
+}
+
+// Put adds or replaces attributes
+func (s *AttributeService) Put(attributes ...goakeneo.Attribute) {
+	s.items.put(attributes...)
+}
+
+// PutOptions adds or replaces options of an attribute
+func (s *AttributeService) PutOptions(attributeCode string, options ...goakeneo.AttributeOption) {
+	for _, o := range options {
+		o.Attribute = attributeCode
+		s.options.put(o)
+	}
+}
+
+// ListWithPagination lists attributes with pagination
+func (s *AttributeService) ListWithPagination(options any) ([]goakeneo.Attribute, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/attributes", options)
+}
+
+// GetAttribute gets an attribute by code
+func (s *AttributeService) GetAttribute(code string, options any) (*goakeneo.Attribute, error) {
+	a, ok := s.items.get(code)
+	if !ok {
+		return nil, notFound("attribute %s", code)
+	}
+	return &a, nil
+}
+
+// GetAttributeOptions lists the options of an attribute with pagination
+func (s *AttributeService) GetAttributeOptions(code string, options any) ([]goakeneo.AttributeOption, goakeneo.Links, error) {
+	if _, ok := s.items.get(code); !ok {
+		return nil, goakeneo.Links{}, notFound("attribute %s", code)
+	}
+	var attributeOptions []goakeneo.AttributeOption
+	for _, o := range s.options.list() {
+		if o.Attribute == code {
+			attributeOptions = append(attributeOptions, o)
+		}
+	}
+	return paginate(attributeOptions, path.Join("/api/rest/v1/attributes", code, "options"), options)
+}
+
+// AttributeGroupService is an in-memory goakeneo.AttributeGroupService, attribute groups are keyed by code
+type AttributeGroupService struct {
+	items *collection[goakeneo.AttributeGroup]
+}
+
+// NewAttributeGroupService returns an attribute group service holding groups
+func NewAttributeGroupService(groups ...goakeneo.AttributeGroup) *AttributeGroupService {
+	s := &AttributeGroupService{items: newCollection(func(g goakeneo.AttributeGroup) string { return g.Code })}
+	s.items.put(groups...)
+	return s
+}
+
+// Put adds or replaces attribute groups
+func (s *AttributeGroupService) Put(groups ...goakeneo.AttributeGroup) {
+	s.items.put(groups...)
+}
+
+// ListWithPagination lists attribute groups with pagination
+func (s *AttributeGroupService) ListWithPagination(options any) ([]goakeneo.AttributeGroup, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/attribute-groups", options)
+}
@@ -0,0 +1,102 @@
+package akeneomem
+
+import (
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+
+// CategoryService is an in-memory goakeneo.CategoryService, categories are keyed by code
+type CategoryService struct {
+	items *collection[goakeneo.Category]
+}
+
+// NewCategoryService returns a category service holding categories
+func NewCategoryService(categories ...goakeneo.Category) *CategoryService {
+	s := &CategoryService{items: newCollection(func(c goakeneo.Category) string { return c.Code })}
+	s.items.put(categories...)
+	return s
+}
+
+// Put adds or replaces categories
+func (s *CategoryService) Put(categories ...goakeneo.Category) {
+	s.items.put(categories...)
+}
+
+// ListWithPagination lists categories with pagination
+func (s *CategoryService) ListWithPagination(options any) ([]goakeneo.Category, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/categories", options)
+}
+
+// Get gets a category by code
+func (s *CategoryService) Get(code string) (*goakeneo.Category, error) {
+	c, ok := s.items.get(code)
+	if !ok {
+		return nil, notFound("category %s", code)
+	}
+	return &c, nil
+}
+
+// ChannelService is an in-memory goakeneo.ChannelService, channels are keyed by code
+type ChannelService struct {
+	items *collection[goakeneo.Channel]
+}
+
+// NewChannelService returns a channel service holding channels
+func NewChannelService(channels ...goakeneo.Channel) *ChannelService {
+	s := &ChannelService{items: newCollection(func(c goakeneo.Channel) string { return c.Code })}
+	s.items.put(channels...)
+	return s
+}
+
+// Put adds or replaces channels
+func (s *ChannelService) Put(channels ...goakeneo.Channel) {
+	s.items.put(channels...)
+}
+
+// ListWithPagination lists channels with pagination
+func (s *ChannelService) ListWithPagination(options any) ([]goakeneo.Channel, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/channels", options)
+}
+
+// LocaleService is an in-memory goakeneo.LocaleService, locales are keyed by code
+type LocaleService struct {
+	items *collection[goakeneo.Locale]
+}
+
+// NewLocaleService returns a locale service holding locales
+func NewLocaleService(locales ...goakeneo.Locale) *LocaleService {
+	s := &LocaleService{items: newCollection(func(l goakeneo.Locale) string { return l.Code })}
+	s.items.put(locales...)
+	return s
+}
+
+// Put adds or replaces locales
+func (s *LocaleService) Put(locales ...goakeneo.Locale) {
+	s.items.put(locales...)
+}
+
+// ListWithPagination lists locales with pagination
+func (s *LocaleService) ListWithPagination(options any) ([]goakeneo.Locale, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/locales", options)
+}
+
+// CurrencyService is an in-memory goakeneo.CurrencyService, currencies are keyed by code
+type CurrencyService struct {
+	items *collection[goakeneo.Currency]
+}
+
+// NewCurrencyService returns a currency service holding currencies
+func NewCurrencyService(currencies ...goakeneo.Currency) *CurrencyService {
+	s := &CurrencyService{items: newCollection(func(c goakeneo.Currency) string { return c.Code })}
+	s.items.put(currencies...)
+	return s
+}
+
+// Put adds or replaces currencies
+func (s *CurrencyService) Put(currencies ...goakeneo.Currency) {
+	s.items.put(currencies...)
+}
+
+// ListWithPagination lists currencies with pagination
+func (s *CurrencyService) ListWithPagination(options any) ([]goakeneo.Currency, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/currencies", options)
+}
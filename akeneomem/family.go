@@ -0,0 +1,113 @@
+package akeneomem
+
+import (
+	"path"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/pkg/errors"
+)
+
+// FamilyService is an in-memory goakeneo.FamilyService, families and their variants are keyed by code
+type FamilyService struct {
+	items    *collection[goakeneo.Family]
+	variants *collection[familyVariant]
+}
+
+// familyVariant is a family variant with the code of its family
+type familyVariant struct {
+	family  string
+	variant goakeneo.FamilyVariant
+}
+
+func familyVariantKey(v familyVariant) string {
+	return v.family + "/" + v.variant.Code
+}
+
+// NewFamilyService returns a family service holding families
+func NewFamilyService(families ...goakeneo.Family) *FamilyService {
+	s := &FamilyService{
+		items:    newCollection(func(f goakeneo.Family) string { return f.Code }),
+		variants: newCollection(familyVariantKey),
+	}
+	s.items.put(families...)
+	return s
+}
+
+// Put adds or replaces families
+func (s *FamilyService) Put(families ...goakeneo.Family) {
+	s.items.put(families...)
+}
+
+// PutVariants adds or replaces variants of a family
+func (s *FamilyService) PutVariants(familyCode string, variants ...goakeneo.FamilyVariant) {
+	for _, v := range variants {
+		s.variants.put(familyVariant{family: familyCode, variant: v})
+	}
+}
+
+// ListWithPagination lists families with pagination
+func (s *FamilyService) ListWithPagination(options any) ([]goakeneo.Family, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/families", options)
+}
+
+// GetFamily gets a family by code
+func (s *FamilyService) GetFamily(familyCode string, options any) (*goakeneo.Family, error) {
+	f, ok := s.items.get(familyCode)
+	if !ok {
+		return nil, notFound("family %s", familyCode)
+	}
+	return &f, nil
+}
+
+// GetFamilyVariants lists one page of the variants of a family
+func (s *FamilyService) GetFamilyVariants(familyCode string, options any) ([]goakeneo.FamilyVariant, error) {
+	if _, ok := s.items.get(familyCode); !ok {
+		return nil, notFound("family %s", familyCode)
+	}
+	var variants []goakeneo.FamilyVariant
+	for _, v := range s.variants.list() {
+		if v.family == familyCode {
+			variants = append(variants, v.variant)
+		}
+	}
+	variants, _, err := paginate(variants, path.Join("/api/rest/v1/families", familyCode, "variants"), options)
+	return variants, err
+}
+
+// GetFamilyVariant gets a variant of a family
+func (s *FamilyService) GetFamilyVariant(familyCode string, familyVariantCode string) (*goakeneo.FamilyVariant, error) {
+	v, ok := s.variants.get(familyVariantKey(familyVariant{family: familyCode, variant: goakeneo.FamilyVariant{Code: familyVariantCode}}))
+	if !ok {
+		return nil, notFound("family variant %s", path.Join(familyCode, familyVariantCode))
+	}
+	return &v.variant, nil
+}
+
+// CreateFamily creates a family, it fails when the code is already used
+func (s *FamilyService) CreateFamily(family goakeneo.Family) error {
+	if family.Code == "" {
+		return errors.New("code is required")
+	}
+	if _, ok := s.items.get(family.Code); ok {
+		return errors.Errorf("family %s already exists", family.Code)
+	}
+	s.items.put(family)
+	return nil
+}
+
+// UpdateOrCreate merges or creates a family variant
+func (s *FamilyService) UpdateOrCreate(familyCode, familyVariantCode string, fv goakeneo.FamilyVariant) error {
+	if _, ok := s.items.get(familyCode); !ok {
+		return notFound("family %s", familyCode)
+	}
+	fv.Code = familyVariantCode
+	if existing, err := s.GetFamilyVariant(familyCode, familyVariantCode); err == nil {
+		merged, err := mergeResource(*existing, fv)
+		if err != nil {
+			return err
+		}
+		fv = merged
+	}
+	s.variants.put(familyVariant{family: familyCode, variant: fv})
+	return nil
+}
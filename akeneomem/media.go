@@ -0,0 +1,166 @@
+package akeneomem
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/pkg/errors"
+)
+
+// MediaFileService is an in-memory goakeneo.MediaFileService, media files are keyed by code
+type MediaFileService struct {
+	items    *collection[mediaFile]
+	products *ProductService      // products receive the values of the created files, may be nil
+	models   *ProductModelService // models receive the values of the created files, may be nil
+}
+
+// mediaFile is a media file with its content
+type mediaFile struct {
+	file    goakeneo.MediaFile
+	content []byte
+}
+
+// NewMediaFileService returns an empty media file service
+func NewMediaFileService() *MediaFileService {
+	return &MediaFileService{items: newCollection(func(m mediaFile) string { return m.file.Code })}
+}
+
+// Put adds or replaces a media file with its content, the size is set from the content
+func (s *MediaFileService) Put(file goakeneo.MediaFile, content []byte) {
+	file.Size = len(content)
+	s.items.put(mediaFile{file: file, content: content})
+}
+
+// Content returns the content of a media file
+func (s *MediaFileService) Content(code string) ([]byte, bool) {
+	m, ok := s.items.get(code)
+	return m.content, ok
+}
+
+// ListPagination lists media files with pagination
+func (s *MediaFileService) ListPagination(options any) ([]goakeneo.MediaFile, goakeneo.Links, error) {
+	var files []goakeneo.MediaFile
+	for _, m := range s.items.list() {
+		files = append(files, m.file)
+	}
+	return paginate(files, "/api/rest/v1/media-files", options)
+}
+
+// GetByCode gets a media file by code
+func (s *MediaFileService) GetByCode(code string, options any) (*goakeneo.MediaFile, error) {
+	m, ok := s.items.get(code)
+	if !ok {
+		return nil, notFound("media file %s", code)
+	}
+	return &m.file, nil
+}
+
+// Download writes the content of a media file to filePath
+func (s *MediaFileService) Download(code, filePath string, options any) error {
+	m, ok := s.items.get(code)
+	if !ok {
+		return notFound("media file %s", code)
+	}
+	if err := os.WriteFile(filePath, m.content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write file %s", filePath)
+	}
+	return nil
+}
+
+// DownloadTo writes the content of a media file to w
+func (s *MediaFileService) DownloadTo(ctx context.Context, code string, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m, ok := s.items.get(code)
+	if !ok {
+		return notFound("media file %s", code)
+	}
+	if _, err := w.Write(m.content); err != nil {
+		return errors.Wrap(err, "failed to write file")
+	}
+	return nil
+}
+
+// Create stores a local file, see CreateFromReader
+func (s *MediaFileService) Create(filePath string, association goakeneo.MediaFileAssociation) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open file %s", filePath)
+	}
+	defer f.Close()
+	return s.CreateFromReader(context.Background(), filepath.Base(filePath), f, association)
+}
+
+// CreateFromReader stores the content of r under a PIM like code
+// and sets it as the value of the associated product or product model
+func (s *MediaFileService) CreateFromReader(ctx context.Context, filename string, r io.Reader, association goakeneo.MediaFileAssociation) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read file %s", filename)
+	}
+	name := path.Base(filename)
+	sum := sha1.Sum(content)
+	hash := hex.EncodeToString(sum[:])
+	code := path.Join(hash[0:1], hash[1:2], hash[2:3], hash[3:4], hash+"_"+name)
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	s.Put(goakeneo.MediaFile{
+		Code:             code,
+		OriginalFilename: name,
+		MimeType:         mime.TypeByExtension(filepath.Ext(name)),
+		Extension:        ext,
+	}, content)
+	if err := s.link(code, association); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// link sets the media file as the value of the associated resource
+func (s *MediaFileService) link(code string, association goakeneo.MediaFileAssociation) error {
+	if association == nil {
+		return nil
+	}
+	var target struct {
+		Identifier string `json:"identifier"`
+		Code       string `json:"code"`
+		Attribute  string `json:"attribute"`
+		Scope      string `json:"scope"`
+		Locale     string `json:"locale"`
+	}
+	if err := json.Unmarshal([]byte(association.ToJSONString()), &target); err != nil {
+		return errors.Wrap(err, "invalid association")
+	}
+	value := map[string][]goakeneo.ProductValue{
+		target.Attribute: {{Locale: target.Locale, Scope: target.Scope, Data: code}},
+	}
+	switch {
+	case association.Type() == "product" && s.products != nil:
+		p, err := s.products.GetProduct(target.Identifier, nil)
+		if err != nil {
+			return err
+		}
+		p.Values = mergeValues(p.Values, value)
+		s.products.Put(*p)
+	case association.Type() == "product_model" && s.models != nil:
+		pm, err := s.models.GetProductModel(target.Code, nil)
+		if err != nil {
+			return err
+		}
+		pm.Values = mergeValues(pm.Values, value)
+		s.models.Put(*pm)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package akeneomem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/pkg/errors"
+)
+
+// ProductService is an in-memory goakeneo.ProductService, products are keyed by identifier
+type ProductService struct {
+	items *collection[goakeneo.Product]
+}
+
+// NewProductService returns a product service holding products
+func NewProductService(products ...goakeneo.Product) *ProductService {
+	s := &ProductService{items: newCollection(productKey)}
+	s.items.put(products...)
+	return s
+}
+
+// productKey is the identifier of the product, or its uuid when it has none
+func productKey(p goakeneo.Product) string {
+	if p.Identifier != "" {
+		return p.Identifier
+	}
+	return p.UUID
+}
+
+// Put adds or replaces products
+func (s *ProductService) Put(products ...goakeneo.Product) {
+	s.items.put(products...)
+}
+
+// Delete removes a product by identifier
+func (s *ProductService) Delete(identifier string) {
+	s.items.delete(identifier)
+}
+
+// GetAllProducts sends every product to the returned channel
+func (s *ProductService) GetAllProducts(ctx context.Context, options any) (<-chan goakeneo.Product, chan error) {
+	prodChan := make(chan goakeneo.Product, 1)
+	errChan := make(chan error)
+	go func() {
+		defer close(errChan)
+		defer close(prodChan)
+		prods, links, err := s.ListWithPagination(options)
+		for {
+			if err != nil {
+				errChan <- err
+				return
+			}
+			for _, prod := range prods {
+				select {
+				case <-ctx.Done():
+					return
+				case prodChan <- prod:
+				}
+			}
+			if !links.HasNext() {
+				return
+			}
+			prods, links, err = s.ListWithPagination(links.NextOptions())
+		}
+	}()
+	return prodChan, errChan
+}
+
+// ListWithPagination lists products with pagination
+func (s *ProductService) ListWithPagination(options any) ([]goakeneo.Product, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/products", options)
+}
+
+// GetProduct gets a product by its identifier or uuid
+func (s *ProductService) GetProduct(id string, options any) (*goakeneo.Product, error) {
+	if p, ok := s.items.get(id); ok {
+		return &p, nil
+	}
+	for _, p := range s.items.list() {
+		if p.UUID != "" && p.UUID == id {
+			return &p, nil
+		}
+	}
+	return nil, notFound("product %s", id)
+}
+
+// UpdateOrCreateProducts merges the products like the PIM does,
+// the values of a product are replaced per attribute, locale and scope
+func (s *ProductService) UpdateOrCreateProducts(products []goakeneo.Product) (goakeneo.PatchProductResponse, error) {
+	result := make(goakeneo.PatchProductResponse, 0, len(products))
+	for i, p := range products {
+		line := goakeneo.PatchProductResponseLine{Line: i + 1, Identifier: p.Identifier}
+		key := productKey(p)
+		if key == "" {
+			line.StatusCode = http.StatusUnprocessableEntity
+			line.Message = "identifier is required"
+			result = append(result, line)
+			continue
+		}
+		line.StatusCode = http.StatusCreated
+		if existing, ok := s.items.get(key); ok {
+			merged, err := mergeResource(existing, p)
+			if err != nil {
+				return nil, err
+			}
+			merged.Values = mergeValues(existing.Values, p.Values)
+			p = merged
+			line.StatusCode = http.StatusNoContent
+		}
+		s.items.put(p)
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+// PatchProducts applies product patches like the PIM does, a null property or value data removes it
+func (s *ProductService) PatchProducts(patches []goakeneo.ProductPatch) (goakeneo.PatchProductResponse, error) {
+	result := make(goakeneo.PatchProductResponse, 0, len(patches))
+	for i, patch := range patches {
+		var p goakeneo.Product
+		if err := remarshal(patch, &p); err != nil {
+			return nil, err
+		}
+		line := goakeneo.PatchProductResponseLine{Line: i + 1, Identifier: p.Identifier}
+		key := productKey(p)
+		if key == "" {
+			line.StatusCode = http.StatusUnprocessableEntity
+			line.Message = "identifier is required"
+			result = append(result, line)
+			continue
+		}
+		existing, ok := s.items.get(key)
+		if patch.ByUUID() {
+			// a patch keyed by uuid can change the identifier, which keys the product in the backend
+			line.Identifier, line.UUID = "", p.UUID
+			existing, ok = s.byUUID(p.UUID)
+			if ok {
+				s.items.delete(productKey(existing))
+			}
+		}
+		line.StatusCode = http.StatusCreated
+		if ok {
+			line.StatusCode = http.StatusNoContent
+		}
+		merged, err := mergeResource(existing, patch)
+		if err != nil {
+			return nil, err
+		}
+		merged.Values = mergeValues(existing.Values, p.Values)
+		s.items.put(merged)
+		result = append(result, line)
+	}
+	return result, nil
+}
+
+// byUUID returns the product having a uuid
+func (s *ProductService) byUUID(uuid string) (goakeneo.Product, bool) {
+	for _, p := range s.items.list() {
+		if p.UUID == uuid {
+			return p, true
+		}
+	}
+	return goakeneo.Product{}, false
+}
+
+// ProductModelService is an in-memory goakeneo.ProductModelService, product models are keyed by code
+type ProductModelService struct {
+	items *collection[goakeneo.ProductModel]
+}
+
+// NewProductModelService returns a product model service holding product models
+func NewProductModelService(models ...goakeneo.ProductModel) *ProductModelService {
+	s := &ProductModelService{items: newCollection(func(pm goakeneo.ProductModel) string { return pm.Code })}
+	s.items.put(models...)
+	return s
+}
+
+// Put adds or replaces product models
+func (s *ProductModelService) Put(models ...goakeneo.ProductModel) {
+	s.items.put(models...)
+}
+
+// Delete removes a product model by code
+func (s *ProductModelService) Delete(code string) {
+	s.items.delete(code)
+}
+
+// ListWithPagination lists product models with pagination
+func (s *ProductModelService) ListWithPagination(options any) ([]goakeneo.ProductModel, goakeneo.Links, error) {
+	return s.items.page("/api/rest/v1/product-models", options)
+}
+
+// GetProductModel gets a product model by code
+func (s *ProductModelService) GetProductModel(code string, options any) (*goakeneo.ProductModel, error) {
+	pm, ok := s.items.get(code)
+	if !ok {
+		return nil, notFound("product model %s", code)
+	}
+	return &pm, nil
+}
+
+// Crate creates a product model, it fails when the code is already used
+func (s *ProductModelService) Crate(pm goakeneo.ProductModel) error {
+	if pm.Code == "" {
+		return errors.New("code is required")
+	}
+	if pm.FamilyVariant == "" {
+		return errors.New("family is required")
+	}
+	if _, ok := s.items.get(pm.Code); ok {
+		return errors.Errorf("product model %s already exists", pm.Code)
+	}
+	s.items.put(pm)
+	return nil
+}
+
+// mergeResource overwrites the fields of existing with the fields set in patch, as a JSON merge
+func mergeResource[T any](existing T, patch any) (T, error) {
+	var merged T
+	fields := make(map[string]json.RawMessage)
+	b, err := json.Marshal(existing)
+	if err != nil {
+		return merged, errors.Wrap(err, "unable to marshal resource")
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return merged, errors.Wrap(err, "unable to unmarshal resource")
+	}
+	b, err = json.Marshal(patch)
+	if err != nil {
+		return merged, errors.Wrap(err, "unable to marshal patch")
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return merged, errors.Wrap(err, "unable to unmarshal patch")
+	}
+	b, err = json.Marshal(fields)
+	if err != nil {
+		return merged, errors.Wrap(err, "unable to marshal merged resource")
+	}
+	if err := json.Unmarshal(b, &merged); err != nil {
+		return merged, errors.Wrap(err, "unable to unmarshal merged resource")
+	}
+	return merged, nil
+}
+
+// remarshal converts v to out through JSON
+func remarshal(v, out any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal resource")
+	}
+	return errors.Wrap(json.Unmarshal(b, out), "unable to unmarshal resource")
+}
+
+// mergeValues replaces the existing values per attribute, locale and scope, a nil data removes the value
+func mergeValues(existing, patch map[string][]goakeneo.ProductValue) map[string][]goakeneo.ProductValue {
+	merged := make(map[string][]goakeneo.ProductValue, len(existing))
+	for attr, values := range existing {
+		merged[attr] = append([]goakeneo.ProductValue(nil), values...)
+	}
+	for attr, values := range patch {
+		for _, v := range values {
+			current := merged[attr][:0:0]
+			for _, e := range merged[attr] {
+				if e.Locale != v.Locale || e.Scope != v.Scope {
+					current = append(current, e)
+				}
+			}
+			if v.Data != nil {
+				current = append(current, v)
+			}
+			merged[attr] = current
+		}
+		if len(merged[attr]) == 0 {
+			delete(merged, attr)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,118 @@
+package akeneotest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFile describes an export of the goakeneo package, it is not a fixture
+const manifestFile = "manifest.json"
+
+// fixture files holding nested resources, the parent code is read from the given property
+var nestedFixtures = map[string]struct {
+	parent   string // parent is the parent collection
+	property string // property holds the parent code in each item
+	child    string // child is the nested collection name
+}{
+	"family-variants":   {parent: "families", property: "family", child: "variants"},
+	"attribute-options": {parent: "attributes", property: "attribute", child: "options"},
+}
+
+// LoadFixtures seeds the server from a directory.
+// Each <collection>.json file holds a JSON array and each <collection>.jsonl file one JSON object per line,
+// i.e. products.json, product-models.jsonl or families.json.
+// Family variants are read from family-variants files with a "family" property,
+// attribute options from attribute-options files with their "attribute" property.
+// Media files are read from media-files files, their content from media/<code> when it exists.
+// The manifest.json file of an export is ignored, so an export directory is a fixture directory
+func (s *Server) LoadFixtures(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".jsonl") || entry.Name() == manifestFile {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		items, err := readFixture(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := s.loadFixture(dir, name, items); err != nil {
+			return fmt.Errorf("fixture %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (s *Server) loadFixture(dir, name string, items []map[string]any) error {
+	if nested, ok := nestedFixtures[name]; ok {
+		for _, item := range items {
+			parent, _ := item[nested.property].(string)
+			if parent == "" {
+				return fmt.Errorf("property %q is required", nested.property)
+			}
+			if name == "family-variants" {
+				delete(item, nested.property)
+			}
+			if err := s.Seed(nested.parent+"/"+parent+"/"+nested.child, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if name == mediaFilesPath {
+		for _, item := range items {
+			code, _ := item["code"].(string)
+			filename, _ := item["original_filename"].(string)
+			mimeType, _ := item["mime_type"].(string)
+			content, err := os.ReadFile(filepath.Join(dir, "media", filepath.FromSlash(strings.TrimPrefix(code, "/"))))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			s.SeedMediaFile(code, filename, mimeType, content)
+		}
+		return nil
+	}
+	for _, item := range items {
+		if err := s.Seed(name, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFixture(file string) ([]map[string]any, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var items []map[string]any
+	if filepath.Ext(file) == ".json" {
+		if err := json.NewDecoder(f).Decode(&items); err != nil {
+			return nil, fmt.Errorf("fixture %s must be a JSON array of objects: %w", file, err)
+		}
+		return items, nil
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item map[string]any
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, fmt.Errorf("fixture %s: invalid line: %w", file, err)
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
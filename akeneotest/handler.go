@@ -0,0 +1,576 @@
+package akeneotest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// route is a parsed API path
+type route struct {
+	collection string // collection is the stored collection, i.e. products or families/shoes/variants
+	view       string // view is the first path segment, products-uuid for the products stored in products
+	code       string // code is the item code, empty for the collection endpoints
+	download   bool   // download is true for the media file download endpoint
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == tokenPath {
+		s.handleToken(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "The access token provided is invalid.")
+		return
+	}
+	if r.URL.Path == systemInfoPath {
+		if s.Version == "" {
+			writeError(w, http.StatusNotFound, "Resource not found.")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"version": s.Version, "edition": s.Edition})
+		return
+	}
+	rt, ok := parseRoute(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Resource not found.")
+		return
+	}
+	switch {
+	case rt.collection == mediaFilesPath && r.Method == http.MethodPost && rt.code == "":
+		s.handleMediaUpload(w, r)
+	case rt.download && r.Method == http.MethodGet:
+		s.handleMediaDownload(w, r, rt)
+	case rt.code == "" && r.Method == http.MethodGet:
+		s.handleList(w, r, rt)
+	case rt.code == "" && r.Method == http.MethodPost:
+		s.handleCreate(w, r, rt)
+	case rt.code == "" && r.Method == http.MethodPatch:
+		s.handleBatchPatch(w, r, rt)
+	case rt.code != "" && r.Method == http.MethodGet:
+		s.handleGet(w, r, rt)
+	case rt.code != "" && r.Method == http.MethodPatch:
+		s.handlePatch(w, r, rt)
+	case rt.code != "" && r.Method == http.MethodDelete:
+		s.handleDelete(w, rt)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+func parseRoute(p string) (route, bool) {
+	rel, ok := strings.CutPrefix(p, apiPrefix)
+	if !ok {
+		return route{}, false
+	}
+	rel = strings.Trim(rel, "/")
+	view, rest, _ := strings.Cut(rel, "/")
+	rt := route{collection: view, view: view}
+	if view == productsUUIDPath {
+		rt.collection = productsPath
+	}
+	if view == mediaFilesPath {
+		rt.code, rt.download = strings.CutSuffix(rest, "/download")
+		return rt, true
+	}
+	if rest == "" {
+		return rt, view != ""
+	}
+	segments := strings.Split(rest, "/")
+	for i := range segments {
+		if unescaped, err := url.PathUnescape(segments[i]); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	switch len(segments) {
+	case 1:
+		rt.code = segments[0]
+	case 2, 3:
+		// nested resources, i.e. families/{code}/variants/{code}
+		rt.collection = view + "/" + segments[0] + "/" + segments[1]
+		rt.view = rt.collection
+		if len(segments) == 3 {
+			rt.code = segments[2]
+		}
+	default:
+		return route{}, false
+	}
+	return rt, true
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte(s.ClientID+":"+s.Secret))
+	if r.Header.Get("Authorization") != basic {
+		writeError(w, http.StatusUnprocessableEntity, "Parameter \"client_id\" is missing or does not match any client, or secret is invalid")
+		return
+	}
+	var req struct {
+		GrantType    string `json:"grant_type"`
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid json message received")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch req.GrantType {
+	case "password":
+		if req.Username != s.Username || req.Password != s.Password {
+			writeError(w, http.StatusUnprocessableEntity, "No user found for the given username and password")
+			return
+		}
+	case "refresh_token":
+		if !s.refresh[req.RefreshToken] {
+			writeError(w, http.StatusUnprocessableEntity, "Refresh token is invalid or has expired")
+			return
+		}
+		delete(s.refresh, req.RefreshToken)
+	default:
+		writeError(w, http.StatusUnprocessableEntity, "Parameter \"grant_type\" is invalid")
+		return
+	}
+	access, refresh := randomToken(), randomToken()
+	s.tokens[access] = s.Now().Add(s.TokenTTL)
+	s.refresh[refresh] = true
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  access,
+		"expires_in":    int(s.TokenTTL.Seconds()),
+		"token_type":    "bearer",
+		"scope":         nil,
+		"refresh_token": refresh,
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.tokens[token]
+	return ok && s.Now().Before(exp)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, rt route) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.lookup(rt.collection, rt.code)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource with code \"%s\" does not exist.", rt.code))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.present(rt, item, r.URL.Query()))
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request, rt route) {
+	q := r.URL.Query()
+	limit := defaultPageLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageLimit {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("You cannot request more than %d items.", maxPageLimit))
+			return
+		}
+		limit = n
+	}
+	page := 1
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusUnprocessableEntity, "The page parameter must be a positive integer.")
+			return
+		}
+		page = n
+	}
+	filters, err := parseSearch(q.Get("search"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	col := s.collections[rt.collection]
+	var matched []map[string]any
+	if col != nil {
+		for _, key := range col.keys() {
+			item := col.items[key]
+			ok, err := s.match(rt.collection, item, filters)
+			if err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			if ok {
+				matched = append(matched, item)
+			}
+		}
+	}
+	selfURL := s.URL + r.URL.Path
+	links := map[string]any{
+		"self":  link(selfURL + "?" + q.Encode()),
+		"first": link(selfURL + "?" + withParams(q, "page", "1").Encode()),
+	}
+	var items []map[string]any
+	if q.Get("pagination_type") == "search_after" {
+		field := keyField(rt.collection)
+		after := q.Get("search_after")
+		for _, item := range matched {
+			if key, _ := item[field].(string); after == "" || key > after {
+				items = append(items, item)
+			}
+		}
+		hasNext := len(items) > limit
+		if hasNext {
+			items = items[:limit]
+			last, _ := items[limit-1][field].(string)
+			links["next"] = link(selfURL + "?" + withParams(q, "search_after", last).Encode())
+		}
+	} else {
+		start := (page - 1) * limit
+		if start < len(matched) {
+			end := start + limit
+			if end < len(matched) {
+				links["next"] = link(selfURL + "?" + withParams(q, "page", strconv.Itoa(page+1)).Encode())
+			} else {
+				end = len(matched)
+			}
+			items = matched[start:end]
+		}
+		if page > 1 {
+			links["previous"] = link(selfURL + "?" + withParams(q, "page", strconv.Itoa(page-1)).Encode())
+		}
+	}
+	presented := make([]map[string]any, len(items))
+	for i, item := range items {
+		presented[i] = s.present(rt, item, q)
+	}
+	body := map[string]any{
+		"_links":    links,
+		"_embedded": map[string]any{"items": presented},
+	}
+	if q.Get("pagination_type") != "search_after" {
+		body["current_page"] = page
+	}
+	if q.Get("with_count") == "true" {
+		body["items_count"] = len(matched)
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request, rt route) {
+	obj, ok := decodeObject(w, r.Body)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	field := keyField(rt.collection)
+	if rt.collection == productsPath {
+		field = "identifier"
+	}
+	if code, _ := obj[field].(string); code != "" {
+		if _, exists := s.lookup(rt.collection, code); exists {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("The %s \"%s\" is already used.", field, code))
+			return
+		}
+	}
+	if _, err := s.put(rt.collection, obj); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.Header().Set("Location", s.itemURL(rt, obj))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handlePatch(w http.ResponseWriter, r *http.Request, rt route) {
+	obj, ok := decodeObject(w, r.Body)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, err := s.upsert(rt, rt.code, obj)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	w.Header().Set("Location", s.itemURL(rt, obj))
+	w.WriteHeader(status)
+}
+
+// upsert updates or creates an item identified by the route code or the body, s.mu must be held
+func (s *Server) upsert(rt route, code string, obj map[string]any) (int, error) {
+	delete(obj, "_links")
+	field := keyField(rt.collection)
+	if rt.view == productsPath {
+		field = "identifier"
+	}
+	if v, set := obj[field]; set && code != "" && v != code {
+		return http.StatusUnprocessableEntity, fmt.Errorf("the %s \"%v\" provided in the request body must match the %s \"%s\" provided in the url", field, v, field, code)
+	}
+	if code != "" {
+		obj[field] = code
+	}
+	if err := s.checkReferences(rt.collection, obj); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	if rt.collection == productsPath {
+		if key, ok := s.keyOf(productsPath, code); ok {
+			obj["uuid"] = key
+		}
+	}
+	created, err := s.put(rt.collection, obj)
+	switch {
+	case err != nil:
+		return http.StatusUnprocessableEntity, err
+	case created:
+		return http.StatusCreated, nil
+	default:
+		return http.StatusNoContent, nil
+	}
+}
+
+// checkReferences verifies the category tree of a channel and the associated products and product models
+// of a product or a product model exist, as the PIM does, s.mu must be held
+func (s *Server) checkReferences(collectionPath string, obj map[string]any) error {
+	switch collectionPath {
+	case channelsPath:
+		if tree, _ := obj["category_tree"].(string); tree != "" {
+			if _, ok := s.lookup(categoriesPath, tree); !ok {
+				return fmt.Errorf("property \"category_tree\" expects a valid category code, the category \"%s\" does not exist", tree)
+			}
+		}
+	case productsPath, productModelsPath:
+		for _, property := range []string{"associations", "quantified_associations"} {
+			types, _ := obj[property].(map[string]any)
+			for associationType, raw := range types {
+				association, _ := raw.(map[string]any)
+				for kind, target := range map[string]string{"products": productsPath, "product_models": productModelsPath} {
+					list, _ := association[kind].([]any)
+					for _, entry := range list {
+						code, _ := entry.(string)
+						if quantified, ok := entry.(map[string]any); ok {
+							code, _ = quantified["identifier"].(string)
+							if kind == "product_models" {
+								code, _ = quantified["code"].(string)
+							}
+						}
+						if _, ok := s.lookup(target, code); !ok {
+							return fmt.Errorf("property \"%s\" expects valid %s for the association type \"%s\", \"%s\" does not exist",
+								property, strings.ReplaceAll(kind, "_", " "), associationType, code)
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleBatchPatch(w http.ResponseWriter, r *http.Request, rt route) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), collectionType) {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("The \"%s\" content type is not supported, use \"%s\".", r.Header.Get("Content-Type"), collectionType))
+		return
+	}
+	field := keyField(rt.collection)
+	if rt.view == productsPath {
+		field = "identifier"
+	}
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line := 0
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		line++
+		result := map[string]any{"line": line}
+		if line > maxBatchLines {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Too many resources to process, %d is the maximum allowed.", maxBatchLines))
+			return
+		}
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(text), &obj); err != nil {
+			result["status_code"] = http.StatusBadRequest
+			result["message"] = "Invalid json message received"
+		} else {
+			code, _ := obj[field].(string)
+			result[field] = code
+			status, err := s.upsert(rt, code, obj)
+			result["status_code"] = status
+			if err != nil {
+				result["message"] = err.Error()
+			}
+		}
+		b, _ := json.Marshal(result)
+		out.Write(b)
+		out.WriteByte('\n')
+	}
+	w.Header().Set("Content-Type", collectionType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out.Bytes())
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, rt route) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keyOf(rt.collection, rt.code)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource with code \"%s\" does not exist.", rt.code))
+		return
+	}
+	delete(s.collections[rt.collection].items, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// present returns the item as served by the API with its links, s.mu must be held
+func (s *Server) present(rt route, item map[string]any, q url.Values) map[string]any {
+	out := clone(item)
+	out["_links"] = map[string]any{"self": link(s.itemURL(rt, item))}
+	if rt.collection == mediaFilesPath {
+		out["_links"].(map[string]any)["download"] = link(s.itemURL(rt, item) + "/download")
+	}
+	values, _ := out["values"].(map[string]any)
+	if values == nil {
+		return out
+	}
+	attributes := splitList(q.Get("attributes"))
+	locales := splitList(q.Get("locales"))
+	scope := q.Get("scope")
+	for attr, list := range values {
+		if len(attributes) > 0 && !contains(attributes, attr) {
+			delete(values, attr)
+			continue
+		}
+		entries, _ := list.([]any)
+		kept := entries[:0]
+		for _, e := range entries {
+			entry, _ := e.(map[string]any)
+			if l, _ := entry["locale"].(string); l != "" && len(locales) > 0 && !contains(locales, l) {
+				continue
+			}
+			if sc, _ := entry["scope"].(string); sc != "" && scope != "" && sc != scope {
+				continue
+			}
+			s.addMediaLinks(entry)
+			kept = append(kept, entry)
+		}
+		values[attr] = kept
+	}
+	return out
+}
+
+// addMediaLinks adds the download links of the media file values, s.mu must be held
+func (s *Server) addMediaLinks(entry map[string]any) {
+	if _, ok := entry["_links"]; ok {
+		return
+	}
+	mediaLink := func(code string) map[string]any {
+		u := s.URL + apiPrefix + mediaFilesPath + "/" + code
+		return map[string]any{"download": link(u + "/download")}
+	}
+	switch data := entry["data"].(type) {
+	case string:
+		if _, ok := s.media[data]; ok {
+			entry["_links"] = mediaLink(data)
+		}
+	case []any:
+		var links []any
+		for _, d := range data {
+			code, _ := d.(string)
+			if _, ok := s.media[code]; !ok {
+				return
+			}
+			links = append(links, mediaLink(code))
+		}
+		if len(links) > 0 {
+			entry["_links"] = links
+		}
+	}
+}
+
+func (s *Server) itemURL(rt route, item map[string]any) string {
+	field := keyField(rt.collection)
+	if rt.view == productsPath {
+		field = "identifier"
+	}
+	code, _ := item[field].(string)
+	if rt.collection == mediaFilesPath {
+		return s.URL + apiPrefix + rt.view + "/" + code
+	}
+	return s.URL + apiPrefix + rt.view + "/" + url.PathEscape(code)
+}
+
+func decodeObject(w http.ResponseWriter, body io.Reader) (map[string]any, bool) {
+	var obj map[string]any
+	if err := json.NewDecoder(body).Decode(&obj); err != nil || obj == nil {
+		writeError(w, http.StatusBadRequest, "Invalid json message received")
+		return nil, false
+	}
+	return obj, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{Code: status, Message: message})
+}
+
+func link(href string) map[string]any {
+	return map[string]any{"href": href}
+}
+
+func withParams(q url.Values, key, value string) url.Values {
+	c := make(url.Values, len(q))
+	for k, v := range q {
+		c[k] = v
+	}
+	c.Set(key, value)
+	if key == "search_after" {
+		c.Del("page")
+	}
+	return c
+}
+
+func splitList(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func contains(list []string, v string) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package akeneotest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (s *Server) handleMediaUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusUnsupportedMediaType, "\"Content-Type\" header must be \"multipart/form-data\" with a boundary")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Property \"file\" is required.")
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var association struct {
+		Identifier string `json:"identifier"`
+		Code       string `json:"code"`
+		Attribute  string `json:"attribute"`
+		Scope      string `json:"scope"`
+		Locale     string `json:"locale"`
+	}
+	target, owner := "", ""
+	for field, collectionPath := range map[string]string{"product": productsPath, "product_model": productModelsPath} {
+		if v := r.FormValue(field); v != "" {
+			if err := json.Unmarshal([]byte(v), &association); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Property \"%s\" must be a valid JSON.", field))
+				return
+			}
+			target, owner = collectionPath, association.Identifier+association.Code
+		}
+	}
+	sum := sha1.Sum(content)
+	hash := hex.EncodeToString(sum[:])
+	name := unsafeFilename.ReplaceAllString(header.Filename, "_")
+	code := fmt.Sprintf("%c/%c/%c/%c/%s_%s", hash[0], hash[1], hash[2], hash[3], hash, name)
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		if t := mime.TypeByExtension(path.Ext(header.Filename)); t != "" {
+			mimeType = t
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if target != "" {
+		item, ok := s.lookup(target, owner)
+		if !ok {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Resource \"%s\" does not exist.", owner))
+			return
+		}
+		mergeValues(item, map[string]any{association.Attribute: []any{map[string]any{
+			"locale": nullable(association.Locale),
+			"scope":  nullable(association.Scope),
+			"data":   code,
+		}}})
+		item["updated"] = s.Now().Format(dateTimeLayout)
+	}
+	s.addMediaFile(code, header.Filename, mimeType, content)
+	w.Header().Set("Location", s.URL+apiPrefix+mediaFilesPath+"/"+code)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handleMediaDownload(w http.ResponseWriter, r *http.Request, rt route) {
+	s.mu.Lock()
+	content, ok := s.media[rt.code]
+	item, found := s.lookup(mediaFilesPath, rt.code)
+	s.mu.Unlock()
+	if !ok || !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Media file \"%s\" does not exist.", rt.code))
+		return
+	}
+	if mimeType, _ := item["mime_type"].(string); mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	}
+	filename, _ := item["original_filename"].(string)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	// ServeContent handles the Range requests
+	http.ServeContent(w, r, filename, time.Time{}, bytes.NewReader(content))
+}
+
+// addMediaFile stores a media file and its metadata, s.mu must be held
+func (s *Server) addMediaFile(code, filename, mimeType string, content []byte) {
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(path.Ext(filename))
+	}
+	s.media[code] = content
+	_, _ = s.put(mediaFilesPath, map[string]any{
+		"code":              code,
+		"original_filename": filename,
+		"mime_type":         mimeType,
+		"size":              float64(len(content)),
+		"extension":         strings.TrimPrefix(path.Ext(filename), "."),
+	})
+}
+
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,269 @@
+package akeneotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// condition is a search filter condition,
+// see https://api.akeneo.com/documentation/filter.html
+type condition struct {
+	Operator string `json:"operator"`
+	Value    any    `json:"value"`
+	Locale   string `json:"locale"`
+	Scope    string `json:"scope"`
+}
+
+var searchTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+func parseSearch(search string) (map[string][]condition, error) {
+	if search == "" {
+		return nil, nil
+	}
+	var filters map[string][]condition
+	if err := json.Unmarshal([]byte(search), &filters); err != nil {
+		return nil, fmt.Errorf("search query parameter should be valid JSON")
+	}
+	return filters, nil
+}
+
+// match returns true if the item matches every filter, s.mu must be held
+func (s *Server) match(collectionPath string, item map[string]any, filters map[string][]condition) (bool, error) {
+	for field, conditions := range filters {
+		for _, c := range conditions {
+			ok, err := s.matchCondition(collectionPath, item, field, c)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func (s *Server) matchCondition(collectionPath string, item map[string]any, field string, c condition) (bool, error) {
+	value, isSet := item[field]
+	if !isSet {
+		value = valueData(item, field, c.Locale, c.Scope)
+	}
+	if field == "categories" {
+		if list, ok := c.Value.([]any); ok && strings.Contains(c.Operator, "CHILDREN") {
+			c.Value = s.withChildren(list)
+			c.Operator = strings.Replace(c.Operator, " CHILDREN", "", 1)
+		}
+		switch c.Operator {
+		case "UNCLASSIFIED":
+			return isEmpty(value), nil
+		case "IN OR UNCLASSIFIED":
+			ok, err := compare(value, "IN", c.Value)
+			return ok || isEmpty(value), err
+		}
+	}
+	if field == "updated" || field == "created" {
+		if c.Operator == "SINCE LAST N DAYS" {
+			days, ok := c.Value.(float64)
+			if !ok {
+				return false, fmt.Errorf("property \"%s\" expects a number as data", field)
+			}
+			c.Operator, c.Value = ">", s.Now().AddDate(0, 0, -int(days)).Format(time.RFC3339)
+		}
+	}
+	return compare(value, c.Operator, c.Value)
+}
+
+// valueData returns the data of a product value matching the locale and the scope
+func valueData(item map[string]any, attribute, locale, scope string) any {
+	values, _ := item["values"].(map[string]any)
+	entries, _ := values[attribute].([]any)
+	for _, e := range entries {
+		entry, _ := e.(map[string]any)
+		l, _ := entry["locale"].(string)
+		sc, _ := entry["scope"].(string)
+		if l == locale && sc == scope {
+			return entry["data"]
+		}
+	}
+	return nil
+}
+
+// withChildren adds the descendants of the categories, s.mu must be held
+func (s *Server) withChildren(codes []any) []any {
+	col := s.collections[categoriesPath]
+	if col == nil {
+		return codes
+	}
+	seen := make(map[any]bool)
+	for _, c := range codes {
+		seen[c] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for code, category := range col.items {
+			if seen[category["parent"]] && !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+				changed = true
+			}
+		}
+	}
+	return codes
+}
+
+func compare(actual any, operator string, expected any) (bool, error) {
+	switch operator {
+	case "EMPTY":
+		return isEmpty(actual), nil
+	case "NOT EMPTY":
+		return !isEmpty(actual), nil
+	case "IN", "NOT IN":
+		list, ok := expected.([]any)
+		if !ok {
+			return false, fmt.Errorf("operator \"%s\" expects an array as value", operator)
+		}
+		in := false
+		for _, e := range list {
+			if overlaps(actual, e) {
+				in = true
+				break
+			}
+		}
+		return in == (operator == "IN"), nil
+	case "BETWEEN", "NOT BETWEEN":
+		list, ok := expected.([]any)
+		if !ok || len(list) != 2 {
+			return false, fmt.Errorf("operator \"%s\" expects an array of two values", operator)
+		}
+		low, err1 := order(actual, list[0])
+		high, err2 := order(actual, list[1])
+		if err1 != nil || err2 != nil {
+			return false, nil
+		}
+		return (low >= 0 && high <= 0) == (operator == "BETWEEN"), nil
+	case "=", "!=":
+		return overlapsScalar(actual, expected) == (operator == "="), nil
+	case "<", "<=", ">", ">=":
+		o, err := order(actual, expected)
+		if err != nil {
+			return false, nil
+		}
+		switch operator {
+		case "<":
+			return o < 0, nil
+		case "<=":
+			return o <= 0, nil
+		case ">":
+			return o > 0, nil
+		default:
+			return o >= 0, nil
+		}
+	case "CONTAINS", "DOES NOT CONTAIN", "STARTS WITH":
+		a, _ := actual.(string)
+		e, _ := expected.(string)
+		switch operator {
+		case "CONTAINS":
+			return strings.Contains(strings.ToLower(a), strings.ToLower(e)), nil
+		case "DOES NOT CONTAIN":
+			return !strings.Contains(strings.ToLower(a), strings.ToLower(e)), nil
+		default:
+			return strings.HasPrefix(strings.ToLower(a), strings.ToLower(e)), nil
+		}
+	default:
+		return false, fmt.Errorf("filter operator \"%s\" is not supported", operator)
+	}
+}
+
+func isEmpty(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []any:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
+
+// overlaps returns true if the value equals e, or contains e when it is a list
+func overlaps(v, e any) bool {
+	if list, ok := v.([]any); ok {
+		for _, x := range list {
+			if overlapsScalar(x, e) {
+				return true
+			}
+		}
+		return false
+	}
+	return overlapsScalar(v, e)
+}
+
+func overlapsScalar(a, b any) bool {
+	if o, err := order(a, b); err == nil {
+		return o == 0
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// order compares two values as times, numbers or strings
+func order(a, b any) (int, error) {
+	if ta, ok := parseTime(a); ok {
+		if tb, ok := parseTime(b); ok {
+			return ta.Compare(tb), nil
+		}
+	}
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1, nil
+		case fa > fb:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if okA && okB {
+		return strings.Compare(sa, sb), nil
+	}
+	if ba, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok && ba == bb {
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("values %v and %v are not comparable", a, b)
+}
+
+func parseTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok || len(s) < len("2006-01-02") {
+		return time.Time{}, false
+	}
+	for _, layout := range searchTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
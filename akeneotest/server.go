@@ -0,0 +1,407 @@
+// Package akeneotest provides an in-process fake Akeneo PIM for tests.
+//
+// The fake implements the OAuth token endpoint and the product, product model, family,
+// family variant, attribute, attribute option, category, channel, locale and media file
+// endpoints with pagination, search filtering and batch PATCH semantics.
+// It does not depend on the goakeneo package so it can be used by its own tests:
+//
+//	srv := akeneotest.NewServer()
+//	defer srv.Close()
+//	if err := srv.LoadFixtures("testdata"); err != nil {
+//		t.Fatal(err)
+//	}
+//	con := goakeneo.Connector{ClientID: srv.ClientID, Secret: srv.Secret, UserName: srv.Username, Password: srv.Password}
+//	client, err := con.NewClient(goakeneo.WithBaseURL(srv.URL))
+package akeneotest
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default credentials accepted by the server
+const (
+	DefaultClientID = "akeneotest_client_id"
+	DefaultSecret   = "akeneotest_secret"
+	DefaultUsername = "akeneotest"
+	DefaultPassword = "akeneotest_password"
+)
+
+const (
+	apiPrefix         = "/api/rest/v1/"
+	tokenPath         = "/api/oauth/v1/token"
+	collectionType    = "application/vnd.akeneo.collection+json"
+	defaultPageLimit  = 10
+	maxPageLimit      = 100
+	maxBatchLines     = 100
+	defaultTokenTTL   = time.Hour
+	defaultVersion    = "7.0.0"
+	dateTimeLayout    = "2006-01-02T15:04:05-07:00"
+	productsPath      = "products"
+	productsUUIDPath  = "products-uuid"
+	productModelsPath = "product-models"
+	mediaFilesPath    = "media-files"
+	categoriesPath    = "categories"
+	systemInfoPath    = "/api/rest/v1/system-information"
+	channelsPath      = "channels"
+)
+
+// Server is a fake Akeneo PIM, it is safe for concurrent use
+type Server struct {
+	*httptest.Server
+	ClientID string
+	Secret   string
+	Username string
+	Password string
+	// TokenTTL is the lifetime of the issued access tokens
+	TokenTTL time.Duration
+	// Now returns the time used for the created and updated dates
+	Now func() time.Time
+	// Version and Edition are served by the system information endpoint, which is missing when Version is empty
+	Version string
+	Edition string
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	media       map[string][]byte
+	tokens      map[string]time.Time
+	refresh     map[string]bool
+}
+
+// NewServer starts a fake PIM without data, use Seed or LoadFixtures to add some
+func NewServer() *Server {
+	s := &Server{
+		ClientID:    DefaultClientID,
+		Secret:      DefaultSecret,
+		Username:    DefaultUsername,
+		Password:    DefaultPassword,
+		TokenTTL:    defaultTokenTTL,
+		Now:         time.Now,
+		Version:     defaultVersion,
+		Edition:     "CE",
+		collections: make(map[string]*collection),
+		media:       make(map[string][]byte),
+		tokens:      make(map[string]time.Time),
+		refresh:     make(map[string]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Seed adds items to a collection, items are any value marshalling to a JSON object.
+// The collection is the API path of the resource, i.e. "products", "families" or
+// "families/shoes/variants" and "attributes/color/options" for the nested resources
+func (s *Server) Seed(collectionPath string, items ...any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		obj, err := toObject(item)
+		if err != nil {
+			return err
+		}
+		delete(obj, "_links")
+		if _, err := s.put(collectionPath, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeedMediaFile adds a media file with its content, the code is the akeneo file path,
+// i.e. "1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_shoes.jpeg"
+func (s *Server) SeedMediaFile(code, filename, mimeType string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addMediaFile(strings.TrimPrefix(code, "/"), filename, mimeType, content)
+}
+
+// Get returns a copy of an item of a collection, products are looked up by identifier or uuid
+func (s *Server) Get(collectionPath, code string) (map[string]any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.lookup(collectionPath, code)
+	if !ok {
+		return nil, false
+	}
+	return clone(item), true
+}
+
+// List returns a copy of the items of a collection sorted by code
+func (s *Server) List(collectionPath string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	col := s.collections[collectionPath]
+	if col == nil {
+		return nil
+	}
+	items := make([]map[string]any, 0, len(col.items))
+	for _, key := range col.keys() {
+		items = append(items, clone(col.items[key]))
+	}
+	return items
+}
+
+// MediaContent returns the content of a media file
+func (s *Server) MediaContent(code string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.media[strings.TrimPrefix(code, "/")]
+	return content, ok
+}
+
+// Delete removes an item from a collection
+func (s *Server) Delete(collectionPath, code string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	col := s.collections[collectionPath]
+	if col == nil {
+		return false
+	}
+	key, ok := s.keyOf(collectionPath, code)
+	if !ok {
+		return false
+	}
+	delete(col.items, key)
+	return true
+}
+
+// collection is a set of items indexed by key
+type collection struct {
+	items map[string]map[string]any
+}
+
+func (c *collection) keys() []string {
+	keys := make([]string, 0, len(c.items))
+	for k := range c.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keyField returns the field identifying the items of a collection
+func keyField(collectionPath string) string {
+	switch collectionPath {
+	case productsPath:
+		return "uuid"
+	default:
+		return "code"
+	}
+}
+
+// put stores an item, it returns true when the item has been created, s.mu must be held
+func (s *Server) put(collectionPath string, obj map[string]any) (bool, error) {
+	if collectionPath == productsPath {
+		identifier, _ := obj["identifier"].(string)
+		uuid, _ := obj["uuid"].(string)
+		switch {
+		case uuid == "" && identifier == "":
+			return false, fmt.Errorf("the identifier or the uuid of the product is required")
+		case uuid == "":
+			if existing, ok := s.lookup(productsPath, identifier); ok {
+				uuid = existing["uuid"].(string)
+			} else {
+				uuid = productUUID(identifier)
+			}
+			obj["uuid"] = uuid
+		}
+	}
+	field := keyField(collectionPath)
+	key, _ := obj[field].(string)
+	if key == "" {
+		return false, fmt.Errorf("property %q is required", field)
+	}
+	col := s.collections[collectionPath]
+	if col == nil {
+		col = &collection{items: make(map[string]map[string]any)}
+		s.collections[collectionPath] = col
+	}
+	now := s.Now().Format(dateTimeLayout)
+	existing, ok := col.items[key]
+	if !ok {
+		if hasDates(collectionPath) {
+			if _, set := obj["created"]; !set {
+				obj["created"] = now
+			}
+			if _, set := obj["updated"]; !set {
+				obj["updated"] = now
+			}
+		}
+		col.items[key] = obj
+		return true, nil
+	}
+	mergePatch(existing, obj)
+	if hasDates(collectionPath) {
+		if _, set := obj["updated"]; !set {
+			existing["updated"] = now
+		}
+	}
+	return false, nil
+}
+
+// lookup finds an item by key, products are also looked up by identifier, s.mu must be held
+func (s *Server) lookup(collectionPath, code string) (map[string]any, bool) {
+	key, ok := s.keyOf(collectionPath, code)
+	if !ok {
+		return nil, false
+	}
+	return s.collections[collectionPath].items[key], true
+}
+
+// keyOf returns the key of an item, s.mu must be held
+func (s *Server) keyOf(collectionPath, code string) (string, bool) {
+	col := s.collections[collectionPath]
+	if col == nil {
+		return "", false
+	}
+	if _, ok := col.items[code]; ok {
+		return code, true
+	}
+	if collectionPath == productsPath {
+		for key, item := range col.items {
+			if item["identifier"] == code {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+func hasDates(collectionPath string) bool {
+	switch collectionPath {
+	case productsPath, productModelsPath, categoriesPath:
+		return true
+	default:
+		return false
+	}
+}
+
+// productUUID derives a stable uuid from a product identifier
+func productUUID(identifier string) string {
+	sum := sha1.Sum([]byte("akeneotest:" + identifier))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(sum[:16])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+func randomToken() string {
+	b := make([]byte, 20)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// toObject converts a value to a JSON object
+func toObject(v any) (map[string]any, error) {
+	if obj, ok := v.(map[string]any); ok {
+		return clone(obj), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, fmt.Errorf("item must be a JSON object: %w", err)
+	}
+	return obj, nil
+}
+
+// clone deep copies a JSON object
+func clone(obj map[string]any) map[string]any {
+	return cloneValue(obj).(map[string]any)
+}
+
+func cloneValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[k] = cloneValue(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, e := range t {
+			s[i] = cloneValue(e)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// mergePatch applies an akeneo PATCH body on an item:
+// objects are merged, values are merged by locale and scope, other properties are replaced,
+// see https://api.akeneo.com/documentation/update.html#update-behavior
+func mergePatch(dst, patch map[string]any) {
+	for k, v := range patch {
+		if k == "values" {
+			mergeValues(dst, v)
+			continue
+		}
+		pm, isObj := v.(map[string]any)
+		dm, wasObj := dst[k].(map[string]any)
+		switch {
+		case v == nil:
+			delete(dst, k)
+		case isObj && wasObj:
+			mergePatch(dm, pm)
+		default:
+			dst[k] = v
+		}
+	}
+}
+
+func mergeValues(dst map[string]any, patch any) {
+	pv, ok := patch.(map[string]any)
+	if !ok {
+		return
+	}
+	values, _ := dst["values"].(map[string]any)
+	if values == nil {
+		values = make(map[string]any)
+		dst["values"] = values
+	}
+	for attr, list := range pv {
+		entries, _ := list.([]any)
+		existing, _ := values[attr].([]any)
+		for _, e := range entries {
+			entry, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			idx := -1
+			for i, x := range existing {
+				xm, _ := x.(map[string]any)
+				if xm["locale"] == entry["locale"] && xm["scope"] == entry["scope"] {
+					idx = i
+					break
+				}
+			}
+			switch {
+			case entry["data"] == nil && idx >= 0:
+				existing = append(existing[:idx], existing[idx+1:]...)
+			case entry["data"] == nil:
+			case idx >= 0:
+				existing[idx] = entry
+			default:
+				existing = append(existing, entry)
+			}
+		}
+		if len(existing) == 0 {
+			delete(values, attr)
+			continue
+		}
+		values[attr] = existing
+	}
+}
@@ -0,0 +1,207 @@
+package akeneotest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	assert.NoError(t, s.LoadFixtures("../testdata"))
+	body := strings.NewReader(`{"grant_type":"password","username":"` + s.Username + `","password":"` + s.Password + `"}`)
+	req, _ := http.NewRequest(http.MethodPost, s.URL+tokenPath, body)
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(s.ClientID+":"+s.Secret)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+	assert.NotEmpty(t, token.AccessToken)
+	return s, token.AccessToken
+}
+
+func do(t *testing.T, token, method, u, contentType string, body io.Reader) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, u, body)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp, b
+}
+
+type listResponse struct {
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+	ItemsCount int `json:"items_count"`
+	Embedded   struct {
+		Items []map[string]any `json:"items"`
+	} `json:"_embedded"`
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	s, _ := newTestServer(t)
+	resp, _ := do(t, "invalid", http.MethodGet, s.URL+apiPrefix+"products", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s, token := newTestServer(t)
+	next := s.URL + apiPrefix + "products?limit=5&with_count=true"
+	var identifiers []string
+	for pages := 0; next != ""; pages++ {
+		assert.Less(t, pages, 3)
+		resp, b := do(t, token, http.MethodGet, next, "", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var list listResponse
+		assert.NoError(t, json.Unmarshal(b, &list))
+		assert.Equal(t, 12, list.ItemsCount)
+		for _, item := range list.Embedded.Items {
+			identifiers = append(identifiers, item["identifier"].(string))
+		}
+		next = list.Links.Next.Href
+	}
+	assert.Len(t, identifiers, 12)
+
+	next = s.URL + apiPrefix + "products?limit=5&pagination_type=search_after"
+	identifiers = nil
+	for next != "" {
+		_, b := do(t, token, http.MethodGet, next, "", nil)
+		var list listResponse
+		assert.NoError(t, json.Unmarshal(b, &list))
+		for _, item := range list.Embedded.Items {
+			identifiers = append(identifiers, item["identifier"].(string))
+		}
+		next = list.Links.Next.Href
+	}
+	assert.Len(t, identifiers, 12)
+
+	resp, _ := do(t, token, http.MethodGet, s.URL+apiPrefix+"products?limit=101", "", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestServer_Search(t *testing.T) {
+	s, token := newTestServer(t)
+	tests := []struct {
+		search string
+		want   int
+	}{
+		{`{"family":[{"operator":"IN","value":["shoes"]}]}`, 4},
+		{`{"enabled":[{"operator":"=","value":false}]}`, 2},
+		{`{"updated":[{"operator":">","value":"2023-01-31 00:00:00"}]}`, 2},
+		{`{"categories":[{"operator":"IN CHILDREN","value":["master_shoes"]}]}`, 4},
+		{`{"parent":[{"operator":"NOT EMPTY"}]}`, 2},
+		{`{"color":[{"operator":"IN","value":["red"]}],"family":[{"operator":"IN","value":["accessories"]}]}`, 4},
+		{`{"name":[{"operator":"CONTAINS","value":"shoe","locale":"en_US"}]}`, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			resp, b := do(t, token, http.MethodGet, s.URL+apiPrefix+"products?limit=100&search="+url.QueryEscape(tt.search), "", nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, string(b))
+			var list listResponse
+			assert.NoError(t, json.Unmarshal(b, &list))
+			assert.Len(t, list.Embedded.Items, tt.want)
+		})
+	}
+	resp, _ := do(t, token, http.MethodGet, s.URL+apiPrefix+"products?search="+url.QueryEscape(`{"family":[{"operator":"LIKE","value":"x"}]}`), "", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestServer_BatchPatch(t *testing.T) {
+	s, token := newTestServer(t)
+	body := strings.Join([]string{
+		`{"identifier":"accessory-01","values":{"name":[{"locale":"en_US","scope":null,"data":null}],"color":[{"locale":null,"scope":null,"data":"red"}]}}`,
+		`{"identifier":"accessory-99","family":"accessories"}`,
+		`{invalid`,
+	}, "\n")
+	resp, b := do(t, token, http.MethodPatch, s.URL+apiPrefix+"products", collectionType, strings.NewReader(body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, collectionType, resp.Header.Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, []string{
+		`{"identifier":"accessory-01","line":1,"status_code":204}`,
+		`{"identifier":"accessory-99","line":2,"status_code":201}`,
+		`{"line":3,"message":"Invalid json message received","status_code":400}`,
+	}, lines)
+
+	item, ok := s.Get("products", "accessory-01")
+	assert.True(t, ok)
+	values := item["values"].(map[string]any)
+	assert.NotContains(t, values, "name")
+	assert.Equal(t, "red", values["color"].([]any)[0].(map[string]any)["data"])
+	assert.Equal(t, "accessory-01", values["sku"].([]any)[0].(map[string]any)["data"])
+
+	resp, _ = do(t, token, http.MethodPatch, s.URL+apiPrefix+"products", "application/json", strings.NewReader(body))
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestServer_NestedResources(t *testing.T) {
+	s, token := newTestServer(t)
+	resp, b := do(t, token, http.MethodGet, s.URL+apiPrefix+"families/shoes/variants/shoes_by_size", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(b), `"axes":["size"]`)
+	assert.NotContains(t, string(b), `"family"`)
+
+	resp, b = do(t, token, http.MethodGet, s.URL+apiPrefix+"attributes/color/options", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var list listResponse
+	assert.NoError(t, json.Unmarshal(b, &list))
+	assert.Len(t, list.Embedded.Items, 2)
+
+	resp, _ = do(t, token, http.MethodPatch, s.URL+apiPrefix+"attributes/color/options/green", "application/json", strings.NewReader(`{"labels":{"en_US":"Green"}}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	_, ok := s.Get("attributes/color/options", "green")
+	assert.True(t, ok)
+}
+
+func TestServer_Media(t *testing.T) {
+	s, token := newTestServer(t)
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		fw, _ := mw.CreateFormFile("file", "new shoe.png")
+		_, _ = fw.Write([]byte("0123456789"))
+		_ = mw.WriteField("product", `{"identifier":"code-9200-eprcg","attribute":"image","scope":null,"locale":null}`)
+		_ = pw.CloseWithError(mw.Close())
+	}()
+	resp, _ := do(t, token, http.MethodPost, s.URL+apiPrefix+"media-files", mw.FormDataContentType(), pr)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	code := strings.TrimPrefix(location, s.URL+apiPrefix+"media-files/")
+	assert.True(t, strings.HasSuffix(code, "_new_shoe.png"), code)
+
+	product, _ := s.Get("products", "code-9200-eprcg")
+	assert.Equal(t, code, product["values"].(map[string]any)["image"].([]any)[0].(map[string]any)["data"])
+
+	req, _ := http.NewRequest(http.MethodGet, location+"/download", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Range", "bytes=4-")
+	dl, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer dl.Body.Close()
+	b, _ := io.ReadAll(dl.Body)
+	assert.Equal(t, http.StatusPartialContent, dl.StatusCode)
+	assert.Equal(t, "456789", string(b))
+}
@@ -0,0 +1,40 @@
+package goakeneo
+
+const (
+	attributeGroupBasePath = "/api/rest/v1/attribute-groups"
+)
+
+// AttributeGroupService is the interface to interact with the Akeneo Attribute Group API
+type AttributeGroupService interface {
+	ListWithPagination(options any) ([]AttributeGroup, Links, error)
+}
+
+type attributeGroupOp struct {
+	client *Client
+}
+
+// ListWithPagination lists attribute groups with pagination
+func (a *attributeGroupOp) ListWithPagination(options any) ([]AttributeGroup, Links, error) {
+	attributeGroupResponse := new(AttributeGroupsResponse)
+	if err := a.client.GET(
+		attributeGroupBasePath,
+		options,
+		nil,
+		attributeGroupResponse,
+	); err != nil {
+		return nil, Links{}, err
+	}
+	return attributeGroupResponse.Embedded.Items, attributeGroupResponse.Links, nil
+}
+
+// AttributeGroupsResponse is the struct for a akeneo attribute groups response
+type AttributeGroupsResponse struct {
+	Links       Links               `json:"_links" mapstructure:"_links"`
+	CurrentPage int                 `json:"current_page" mapstructure:"current_page"`
+	Embedded    attributeGroupItems `json:"_embedded" mapstructure:"_embedded"`
+}
+
+// attributeGroupItems is the struct for a akeneo attribute group items response
+type attributeGroupItems struct {
+	Items []AttributeGroup `json:"items" mapstructure:"items"`
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -24,21 +25,62 @@ type AuthService interface {
 
 type authOp struct {
 	client *Client
+
+	mu           sync.Mutex // mu guards the token fields, the requests of concurrent workers read them
+	token        string     // token is the access token
+	refreshToken string     // refreshToken is the refresh token
+	tokenExp     time.Time  // tokenExp is the token expiration time
+
+	refreshing sync.Mutex // refreshing lets a single goroutine refresh the token at a time
+}
+
+// accessToken returns the current access token
+func (a *authOp) accessToken() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.token
 }
 
 // GrantByPassword authenticates to the Akeneo API using the password grant type
 func (a *authOp) GrantByPassword() error {
-	result := new(authResponse)
 	request := authByPasswordRequest{
 		GrantType: "password",
 		Username:  a.client.connector.UserName,
 		Password:  a.client.connector.Password,
 	}
+	return a.grant(request.GrantType, request)
+}
+
+// GrantByRefreshToken authenticates to the Akeneo API using the refresh token grant type
+func (a *authOp) GrantByRefreshToken() error {
+	request := authByRefreshTokenRequest{
+		GrantType:    "refresh_token",
+		RefreshToken: a.currentRefreshToken(),
+	}
+	return a.grant(request.GrantType, request)
+}
+
+func (a *authOp) currentRefreshToken() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.refreshToken
+}
+
+// grant requests a new token,
+// it does not go through the client request pipeline which refreshes the token itself
+func (a *authOp) grant(grantType string, request any) (err error) {
+	start := time.Now()
+	var expiresAt time.Time
+	defer func() {
+		a.client.logAuth(grantType, start, expiresAt, err)
+		a.client.hooks.OnAuth(grantType, time.Since(start), err)
+	}()
+	result := new(authResponse)
 	rel, _ := url.Parse(authBasePath)
 	// Make the full url based on the relative path
 	u := a.client.baseURL.ResolveReference(rel)
 	var errResp ErrorResponse
-	_, err := resty.New().R().
+	_, err = resty.NewWithClient(a.client.httpClient).R().
 		SetHeader("Content-Type", defaultContentType).
 		SetHeader("Authorization", base64BasicAuth(a.client.connector.ClientID, a.client.connector.Secret)).
 		SetBody(request).
@@ -48,55 +90,42 @@ func (a *authOp) GrantByPassword() error {
 	if err != nil {
 		return errors.Wrap(err, "unable to authenticate to the Akeneo API")
 	}
-	if err := result.validate(); err != nil {
-		return errors.Wrap(err, "invalid response from the Akeneo API")
-	}
 	if errResp.Message != "" {
-		return errors.Wrapf(err, "unable to authenticate to the Akeneo API: %s", errResp.Message)
-	}
-	a.client.token = result.AccessToken
-	a.client.refreshToken = result.RefreshToken
-	a.client.tokenExp = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
-	return nil
-}
-
-// GrantByRefreshToken authenticates to the Akeneo API using the refresh token grant type
-func (a *authOp) GrantByRefreshToken() error {
-	result := new(authResponse)
-	request := authByRefreshTokenRequest{
-		GrantType:    "refresh_token",
-		RefreshToken: a.client.refreshToken,
+		return errors.Errorf("unable to authenticate to the Akeneo API: %s", errResp.Message)
 	}
-	if err := a.client.POST(
-		authBasePath,
-		nil,
-		request,
-		result,
-	); err != nil {
-		return err
-	}
-	if err := result.validate(); err != nil {
-		return err
+	if err = result.validate(); err != nil {
+		return errors.Wrap(err, "invalid response from the Akeneo API")
 	}
-	a.client.token = result.AccessToken
-	a.client.refreshToken = result.RefreshToken
-	a.client.tokenExp = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	a.mu.Lock()
+	a.token = result.AccessToken
+	a.refreshToken = result.RefreshToken
+	a.tokenExp = expiresAt
+	a.mu.Unlock()
 	return nil
 }
 
 // ShouldRefreshToken returns true if the token should be refreshed
 func (a *authOp) ShouldRefreshToken() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	// time.Now is 5 minutes before the actual expiration
-	return time.Now().Add(5 * time.Minute).After(a.client.tokenExp)
+	return time.Now().Add(5 * time.Minute).After(a.tokenExp)
 }
 
-// AutoRefreshToken refreshes the token if needed
+// AutoRefreshToken refreshes the token if needed, concurrent callers wait for a single refresh
 func (a *authOp) AutoRefreshToken() error {
-	if a.ShouldRefreshToken() {
-		err := a.GrantByRefreshToken()
-		if err != nil {
-			return a.GrantByPassword()
-		}
+	if !a.ShouldRefreshToken() {
+		return nil
+	}
+	a.refreshing.Lock()
+	defer a.refreshing.Unlock()
+	// another goroutine may have refreshed the token while this one was waiting
+	if !a.ShouldRefreshToken() {
+		return nil
+	}
+	if err := a.GrantByRefreshToken(); err != nil {
+		return a.GrantByPassword()
 	}
 	return nil
 }
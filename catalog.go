@@ -0,0 +1,391 @@
+package goakeneo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotInCatalog is returned by Catalog when a code does not exist in the PIM
+var ErrNotInCatalog = errors.New("not in the akeneo catalog")
+
+// Catalog lazily loads and caches the definitions of the catalog: attributes and their options,
+// families and their variants, channels, locales and categories. Each resource is listed once
+// and kept until its TTL expires or it is invalidated. The returned lists must not be modified.
+// It is safe for concurrent use
+type Catalog struct {
+	client     *Client
+	ttl        time.Duration
+	now        func() time.Time
+	attributes *cached[Attribute]
+	families   *cached[Family]
+	channels   *cached[Channel]
+	locales    *cached[Locale]
+	categories *cached[Category]
+	options    *keyedCache[AttributeOption] // options by attribute
+	variants   *keyedCache[FamilyVariant]   // variants by family
+}
+
+func newCatalog(c *Client, ttl time.Duration) *Catalog {
+	return &Catalog{
+		client:     c,
+		ttl:        ttl,
+		now:        time.Now,
+		attributes: newCached(func(a Attribute) string { return a.Code }),
+		families:   newCached(func(f Family) string { return f.Code }),
+		channels:   newCached(func(ch Channel) string { return ch.Code }),
+		locales:    newCached(func(l Locale) string { return l.Code }),
+		categories: newCached(func(cat Category) string { return cat.Code }),
+		options:    newKeyedCache(func(o AttributeOption) string { return o.Code }),
+		variants:   newKeyedCache(func(v FamilyVariant) string { return v.Code }),
+	}
+}
+
+// Attributes returns every attribute
+func (c *Catalog) Attributes(ctx context.Context) ([]Attribute, error) {
+	snap, err := c.attributes.get(c, func() ([]Attribute, error) {
+		return listItems(ctx, nil, c.client.Attribute.ListWithPagination)
+	})
+	return snap.items, err
+}
+
+// Attribute returns an attribute by code
+func (c *Catalog) Attribute(ctx context.Context, code string) (Attribute, error) {
+	snap, err := c.attributes.get(c, func() ([]Attribute, error) {
+		return listItems(ctx, nil, c.client.Attribute.ListWithPagination)
+	})
+	return lookup(snap, err, "attribute", code)
+}
+
+// AttributeOptions returns the options of an attribute
+func (c *Catalog) AttributeOptions(ctx context.Context, attribute string) ([]AttributeOption, error) {
+	snap, err := c.attributeOptions(ctx, attribute)
+	return snap.items, err
+}
+
+// AttributeOption returns an option of an attribute by code
+func (c *Catalog) AttributeOption(ctx context.Context, attribute, code string) (AttributeOption, error) {
+	snap, err := c.attributeOptions(ctx, attribute)
+	return lookup(snap, err, "option of "+attribute, code)
+}
+
+func (c *Catalog) attributeOptions(ctx context.Context, attribute string) (snapshot[AttributeOption], error) {
+	return c.options.entry(attribute).get(c, func() ([]AttributeOption, error) {
+		list := func(options any) ([]AttributeOption, Links, error) {
+			return c.client.Attribute.GetAttributeOptions(attribute, options)
+		}
+		return listItems(ctx, AttributeOptionListOptions{Limit: defaultExportPageSize}, list)
+	})
+}
+
+// Families returns every family
+func (c *Catalog) Families(ctx context.Context) ([]Family, error) {
+	snap, err := c.families.get(c, func() ([]Family, error) {
+		return listItems(ctx, nil, c.client.Family.ListWithPagination)
+	})
+	return snap.items, err
+}
+
+// Family returns a family by code
+func (c *Catalog) Family(ctx context.Context, code string) (Family, error) {
+	snap, err := c.families.get(c, func() ([]Family, error) {
+		return listItems(ctx, nil, c.client.Family.ListWithPagination)
+	})
+	return lookup(snap, err, "family", code)
+}
+
+// FamilyVariants returns the variants of a family
+func (c *Catalog) FamilyVariants(ctx context.Context, family string) ([]FamilyVariant, error) {
+	snap, err := c.familyVariants(ctx, family)
+	return snap.items, err
+}
+
+// FamilyVariant returns a variant of a family by code
+func (c *Catalog) FamilyVariant(ctx context.Context, family, code string) (FamilyVariant, error) {
+	snap, err := c.familyVariants(ctx, family)
+	return lookup(snap, err, "variant of "+family, code)
+}
+
+func (c *Catalog) familyVariants(ctx context.Context, family string) (snapshot[FamilyVariant], error) {
+	return c.variants.entry(family).get(c, func() ([]FamilyVariant, error) {
+		return allFamilyVariants(ctx, c.client.Family, family)
+	})
+}
+
+// Channels returns every channel
+func (c *Catalog) Channels(ctx context.Context) ([]Channel, error) {
+	snap, err := c.channels.get(c, func() ([]Channel, error) {
+		return listItems(ctx, nil, c.client.Channel.ListWithPagination)
+	})
+	return snap.items, err
+}
+
+// Channel returns a channel by code
+func (c *Catalog) Channel(ctx context.Context, code string) (Channel, error) {
+	snap, err := c.channels.get(c, func() ([]Channel, error) {
+		return listItems(ctx, nil, c.client.Channel.ListWithPagination)
+	})
+	return lookup(snap, err, "channel", code)
+}
+
+// Locales returns every locale, enabled or not
+func (c *Catalog) Locales(ctx context.Context) ([]Locale, error) {
+	snap, err := c.locales.get(c, func() ([]Locale, error) {
+		return listItems(ctx, nil, c.client.Locale.ListWithPagination)
+	})
+	return snap.items, err
+}
+
+// Locale returns a locale by code
+func (c *Catalog) Locale(ctx context.Context, code string) (Locale, error) {
+	snap, err := c.locales.get(c, func() ([]Locale, error) {
+		return listItems(ctx, nil, c.client.Locale.ListWithPagination)
+	})
+	return lookup(snap, err, "locale", code)
+}
+
+// Categories returns every category
+func (c *Catalog) Categories(ctx context.Context) ([]Category, error) {
+	snap, err := c.categories.get(c, func() ([]Category, error) {
+		return listItems(ctx, nil, c.client.Category.ListWithPagination)
+	})
+	return snap.items, err
+}
+
+// Category returns a category by code
+func (c *Catalog) Category(ctx context.Context, code string) (Category, error) {
+	snap, err := c.categories.get(c, func() ([]Category, error) {
+		return listItems(ctx, nil, c.client.Category.ListWithPagination)
+	})
+	return lookup(snap, err, "category", code)
+}
+
+// Definitions returns the cached definitions for a ProductValidator or a CompletenessCalculator
+func (c *Catalog) Definitions(ctx context.Context) (*CatalogDefinitions, error) {
+	defs := &CatalogDefinitions{FamilyVariants: make(map[string][]FamilyVariant)}
+	var err error
+	if defs.Families, err = c.Families(ctx); err != nil {
+		return nil, err
+	}
+	for _, f := range defs.Families {
+		variants, err := c.FamilyVariants(ctx, f.Code)
+		if err != nil {
+			return nil, err
+		}
+		if len(variants) > 0 {
+			defs.FamilyVariants[f.Code] = variants
+		}
+	}
+	if defs.Attributes, err = c.Attributes(ctx); err != nil {
+		return nil, err
+	}
+	for _, a := range defs.Attributes {
+		if !selectAttributeTypes[a.Type] {
+			continue
+		}
+		options, err := c.AttributeOptions(ctx, a.Code)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range options {
+			o.Attribute = a.Code
+			defs.Options = append(defs.Options, o)
+		}
+	}
+	if defs.Channels, err = c.Channels(ctx); err != nil {
+		return nil, err
+	}
+	if defs.Locales, err = c.Locales(ctx); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// Invalidate drops the cached resources, such as CatalogAttributes, or every resource when none is given.
+// Invalidating CatalogAttributeOptions or CatalogFamilyVariants drops the options of every attribute
+// or the variants of every family
+func (c *Catalog) Invalidate(resources ...string) {
+	if len(resources) == 0 {
+		resources = []string{
+			CatalogAttributes, CatalogAttributeOptions, CatalogFamilies, CatalogFamilyVariants,
+			CatalogChannels, CatalogLocales, CatalogCategories,
+		}
+	}
+	for _, resource := range resources {
+		switch resource {
+		case CatalogAttributes:
+			c.attributes.invalidate()
+		case CatalogAttributeOptions:
+			c.options.invalidate()
+		case CatalogFamilies:
+			c.families.invalidate()
+		case CatalogFamilyVariants:
+			c.variants.invalidate()
+		case CatalogChannels:
+			c.channels.invalidate()
+		case CatalogLocales:
+			c.locales.invalidate()
+		case CatalogCategories:
+			c.categories.invalidate()
+		}
+	}
+}
+
+// HandleEvent invalidates the cached resources an event refers to without being cached,
+// i.e. the families when the product of the event has a new family.
+// It has the signature of the NewEventHandler callbacks
+func (c *Catalog) HandleEvent(_ context.Context, e Event) error {
+	r := e.Data.Resource
+	if r.Family != "" && c.families.missing(r.Family) {
+		c.families.invalidate()
+	}
+	if variants, ok := c.variants.lookup(r.Family); ok && r.FamilyVariant != "" && variants.missing(r.FamilyVariant) {
+		variants.invalidate()
+	}
+	for _, code := range r.Categories {
+		if c.categories.missing(code) {
+			c.categories.invalidate()
+			break
+		}
+	}
+	for code, values := range r.Values {
+		if c.attributes.missing(code) {
+			c.attributes.invalidate()
+		}
+		options, hasOptions := c.options.lookup(code)
+		for _, v := range values {
+			if v.Locale != "" && c.locales.missing(v.Locale) {
+				c.locales.invalidate()
+			}
+			if v.Scope != "" && c.channels.missing(v.Scope) {
+				c.channels.invalidate()
+			}
+			if !hasOptions {
+				continue
+			}
+			codes, _ := toStrings(v.Data)
+			if s, ok := v.Data.(string); ok {
+				codes = []string{s}
+			}
+			for _, option := range codes {
+				if options.missing(option) {
+					options.invalidate()
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// listItems lists every item of a resource
+func listItems[T any](ctx context.Context, options any, list func(options any) ([]T, Links, error)) ([]T, error) {
+	var items []T
+	err := listAll(ctx, options, list, func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// lookup returns the item of a code from a snapshot
+func lookup[T any](snap snapshot[T], err error, kind, code string) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	i, ok := snap.index[code]
+	if !ok {
+		return zero, errors.Wrapf(ErrNotInCatalog, "%s %s", kind, code)
+	}
+	return snap.items[i], nil
+}
+
+// snapshot is the content of a cached resource
+type snapshot[T any] struct {
+	items []T
+	index map[string]int // index is the position of the items by code
+}
+
+// cached is a resource loaded once per TTL, concurrent loads wait for the first one
+type cached[T any] struct {
+	key    func(T) string
+	mu     sync.Mutex
+	snap   snapshot[T]
+	loaded time.Time
+	valid  bool
+}
+
+func newCached[T any](key func(T) string) *cached[T] {
+	return &cached[T]{key: key}
+}
+
+func (e *cached[T]) get(c *Catalog, load func() ([]T, error)) (snapshot[T], error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.valid && (c.ttl <= 0 || c.now().Sub(e.loaded) < c.ttl) {
+		return e.snap, nil
+	}
+	items, err := load()
+	if err != nil {
+		return snapshot[T]{}, err
+	}
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[e.key(item)] = i
+	}
+	e.snap, e.loaded, e.valid = snapshot[T]{items: items, index: index}, c.now(), true
+	return e.snap, nil
+}
+
+// missing reports whether the resource is loaded without the code, it never loads the resource
+func (e *cached[T]) missing(code string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.snap.index[code]
+	return e.valid && !ok
+}
+
+func (e *cached[T]) invalidate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.snap, e.valid = snapshot[T]{}, false
+}
+
+// keyedCache holds one cached resource per parent, i.e. the options of each attribute
+type keyedCache[T any] struct {
+	key     func(T) string
+	mu      sync.Mutex
+	entries map[string]*cached[T]
+}
+
+func newKeyedCache[T any](key func(T) string) *keyedCache[T] {
+	return &keyedCache[T]{key: key, entries: make(map[string]*cached[T])}
+}
+
+func (k *keyedCache[T]) entry(parent string) *cached[T] {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.entries[parent]
+	if !ok {
+		e = newCached(k.key)
+		k.entries[parent] = e
+	}
+	return e
+}
+
+// lookup returns the cached resource of a parent, without creating it
+func (k *keyedCache[T]) lookup(parent string) (*cached[T], bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.entries[parent]
+	return e, ok
+}
+
+func (k *keyedCache[T]) invalidate() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.entries = make(map[string]*cached[T])
+}
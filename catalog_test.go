@@ -0,0 +1,93 @@
+package goakeneo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog(t *testing.T) {
+	c, srv := newFakeClient(t, WithRateLimit(1000, time.Second))
+	ctx := context.Background()
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	c.Catalog.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, err := c.Catalog.Attribute(ctx, "weight")
+			assert.NoError(t, err)
+			assert.Equal(t, "pim_catalog_metric", a.Type)
+		}()
+	}
+	wg.Wait()
+
+	// the attributes are cached until the TTL expires
+	assert.True(t, srv.Delete("attributes", "weight"))
+	_, err := c.Catalog.Attribute(ctx, "weight")
+	assert.NoError(t, err)
+	now = now.Add(defaultCatalogTTL)
+	_, err = c.Catalog.Attribute(ctx, "weight")
+	assert.ErrorIs(t, err, ErrNotInCatalog)
+
+	option, err := c.Catalog.AttributeOption(ctx, "color", "red")
+	assert.NoError(t, err)
+	assert.Equal(t, "Red", option.Labels["en_US"])
+	variant, err := c.Catalog.FamilyVariant(ctx, "shoes", "shoes_by_size")
+	assert.NoError(t, err)
+	assert.Len(t, variant.VariantAttributeSets, 1)
+	category, err := c.Catalog.Category(ctx, "master_shoes")
+	assert.NoError(t, err)
+	assert.Equal(t, "master", category.Parent)
+	locales, err := c.Catalog.Locales(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, locales, 3)
+
+	// manual invalidation
+	_, err = c.Catalog.Channels(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Seed("channels", Channel{Code: "mobile", Locales: []string{"en_US"}}))
+	_, err = c.Catalog.Channel(ctx, "mobile")
+	assert.ErrorIs(t, err, ErrNotInCatalog)
+	c.Catalog.Invalidate(CatalogChannels)
+	_, err = c.Catalog.Channel(ctx, "mobile")
+	assert.NoError(t, err)
+
+	defs, err := c.Catalog.Definitions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, defs.Options, 4)
+	assert.Len(t, defs.Channels, 3)
+}
+
+func TestCatalog_HandleEvent(t *testing.T) {
+	c, srv := newFakeClient(t, WithRateLimit(1000, time.Second), WithCatalogTTL(0))
+	ctx := context.Background()
+	_, err := c.Catalog.Families(ctx)
+	assert.NoError(t, err)
+	_, err = c.Catalog.AttributeOptions(ctx, "color")
+	assert.NoError(t, err)
+	assert.NoError(t, srv.Seed("families", Family{Code: "bags"}))
+	assert.NoError(t, srv.Seed("attributes/color/options", AttributeOption{Code: "green"}))
+	_, err = c.Catalog.Family(ctx, "bags")
+	assert.ErrorIs(t, err, ErrNotInCatalog)
+
+	// a known family and option keep the cache
+	e := Event{Action: EventTypeProductUpdated}
+	e.Data.Resource = resource{Identifier: "sku-1", Family: "shoes", Values: map[string][]ProductValue{"color": {{Data: "red"}}}}
+	assert.NoError(t, c.Catalog.HandleEvent(ctx, e))
+	_, err = c.Catalog.AttributeOption(ctx, "color", "green")
+	assert.ErrorIs(t, err, ErrNotInCatalog)
+
+	e.Data.Resource.Family = "bags"
+	e.Data.Resource.Values["color"][0].Data = "green"
+	assert.NoError(t, c.Catalog.HandleEvent(ctx, e))
+	_, err = c.Catalog.Family(ctx, "bags")
+	assert.NoError(t, err)
+	_, err = c.Catalog.AttributeOption(ctx, "color", "green")
+	assert.NoError(t, err)
+}
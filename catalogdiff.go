@@ -0,0 +1,377 @@
+package goakeneo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Catalog resources compared by DiffCatalogs and cached by Catalog, they are the export file names without extension
+const (
+	CatalogAttributes       = "attributes"
+	CatalogAttributeOptions = "attribute-options"
+	CatalogFamilies         = "families"
+	CatalogFamilyVariants   = "family-variants"
+	CatalogChannels         = "channels"
+	CatalogCategories       = "categories"
+	CatalogLocales          = "locales"
+)
+
+// CatalogResources are the resources compared by default
+var CatalogResources = []string{
+	CatalogChannels, CatalogAttributes, CatalogAttributeOptions, CatalogFamilies, CatalogFamilyVariants, CatalogCategories,
+}
+
+// Change kinds of a CatalogDiff
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// diffIgnoredFields change on every save, they are not compared
+var diffIgnoredFields = map[string]bool{"created": true, "updated": true}
+
+// CatalogSource provides the documents of the catalog resources
+type CatalogSource interface {
+	// Documents returns the documents of a resource as exported by Client.Export
+	Documents(ctx context.Context, resource string) ([]map[string]any, error)
+}
+
+// CatalogDiff is the difference between two catalogs
+type CatalogDiff struct {
+	Entities []EntityDiff `json:"entities"`
+}
+
+// EntityDiff is an added, removed or changed entity, Key is the code of the entity
+// prefixed by its attribute or family code for the options and the variants
+type EntityDiff struct {
+	Resource string      `json:"resource"`
+	Key      string      `json:"key"`
+	Change   string      `json:"change"`
+	Fields   []FieldDiff `json:"fields,omitempty"` // Fields are set for the changed entities
+}
+
+// FieldDiff is a change of a field, Path is the dotted path of the field, i.e. "labels.en_US"
+type FieldDiff struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Old    any    `json:"old,omitempty"`
+	New    any    `json:"new,omitempty"`
+}
+
+// ClientCatalogSource reads the catalog of a PIM
+func ClientCatalogSource(c *Client) CatalogSource {
+	return clientCatalogSource{client: c}
+}
+
+// SnapshotCatalogSource reads the catalog of an export directory written by Client.Export
+func SnapshotCatalogSource(dir string) CatalogSource {
+	return snapshotCatalogSource{dir: dir}
+}
+
+// DiffCatalogs compares the resources of two catalogs, all of CatalogResources when none is given.
+// The changes are those to apply on from to get to
+func DiffCatalogs(ctx context.Context, from, to CatalogSource, resources ...string) (*CatalogDiff, error) {
+	if len(resources) == 0 {
+		resources = CatalogResources
+	}
+	diff := &CatalogDiff{Entities: []EntityDiff{}}
+	for _, resource := range resources {
+		if _, ok := catalogKey(resource, nil); !ok {
+			return nil, errors.Errorf("unsupported catalog resource %q", resource)
+		}
+		old, err := catalogDocuments(ctx, from, resource)
+		if err != nil {
+			return nil, err
+		}
+		current, err := catalogDocuments(ctx, to, resource)
+		if err != nil {
+			return nil, err
+		}
+		diff.Entities = append(diff.Entities, diffDocuments(resource, old, current)...)
+	}
+	return diff, nil
+}
+
+// Empty reports whether the catalogs are the same
+func (d *CatalogDiff) Empty() bool {
+	return len(d.Entities) == 0
+}
+
+// WriteText writes the diff in a human-readable format, one line per entity and per changed field
+func (d *CatalogDiff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, e := range d.Entities {
+		switch e.Change {
+		case ChangeAdded:
+			fmt.Fprintf(&b, "+ %s %s\n", e.Resource, e.Key)
+		case ChangeRemoved:
+			fmt.Fprintf(&b, "- %s %s\n", e.Resource, e.Key)
+		default:
+			fmt.Fprintf(&b, "~ %s %s\n", e.Resource, e.Key)
+		}
+		for _, f := range e.Fields {
+			switch f.Change {
+			case ChangeAdded:
+				fmt.Fprintf(&b, "    + %s: %s\n", f.Path, diffValue(f.New))
+			case ChangeRemoved:
+				fmt.Fprintf(&b, "    - %s: %s\n", f.Path, diffValue(f.Old))
+			default:
+				fmt.Fprintf(&b, "    ~ %s: %s -> %s\n", f.Path, diffValue(f.Old), diffValue(f.New))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err, "failed to write diff")
+}
+
+// String returns the human-readable diff
+func (d *CatalogDiff) String() string {
+	var b strings.Builder
+	_ = d.WriteText(&b)
+	return b.String()
+}
+
+// catalogKey returns the key of a document, false for an unsupported resource
+func catalogKey(resource string, doc map[string]any) (string, bool) {
+	code, _ := doc["code"].(string)
+	switch resource {
+	case CatalogAttributeOptions:
+		attribute, _ := doc["attribute"].(string)
+		return attribute + "/" + code, true
+	case CatalogFamilyVariants:
+		family, _ := doc["family"].(string)
+		return family + "/" + code, true
+	case CatalogAttributes, CatalogFamilies, CatalogChannels, CatalogCategories, CatalogLocales:
+		return code, true
+	default:
+		return "", false
+	}
+}
+
+// catalogDocuments returns the documents of a resource by key
+func catalogDocuments(ctx context.Context, source CatalogSource, resource string) (map[string]map[string]any, error) {
+	docs, err := source.Documents(ctx, resource)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", resource)
+	}
+	byKey := make(map[string]map[string]any, len(docs))
+	for _, doc := range docs {
+		key, _ := catalogKey(resource, doc)
+		byKey[key] = doc
+	}
+	return byKey, nil
+}
+
+// diffDocuments compares the documents of a resource sorted by key
+func diffDocuments(resource string, old, current map[string]map[string]any) []EntityDiff {
+	keys := make([]string, 0, len(old)+len(current))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range current {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var entities []EntityDiff
+	for _, k := range keys {
+		o, inOld := old[k]
+		c, inCurrent := current[k]
+		switch {
+		case !inOld:
+			entities = append(entities, EntityDiff{Resource: resource, Key: k, Change: ChangeAdded})
+		case !inCurrent:
+			entities = append(entities, EntityDiff{Resource: resource, Key: k, Change: ChangeRemoved})
+		default:
+			if fields := diffFields("", o, c); len(fields) > 0 {
+				entities = append(entities, EntityDiff{Resource: resource, Key: k, Change: ChangeChanged, Fields: fields})
+			}
+		}
+	}
+	return entities
+}
+
+// diffFields compares two objects field by field, nested objects are compared recursively
+// and lists as a whole, see sameValue
+func diffFields(prefix string, old, current map[string]any) []FieldDiff {
+	names := make([]string, 0, len(old)+len(current))
+	for k := range old {
+		names = append(names, k)
+	}
+	for k := range current {
+		if _, ok := old[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	var fields []FieldDiff
+	for _, name := range names {
+		if prefix == "" && diffIgnoredFields[name] {
+			continue
+		}
+		path := prefix + name
+		o, c := old[name], current[name]
+		switch {
+		case isEmptyValue(o) && isEmptyValue(c):
+		case isEmptyValue(o):
+			fields = append(fields, FieldDiff{Path: path, Change: ChangeAdded, New: c})
+		case isEmptyValue(c):
+			fields = append(fields, FieldDiff{Path: path, Change: ChangeRemoved, Old: o})
+		default:
+			om, oIsObj := o.(map[string]any)
+			cm, cIsObj := c.(map[string]any)
+			if oIsObj && cIsObj {
+				fields = append(fields, diffFields(path+".", om, cm)...)
+			} else if !sameValue(o, c) {
+				fields = append(fields, FieldDiff{Path: path, Change: ChangeChanged, Old: o, New: c})
+			}
+		}
+	}
+	return fields
+}
+
+// sameValue compares two JSON values, the lists of codes such as the attributes of a family or the locales
+// of a channel are compared regardless of their order, which the PIM does not keep
+func sameValue(old, current any) bool {
+	if o, ok := codeList(old); ok {
+		if c, ok := codeList(current); ok {
+			sort.Strings(o)
+			sort.Strings(c)
+			return reflect.DeepEqual(o, c)
+		}
+	}
+	return reflect.DeepEqual(old, current)
+}
+
+// codeList returns a copy of a list of strings
+func codeList(v any) ([]string, bool) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	codes := make([]string, len(list))
+	for i, item := range list {
+		if codes[i], ok = item.(string); !ok {
+			return nil, false
+		}
+	}
+	return codes, true
+}
+
+// isEmptyValue reports whether a JSON value is missing, null, an empty string, list or object,
+// the PIM omits some of them
+func isEmptyValue(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []any:
+		return len(t) == 0
+	case map[string]any:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
+
+func diffValue(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+// clientCatalogSource lists the documents from the API
+type clientCatalogSource struct {
+	client *Client
+}
+
+func (s clientCatalogSource) Documents(ctx context.Context, resource string) ([]map[string]any, error) {
+	c := s.client
+	switch resource {
+	case CatalogChannels:
+		return listDocuments(ctx, c.Channel.ListWithPagination, nil)
+	case CatalogAttributes:
+		return listDocuments(ctx, c.Attribute.ListWithPagination, nil)
+	case CatalogFamilies:
+		return listDocuments(ctx, c.Family.ListWithPagination, nil)
+	case CatalogCategories:
+		return listDocuments(ctx, c.Category.ListWithPagination, nil)
+	case CatalogLocales:
+		return listDocuments(ctx, c.Locale.ListWithPagination, nil)
+	case CatalogAttributeOptions:
+		var docs []map[string]any
+		err := listAll(ctx, nil, c.Attribute.ListWithPagination, func(a Attribute) error {
+			if !selectAttributeTypes[a.Type] {
+				return nil
+			}
+			list := func(options any) ([]AttributeOption, Links, error) {
+				return c.Attribute.GetAttributeOptions(a.Code, options)
+			}
+			options, err := listDocuments(ctx, list, map[string]any{"attribute": a.Code})
+			docs = append(docs, options...)
+			return err
+		})
+		return docs, err
+	case CatalogFamilyVariants:
+		var docs []map[string]any
+		err := listAll(ctx, nil, c.Family.ListWithPagination, func(f Family) error {
+			variants, err := allFamilyVariants(ctx, c.Family, f.Code)
+			if err != nil {
+				return err
+			}
+			for _, v := range variants {
+				doc, err := toDocument(v, map[string]any{"family": f.Code})
+				if err != nil {
+					return err
+				}
+				docs = append(docs, doc)
+			}
+			return nil
+		})
+		return docs, err
+	default:
+		return nil, errors.Errorf("unsupported catalog resource %q", resource)
+	}
+}
+
+// listDocuments lists every item as a document with the extra properties
+func listDocuments[T any](ctx context.Context, list func(options any) ([]T, Links, error), extra map[string]any) ([]map[string]any, error) {
+	var docs []map[string]any
+	err := listAll(ctx, nil, list, func(item T) error {
+		doc, err := toDocument(item, extra)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	return docs, err
+}
+
+// snapshotCatalogSource reads the documents from the files of an export
+type snapshotCatalogSource struct {
+	dir string
+}
+
+func (s snapshotCatalogSource) Documents(_ context.Context, resource string) ([]map[string]any, error) {
+	if err := checkManifest(s.dir); err != nil {
+		return nil, err
+	}
+	var docs []map[string]any
+	err := readJSONL(filepath.Join(s.dir, resource+".jsonl"), func(item snapshotItem) error {
+		docs = append(docs, item.doc)
+		return nil
+	})
+	return docs, err
+}
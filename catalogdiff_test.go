@@ -0,0 +1,73 @@
+package goakeneo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	staging, _ := newFakeClient(t, WithRateLimit(1000, time.Second))
+	dir := t.TempDir()
+	_, err := staging.Export(context.Background(), dir, ExportOptions{})
+	assert.NoError(t, err)
+
+	production, srv := newFakeClient(t, WithRateLimit(1000, time.Second))
+	diff, err := DiffCatalogs(context.Background(), SnapshotCatalogSource(dir), ClientCatalogSource(production))
+	assert.NoError(t, err)
+	assert.True(t, diff.Empty(), diff.String())
+
+	assert.NoError(t, srv.Seed("attributes", map[string]any{"code": "name", "labels": map[string]any{"fr_FR": "Nom"}}))
+	assert.NoError(t, srv.Seed("attributes/color/options", map[string]any{"code": "red", "labels": map[string]any{"en_US": "Crimson"}}))
+	assert.NoError(t, srv.Seed("families", map[string]any{"code": "bags", "attribute_as_label": "name"}))
+	assert.True(t, srv.Delete("channels", "print"))
+	diff, err = DiffCatalogs(context.Background(), SnapshotCatalogSource(dir), ClientCatalogSource(production))
+	assert.NoError(t, err)
+	assert.Equal(t, []EntityDiff{
+		{Resource: CatalogChannels, Key: "print", Change: ChangeRemoved},
+		{Resource: CatalogAttributes, Key: "name", Change: ChangeChanged, Fields: []FieldDiff{
+			{Path: "labels.fr_FR", Change: ChangeAdded, New: "Nom"},
+		}},
+		{Resource: CatalogAttributeOptions, Key: "color/red", Change: ChangeChanged, Fields: []FieldDiff{
+			{Path: "labels.en_US", Change: ChangeChanged, Old: "Red", New: "Crimson"},
+		}},
+		{Resource: CatalogFamilies, Key: "bags", Change: ChangeAdded},
+	}, diff.Entities)
+	assert.Equal(t, `- channels print
+~ attributes name
+    + labels.fr_FR: "Nom"
+~ attribute-options color/red
+    ~ labels.en_US: "Red" -> "Crimson"
++ families bags
+`, diff.String())
+	b, err := json.Marshal(diff.Entities[2])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"resource":"attribute-options","key":"color/red","change":"changed",
+		"fields":[{"path":"labels.en_US","change":"changed","old":"Red","new":"Crimson"}]}`, string(b))
+
+	_, err = DiffCatalogs(context.Background(), SnapshotCatalogSource(dir), SnapshotCatalogSource(t.TempDir()), CatalogFamilies)
+	assert.ErrorContains(t, err, "manifest")
+	_, err = DiffCatalogs(context.Background(), SnapshotCatalogSource(dir), SnapshotCatalogSource(dir), "products")
+	assert.ErrorContains(t, err, `unsupported catalog resource "products"`)
+}
+
+func TestDiffFieldsCodeLists(t *testing.T) {
+	old := map[string]any{
+		"attributes":             []any{"sku", "name", "color"},
+		"attribute_requirements": map[string]any{"ecommerce": []any{"sku", "name"}},
+		"sets":                   []any{map[string]any{"level": 1.0}, map[string]any{"level": 2.0}},
+	}
+	current := map[string]any{
+		"attributes":             []any{"color", "sku", "name"},
+		"attribute_requirements": map[string]any{"ecommerce": []any{"name", "sku", "sku"}},
+		"sets":                   []any{map[string]any{"level": 2.0}, map[string]any{"level": 1.0}},
+	}
+	assert.Equal(t, []FieldDiff{
+		{Path: "attribute_requirements.ecommerce", Change: ChangeChanged, Old: old["attribute_requirements"].(map[string]any)["ecommerce"],
+			New: current["attribute_requirements"].(map[string]any)["ecommerce"]},
+		{Path: "sets", Change: ChangeChanged, Old: old["sets"], New: current["sets"]},
+	}, diffFields("", old, current), "the codes are compared regardless of their order, the objects in order")
+}
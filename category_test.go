@@ -7,7 +7,7 @@ import (
 )
 
 func TestCategory(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	categories, links, err := c.Category.ListWithPagination(nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, categories)
@@ -0,0 +1,226 @@
+package goakeneo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned without calling the PIM while the circuit breaker is open,
+// use errors.Is to check for it
+var ErrCircuitOpen = errors.New("akeneo circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request fast with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings configures a CircuitBreaker
+type CircuitBreakerSettings struct {
+	FailureRatio   float64       // FailureRatio of failed requests in the window opening the circuit, between 0 and 1
+	MinRequests    int           // MinRequests in the window before the failure ratio is considered
+	Window         time.Duration // Window is the period after which the counts are reset while closed
+	OpenTimeout    time.Duration // OpenTimeout is the time the circuit stays open before half-opening
+	HalfOpenProbes int           // HalfOpenProbes successful probes close the circuit, one failure opens it again
+	// OnStateChange is called after each state transition, once the breaker is unlocked so it may call State
+	OnStateChange func(from, to CircuitState)
+}
+
+// DefaultCircuitBreakerSettings returns settings opening the circuit when half of at least 10 requests
+// fail within a minute, and probing the PIM again after 30 seconds
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		FailureRatio:   0.5,
+		MinRequests:    10,
+		Window:         time.Minute,
+		OpenTimeout:    30 * time.Second,
+		HalfOpenProbes: 1,
+	}
+}
+
+func (s CircuitBreakerSettings) validate() error {
+	switch {
+	case s.FailureRatio <= 0 || s.FailureRatio > 1:
+		return errors.New("circuit breaker failure ratio must be between 0 and 1")
+	case s.MinRequests < 1 || s.HalfOpenProbes < 1:
+		return errors.New("circuit breaker min requests and half open probes must be at least 1")
+	case s.Window <= 0 || s.OpenTimeout <= 0:
+		return errors.New("circuit breaker window and open timeout must be positive")
+	default:
+		return nil
+	}
+}
+
+// CircuitBreaker fails the requests fast while the PIM is failing,
+// it is safe for concurrent use and can be shared by several clients pointing to the same PIM
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	settings    CircuitBreakerSettings
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int               // probes in flight while half-open
+	successes   int               // successful probes while half-open
+	changes     [][2]CircuitState // changes are the transitions to report once b.mu is released
+	now         func() time.Time
+}
+
+// NewCircuitBreaker creates a circuit breaker
+func NewCircuitBreaker(settings CircuitBreakerSettings) (*CircuitBreaker, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	return &CircuitBreaker{settings: settings, now: time.Now}, nil
+}
+
+// WithCircuitBreaker sets the circuit breaker of the client, it can be shared by several clients
+func WithCircuitBreaker(b *CircuitBreaker) Option {
+	return func(c *Client) {
+		c.breaker = b
+	}
+}
+
+// State returns the current state of the circuit breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh()
+	return b.state
+}
+
+// allow returns ErrCircuitOpen when the request must fail fast,
+// otherwise done must be called with the outcome of the request
+func (b *CircuitBreaker) allow() (done func(failed bool), err error) {
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh()
+	switch b.state {
+	case CircuitOpen:
+		return nil, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes+b.successes >= b.settings.HalfOpenProbes {
+			return nil, ErrCircuitOpen
+		}
+		b.probes++
+		return b.doneProbe, nil
+	default:
+		return b.done, nil
+	}
+}
+
+func (b *CircuitBreaker) done(failed bool) {
+	b.mu.Lock()
+	defer b.unlock()
+	b.refresh()
+	if b.state != CircuitClosed {
+		return
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if b.requests >= b.settings.MinRequests && float64(b.failures)/float64(b.requests) >= b.settings.FailureRatio {
+		b.setState(CircuitOpen)
+	}
+}
+
+func (b *CircuitBreaker) doneProbe(failed bool) {
+	b.mu.Lock()
+	defer b.unlock()
+	if b.state != CircuitHalfOpen {
+		return
+	}
+	b.probes--
+	if failed {
+		b.setState(CircuitOpen)
+		return
+	}
+	b.successes++
+	if b.successes >= b.settings.HalfOpenProbes {
+		b.setState(CircuitClosed)
+	}
+}
+
+// refresh half-opens the circuit after the open timeout and resets the window counts,
+// b.mu must be held
+func (b *CircuitBreaker) refresh() {
+	now := b.now()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) >= b.settings.OpenTimeout {
+			b.setState(CircuitHalfOpen)
+		}
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.settings.Window {
+			b.windowStart = now
+			b.requests, b.failures = 0, 0
+		}
+	}
+}
+
+// unlock releases b.mu and reports the transitions made while it was held
+func (b *CircuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+	if b.settings.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.settings.OnStateChange(change[0], change[1])
+	}
+}
+
+// setState resets the counts and records the transition, b.mu must be held
+func (b *CircuitBreaker) setState(to CircuitState) {
+	from := b.state
+	b.state = to
+	now := b.now()
+	b.windowStart = now
+	b.requests, b.failures, b.probes, b.successes = 0, 0, 0, 0
+	if to == CircuitOpen {
+		b.openedAt = now
+	}
+	if from != to {
+		b.changes = append(b.changes, [2]CircuitState{from, to})
+	}
+}
+
+// isFailure returns true if the request failed because of the PIM, network and server errors count.
+// Client errors, throttling, which the rate limit handles, and cancelled requests do not
+func isFailure(resp *resty.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	if resp == nil {
+		return false
+	}
+	return resp.StatusCode() >= http.StatusInternalServerError
+}
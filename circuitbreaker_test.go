@@ -0,0 +1,137 @@
+package goakeneo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var transitions []string
+	settings := CircuitBreakerSettings{
+		FailureRatio:   0.5,
+		MinRequests:    4,
+		Window:         time.Minute,
+		OpenTimeout:    10 * time.Second,
+		HalfOpenProbes: 2,
+		OnStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	}
+	b, err := NewCircuitBreaker(settings)
+	assert.NoError(t, err)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	call := func(failed bool) error {
+		done, err := b.allow()
+		if err != nil {
+			return err
+		}
+		done(failed)
+		return nil
+	}
+	assert.NoError(t, call(false))
+	assert.NoError(t, call(false))
+	assert.NoError(t, call(true))
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.NoError(t, call(true))
+	assert.Equal(t, CircuitOpen, b.State())
+	assert.ErrorIs(t, call(false), ErrCircuitOpen)
+
+	now = now.Add(settings.OpenTimeout)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	// a failed probe opens the circuit again
+	assert.NoError(t, call(true))
+	assert.Equal(t, CircuitOpen, b.State())
+
+	now = now.Add(settings.OpenTimeout)
+	done1, err := b.allow()
+	assert.NoError(t, err)
+	done2, err := b.allow()
+	assert.NoError(t, err)
+	// only HalfOpenProbes probes are in flight
+	_, err = b.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	done1(false)
+	done2(false)
+	assert.Equal(t, CircuitClosed, b.State())
+
+	assert.Equal(t, []string{
+		"closed->open", "open->half-open", "half-open->open",
+		"open->half-open", "half-open->closed",
+	}, transitions)
+}
+
+func TestCircuitBreaker_Window(t *testing.T) {
+	settings := DefaultCircuitBreakerSettings()
+	settings.MinRequests = 2
+	b, err := NewCircuitBreaker(settings)
+	assert.NoError(t, err)
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	done, _ := b.allow()
+	done(true)
+	now = now.Add(settings.Window)
+	done, _ = b.allow()
+	done(true)
+	assert.Equal(t, CircuitClosed, b.State())
+
+	_, err = NewCircuitBreaker(CircuitBreakerSettings{})
+	assert.Error(t, err)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	calls := 0
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	settings := DefaultCircuitBreakerSettings()
+	settings.MinRequests = 2
+	b, err := NewCircuitBreaker(settings)
+	assert.NoError(t, err)
+	c := newTestClient(t, srv, WithCircuitBreaker(b), WithRetry(0))
+	for i := 0; i < 2; i++ {
+		_, err = c.Family.GetFamily("shoes", nil)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	_, err = c.Family.GetFamily("shoes", nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCircuitBreaker_OnStateChangeUnlocked(t *testing.T) {
+	settings := DefaultCircuitBreakerSettings()
+	settings.MinRequests = 1
+	var b *CircuitBreaker
+	var states []CircuitState
+	settings.OnStateChange = func(_, to CircuitState) {
+		// the callback may read the breaker
+		states = append(states, b.State())
+	}
+	b, err := NewCircuitBreaker(settings)
+	assert.NoError(t, err)
+	done, err := b.allow()
+	assert.NoError(t, err)
+	done(true)
+	assert.Equal(t, []CircuitState{CircuitOpen}, states)
+}
+
+func TestIsFailure(t *testing.T) {
+	response := func(status int) *resty.Response {
+		return &resty.Response{RawResponse: &http.Response{StatusCode: status}}
+	}
+	assert.True(t, isFailure(response(http.StatusServiceUnavailable), nil))
+	assert.True(t, isFailure(nil, errors.New("connection reset by peer")))
+	assert.False(t, isFailure(response(http.StatusTooManyRequests), nil), "throttling is handled by the rate limit")
+	assert.False(t, isFailure(response(http.StatusNotFound), nil))
+	assert.False(t, isFailure(nil, &url.Error{Op: "Get", URL: "http://pim", Err: context.Canceled}))
+}
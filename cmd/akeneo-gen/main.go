@@ -0,0 +1,109 @@
+// Command akeneo-gen generates Go structs with typed fields from the families of a PIM or of an export.
+//
+// Usage:
+//
+//	akeneo-gen -snapshot backup/2023-03-01 -package catalog -out catalog/catalog_gen.go
+//	AKENEO_CLIENT_ID=... AKENEO_SECRET=... AKENEO_USERNAME=... AKENEO_PASSWORD=... \
+//		akeneo-gen -url https://pim.example.com -package catalog -families shoes,accessories
+//	akeneo-gen -snapshot backup/2023-03-01 -schemas schemas
+//
+// With -schemas, the JSON Schema of the product payload of each family is written to the directory
+// instead of the Go code.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/ezifyio/go-akeneo/codegen"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "akeneo-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("akeneo-gen", flag.ContinueOnError)
+	snapshot := fs.String("snapshot", "", "export directory to read the families from")
+	baseURL := fs.String("url", os.Getenv("AKENEO_URL"), "URL of the PIM, $AKENEO_URL")
+	pkg := fs.String("package", "catalog", "name of the generated package")
+	out := fs.String("out", "", "generated file, the standard output when empty")
+	families := fs.String("families", "", "comma separated codes of the generated families, every family when empty")
+	schemas := fs.String("schemas", "", "directory of the JSON Schemas of the families, instead of Go code")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var source goakeneo.CatalogSource
+	switch {
+	case *snapshot != "":
+		source = goakeneo.SnapshotCatalogSource(*snapshot)
+	case *baseURL != "":
+		con := goakeneo.Connector{
+			ClientID: os.Getenv("AKENEO_CLIENT_ID"),
+			Secret:   os.Getenv("AKENEO_SECRET"),
+			UserName: os.Getenv("AKENEO_USERNAME"),
+			Password: os.Getenv("AKENEO_PASSWORD"),
+		}
+		client, err := con.NewClient(goakeneo.WithBaseURL(*baseURL))
+		if err != nil {
+			return err
+		}
+		source = goakeneo.ClientCatalogSource(client)
+	default:
+		return fmt.Errorf("-snapshot or -url is required")
+	}
+	cfg := codegen.Config{Package: *pkg}
+	if *families != "" {
+		cfg.Families = strings.Split(*families, ",")
+	}
+	if *schemas != "" {
+		return writeSchemas(source, *schemas, cfg.Families)
+	}
+	fams, attributes, err := codegen.Load(context.Background(), source)
+	if err != nil {
+		return err
+	}
+	src, err := codegen.Generate(cfg, fams, attributes)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
+
+// writeSchemas writes the JSON Schema of each family to dir
+func writeSchemas(source goakeneo.CatalogSource, dir string, families []string) error {
+	defs, err := codegen.LoadDefinitions(context.Background(), source)
+	if err != nil {
+		return err
+	}
+	if len(families) == 0 {
+		for _, f := range defs.Families {
+			families = append(families, f.Code)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, family := range families {
+		schema, err := codegen.JSONSchema(*defs, family)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, codegen.SchemaFileName(family)), schema, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+
+func (a *app) get(ctx context.Context, args []string) error {
+	fs := a.flagSet("get")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return errors.New("usage: akeneo get <resource> <code>")
+	}
+	name, r, err := lookupResource(args[0])
+	if err != nil {
+		return err
+	}
+	if r.get == nil {
+		return fmt.Errorf("%s can only be listed", name)
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	item, err := r.get(ctx, c, args[1])
+	if err != nil {
+		return err
+	}
+	return printOne(a.output, a.stdout, r.columns, item)
+}
+
+func (a *app) list(ctx context.Context, args []string) error {
+	return a.listResource(ctx, "list", args, false)
+}
+
+func (a *app) search(ctx context.Context, args []string) error {
+	return a.listResource(ctx, "search", args, true)
+}
+
+func (a *app) listResource(ctx context.Context, cmd string, args []string, filters bool) error {
+	fs := a.flagSet(cmd)
+	limit := fs.Int("limit", 0, "maximum number of items, all when 0")
+	var search *searchFlags
+	if filters {
+		search = addSearchFlags(fs)
+	}
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		if filters {
+			return errors.New("usage: akeneo search <resource> -filter 'property OPERATOR value'")
+		}
+		return errors.New("usage: akeneo list <resource>")
+	}
+	_, r, err := lookupResource(args[0])
+	if err != nil {
+		return err
+	}
+	options := url.Values{}
+	if search != nil {
+		if options, err = search.options(); err != nil {
+			return err
+		}
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	p, err := newPrinter(a.output, a.stdout, r.columns)
+	if err != nil {
+		return err
+	}
+	if err := each(ctx, r, c, options, *limit, p.print); err != nil {
+		_ = p.close()
+		return err
+	}
+	return p.close()
+}
+
+// searchFlags are the flags filtering products and product models
+type searchFlags struct {
+	filters listFlag
+	search  *string
+	locale  *string
+	scope   *string
+}
+
+func addSearchFlags(fs *flag.FlagSet) *searchFlags {
+	s := &searchFlags{}
+	fs.Var(&s.filters, "filter", "filter such as 'family IN shoes,boots', 'enabled = true' or 'updated > \"2023-01-01 00:00:00\"', can be repeated")
+	s.search = fs.String("search", "", "raw JSON search query of the API, merged with the filters")
+	s.locale = fs.String("search-locale", "", "locale of the filters on localizable attributes")
+	s.scope = fs.String("search-scope", "", "channel of the filters on scopable attributes")
+	return s
+}
+
+// options returns the query parameters of the search
+func (s *searchFlags) options() (url.Values, error) {
+	options := url.Values{}
+	sf := make(goakeneo.SearchFilter)
+	if *s.search != "" {
+		if err := json.Unmarshal([]byte(*s.search), &sf); err != nil {
+			return nil, fmt.Errorf("invalid -search: %w", err)
+		}
+	}
+	for _, expr := range s.filters {
+		property, condition, err := parseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		sf[property] = append(sf[property], condition)
+	}
+	if len(sf) > 0 {
+		options.Set("search", sf.String())
+	}
+	if *s.locale != "" {
+		options.Set("search_locale", *s.locale)
+	}
+	if *s.scope != "" {
+		options.Set("search_scope", *s.scope)
+	}
+	return options, nil
+}
+
+// filterOperators are the operators of the API, the longer ones first so "NOT IN" is not read as "NOT"
+var filterOperators = []string{
+	"GREATER OR EQUALS THAN ON ALL LOCALES", "GREATER THAN ON ALL LOCALES",
+	"LOWER OR EQUALS THAN ON ALL LOCALES", "LOWER THAN ON ALL LOCALES",
+	"IN OR UNCLASSIFIED", "NOT IN CHILDREN", "SINCE LAST N DAYS", "DOES NOT CONTAIN", "SINCE LAST JOB",
+	"IN CHILDREN", "NOT BETWEEN", "UNCLASSIFIED", "STARTS WITH", "ENDS WITH", "NOT EMPTY", "CONTAINS",
+	"BETWEEN", "NOT IN", "EMPTY", "!=", "<=", ">=", "IN", "=", "<", ">",
+}
+
+// listOperators take a list of values, a comma separated value is split
+var listOperators = map[string]bool{
+	"IN": true, "NOT IN": true, "IN CHILDREN": true, "NOT IN CHILDREN": true, "IN OR UNCLASSIFIED": true,
+	"BETWEEN": true, "NOT BETWEEN": true,
+}
+
+// parseFilter parses a filter such as "family IN shoes,boots". A value which is valid JSON,
+// such as true, 80 or "123", is sent as such, any other value is a string
+func parseFilter(expr string) (string, map[string]any, error) {
+	property, rest, _ := strings.Cut(strings.TrimSpace(expr), " ")
+	rest = strings.TrimSpace(rest)
+	for _, operator := range filterOperators {
+		if len(rest) < len(operator) || !strings.EqualFold(rest[:len(operator)], operator) {
+			continue
+		}
+		raw := rest[len(operator):]
+		if raw != "" && raw[0] != ' ' && !strings.ContainsAny(operator, "=<>") {
+			continue // "INCH" does not start with "IN"
+		}
+		condition := map[string]any{"operator": operator}
+		if raw = strings.TrimSpace(raw); raw != "" {
+			condition["value"] = filterValue(operator, raw)
+		}
+		return property, condition, nil
+	}
+	return "", nil, fmt.Errorf("invalid filter %q, want 'property OPERATOR value'", expr)
+}
+
+func filterValue(operator, raw string) any {
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		value = raw
+	}
+	if !listOperators[operator] {
+		return value
+	}
+	switch v := value.(type) {
+	case []any:
+		return v
+	case string:
+		list := strings.Split(v, ",")
+		for i := range list {
+			list[i] = strings.TrimSpace(list[i])
+		}
+		return list
+	default:
+		return []any{v}
+	}
+}
+
+// flatKinds are the entities of the flat CSV files
+var flatKinds = map[string]bool{"products": true, "product-models": true}
+
+func (a *app) export(ctx context.Context, args []string) error {
+	fs := a.flagSet("export")
+	media := fs.Bool("media", false, "export the media files referenced by the products and product models")
+	csvKind := fs.String("csv", "", "export products or product-models as flat CSV to a file or to the standard output")
+	search := addSearchFlags(fs)
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	options, err := search.options()
+	if err != nil {
+		return err
+	}
+	if *csvKind != "" {
+		if !flatKinds[*csvKind] || len(args) > 1 {
+			return errors.New("usage: akeneo export -csv products|product-models [file]")
+		}
+		c, err := a.client()
+		if err != nil {
+			return err
+		}
+		w, done, err := a.create(args)
+		if err != nil {
+			return err
+		}
+		export := c.ExportProductsCSV
+		if *csvKind == "product-models" {
+			export = c.ExportProductModelsCSV
+		}
+		n, err := export(ctx, w, options)
+		if cerr := done(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "exported %d %s\n", n, *csvKind)
+		return nil
+	}
+	if len(args) != 1 {
+		return errors.New("usage: akeneo export [-media] <dir>")
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	manifest, err := c.Export(ctx, args[0], goakeneo.ExportOptions{ProductOptions: options, Media: *media})
+	if err != nil {
+		return err
+	}
+	return printOne(a.output, a.stdout, []string{"layout_version", "pim_version", "client_version", "exported_at", "counts"}, manifest)
+}
+
+func (a *app) importCmd(ctx context.Context, args []string) error {
+	fs := a.flagSet("import")
+	batchSize := fs.Int("batch-size", goakeneo.MaxPatchBatchSize, "number of items per request")
+	skipMedia := fs.Bool("skip-media", false, "keep the media values of the export instead of uploading the media files again")
+	csvKind := fs.String("csv", "", "import products or product-models from flat CSV, the standard input when no file is given")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	var report *goakeneo.ImportReport
+	if *csvKind != "" {
+		if !flatKinds[*csvKind] || len(args) > 1 {
+			return errors.New("usage: akeneo import -csv products|product-models [file]")
+		}
+		c, err := a.client()
+		if err != nil {
+			return err
+		}
+		r, done, err := a.open(args)
+		if err != nil {
+			return err
+		}
+		defer done()
+		importCSV := c.ImportProductsCSV
+		if *csvKind == "product-models" {
+			importCSV = c.ImportProductModelsCSV
+		}
+		if report, err = importCSV(ctx, r, goakeneo.FlatImportOptions{BatchSize: *batchSize}); err != nil {
+			return err
+		}
+	} else {
+		if len(args) != 1 {
+			return errors.New("usage: akeneo import <dir>")
+		}
+		c, err := a.client()
+		if err != nil {
+			return err
+		}
+		if report, err = c.Import(ctx, args[0], goakeneo.ImportOptions{BatchSize: *batchSize, SkipMedia: *skipMedia}); err != nil {
+			return err
+		}
+	}
+	if a.output == outputJSON {
+		err = printOne(a.output, a.stdout, nil, report)
+	} else {
+		err = a.printAll([]string{"file", "line", "code", "status_code", "message"}, report.Failures)
+	}
+	if err != nil {
+		return err
+	}
+	if report.HasFailures() {
+		return fmt.Errorf("%d items could not be imported", len(report.Failures))
+	}
+	return nil
+}
+
+func (a *app) diff(ctx context.Context, args []string) error {
+	fs := a.flagSet("diff")
+	only := fs.String("resources", "", "comma separated resources to compare, all by default")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return errors.New("usage: akeneo diff <profile|dir> <profile|dir>")
+	}
+	from, err := a.catalogSource(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := a.catalogSource(args[1])
+	if err != nil {
+		return err
+	}
+	var names []string
+	if *only != "" {
+		names = strings.Split(*only, ",")
+	}
+	diff, err := goakeneo.DiffCatalogs(ctx, from, to, names...)
+	if err != nil {
+		return err
+	}
+	switch a.output {
+	case outputTable:
+		return diff.WriteText(a.stdout)
+	case outputJSONL:
+		return a.printAll(nil, diff.Entities)
+	default:
+		return printOne(a.output, a.stdout, nil, diff)
+	}
+}
+
+// catalogSource returns the export directory at arg, or else the PIM of the profile named arg
+func (a *app) catalogSource(arg string) (goakeneo.CatalogSource, error) {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		return goakeneo.SnapshotCatalogSource(arg), nil
+	}
+	c, err := a.newClient(arg)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither an export directory nor a profile: %w", arg, err)
+	}
+	return goakeneo.ClientCatalogSource(c), nil
+}
+
+// mediaResult is a downloaded media file
+type mediaResult struct {
+	Code string `json:"code"`
+	Path string `json:"path"`
+}
+
+// mediaSyncResult is a goakeneo.MediaSyncReport with printable errors
+type mediaSyncResult struct {
+	Referenced int               `json:"referenced"`
+	Downloaded []string          `json:"downloaded"`
+	Skipped    []string          `json:"skipped"`
+	Failed     map[string]string `json:"failed,omitempty"`
+	Orphans    []string          `json:"orphans,omitempty"`
+}
+
+func (a *app) downloadMedia(ctx context.Context, args []string) error {
+	fs := a.flagSet("download-media")
+	dir := fs.String("dir", ".", "directory of the media files, stored under their code")
+	search := addSearchFlags(fs)
+	codes, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	options, err := search.options()
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	ms := goakeneo.NewMediaSync(c, *dir)
+	if len(codes) == 0 {
+		ms.ProductOptions = options
+		report, err := ms.Run(ctx)
+		if err != nil {
+			return err
+		}
+		result := mediaSyncResult{
+			Referenced: report.Referenced,
+			Downloaded: report.Downloaded,
+			Skipped:    report.Skipped,
+			Orphans:    report.Orphans,
+		}
+		for code, err := range report.Failed {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[code] = err.Error()
+		}
+		if err := printOne(a.output, a.stdout, []string{"referenced", "downloaded", "skipped", "failed"}, result); err != nil {
+			return err
+		}
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("%d media files could not be downloaded", len(result.Failed))
+		}
+		return nil
+	}
+	p, err := newPrinter(a.output, a.stdout, []string{"code", "path"})
+	if err != nil {
+		return err
+	}
+	for _, code := range codes {
+		path := ms.Path(code)
+		if err := c.DownloadMediaFile(ctx, code, path); err != nil {
+			_ = p.close()
+			return fmt.Errorf("unable to download %s: %w", code, err)
+		}
+		if err := p.print(mediaResult{Code: code, Path: path}); err != nil {
+			return err
+		}
+	}
+	return p.close()
+}
+
+func (a *app) patch(ctx context.Context, args []string) error {
+	fs := a.flagSet("patch")
+	batchSize := fs.Int("batch-size", goakeneo.MaxPatchBatchSize, "number of products per request")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: akeneo patch products [file]")
+	}
+	if name, _, err := lookupResource(args[0]); err != nil || name != "products" {
+		return errors.New("only products can be patched")
+	}
+	if *batchSize <= 0 || *batchSize > goakeneo.MaxPatchBatchSize {
+		*batchSize = goakeneo.MaxPatchBatchSize
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	r, done, err := a.open(args[1:])
+	if err != nil {
+		return err
+	}
+	defer done()
+	p, err := newPrinter(a.output, a.stdout, []string{"line", "identifier", "status_code", "message"})
+	if err != nil {
+		return err
+	}
+	var batch []goakeneo.ProductPatch
+	var lines []int
+	total, failed := 0, 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		responses, err := c.PatchProducts(batch)
+		if err != nil {
+			return err
+		}
+		for _, response := range responses {
+			if response.Line >= 1 && response.Line <= len(lines) {
+				response.Line = lines[response.Line-1]
+			}
+			if response.StatusCode >= http.StatusBadRequest {
+				failed++
+			}
+			if err := p.print(response); err != nil {
+				return err
+			}
+		}
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		var patch goakeneo.ProductPatch
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		decoder.UseNumber()
+		if err := decoder.Decode(&patch); err != nil {
+			return fmt.Errorf("line %d: invalid patch: %w", line, err)
+		}
+		batch, lines = append(batch, patch), append(lines, line)
+		total++
+		if len(batch) == *batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if err := p.close(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d products could not be patched", failed, total)
+	}
+	return nil
+}
+
+// printAll prints the items of a slice as a list
+func (a *app) printAll(columns []string, items any) error {
+	p, err := newPrinter(a.output, a.stdout, columns)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	for _, item := range list {
+		if err := p.print(item); err != nil {
+			return err
+		}
+	}
+	return p.close()
+}
+
+// open returns the file of args, or the standard input when args is empty
+func (a *app) open(args []string) (io.Reader, func() error, error) {
+	if len(args) == 0 || args[0] == "-" {
+		return a.stdin, func() error { return nil }, nil
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// create returns the file of args, or the standard output when args is empty
+func (a *app) create(args []string) (io.Writer, func() error, error) {
+	if len(args) == 0 || args[0] == "-" {
+		return a.stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+
+// config is the configuration file, a set of named profiles
+type config struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*profile `json:"profiles"`
+}
+
+// profile holds the URL and the credentials of a PIM
+type profile struct {
+	URL      string `json:"url"`
+	ClientID string `json:"client_id"`
+	Secret   string `json:"secret"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// configPath returns the path of the configuration file and whether it was chosen explicitly
+func (a *app) configPath() (string, bool) {
+	if a.config != "" {
+		return a.config, true
+	}
+	if p := a.getenv("AKENEO_CONFIG"); p != "" {
+		return p, true
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, "akeneo", "config.json"), false
+}
+
+// loadConfig reads the configuration file, a missing default file is an empty configuration
+func (a *app) loadConfig() (*config, error) {
+	path, explicit := a.configPath()
+	cfg := &config{}
+	if path == "" {
+		return cfg, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// resolveProfile returns the named profile, or the selected one when name is empty.
+// The AKENEO_URL, AKENEO_CLIENT_ID, AKENEO_SECRET, AKENEO_USERNAME and AKENEO_PASSWORD
+// environment variables override the fields of the selected profile
+func (a *app) resolveProfile(name string) (*profile, error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	selected := name == ""
+	if selected {
+		name = a.profile
+	}
+	if name == "" {
+		name = a.getenv("AKENEO_PROFILE")
+	}
+	if name == "" {
+		name = cfg.DefaultProfile
+	}
+	p := &profile{}
+	if name != "" {
+		found, ok := cfg.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		*p = *found
+	}
+	if selected {
+		for env, field := range map[string]*string{
+			"AKENEO_URL":       &p.URL,
+			"AKENEO_CLIENT_ID": &p.ClientID,
+			"AKENEO_SECRET":    &p.Secret,
+			"AKENEO_USERNAME":  &p.Username,
+			"AKENEO_PASSWORD":  &p.Password,
+		} {
+			if v := a.getenv(env); v != "" {
+				*field = v
+			}
+		}
+	}
+	if p.URL == "" {
+		return nil, errors.New("no PIM configured, set AKENEO_URL or select a profile of the config file")
+	}
+	return p, nil
+}
+
+// newClient returns a client of the named profile, or of the selected one when name is empty
+func (a *app) newClient(name string) (*goakeneo.Client, error) {
+	p, err := a.resolveProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	con := goakeneo.Connector{
+		ClientID: p.ClientID,
+		Secret:   p.Secret,
+		UserName: p.Username,
+		Password: p.Password,
+	}
+	return con.NewClient(goakeneo.WithBaseURL(p.URL))
+}
+
+// client returns the client of the selected profile, it is created once
+func (a *app) client() (*goakeneo.Client, error) {
+	if a.pim != nil {
+		return a.pim, nil
+	}
+	c, err := a.newClient("")
+	if err != nil {
+		return nil, err
+	}
+	a.pim = c
+	return c, nil
+}
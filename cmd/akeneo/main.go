@@ -0,0 +1,149 @@
+// Command akeneo runs everyday operations against a PIM from the command line.
+//
+// Usage:
+//
+//	akeneo [-profile name] [-output json|jsonl|table] <command> [arguments]
+//
+// Commands:
+//
+//	get <resource> <code>                        print one product, product model, family, attribute, ...
+//	list <resource>                              list products, families, attributes, channels, ...
+//	search <resource> -filter 'family IN shoes'  list the products or product models matching filters
+//	export [-media] <dir>                        export the catalog to a directory
+//	export -csv products|product-models [file]   export products or product models in the flat CSV format
+//	import <dir>                                 import an export directory
+//	import -csv products|product-models [file]   import a flat CSV file, the standard input by default
+//	diff <from> <to>                             compare the configuration of two profiles or export directories
+//	download-media [-dir dir] [code...]          download media files, those of every product by default
+//	patch products [file]                        send the JSONL product patches of a file or of the standard input
+//
+// The PIM and its credentials are read from the AKENEO_URL, AKENEO_CLIENT_ID, AKENEO_SECRET,
+// AKENEO_USERNAME and AKENEO_PASSWORD environment variables, or from a profile of the config file,
+// $AKENEO_CONFIG or akeneo/config.json in the user config directory:
+//
+//	{
+//		"default_profile": "staging",
+//		"profiles": {
+//			"staging": {"url": "https://staging.pim.example.com", "client_id": "...", "secret": "...", "username": "...", "password": "..."}
+//		}
+//	}
+//
+// The profile is chosen with -profile, $AKENEO_PROFILE or default_profile, the environment variables
+// override its fields.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	if err := a.run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "akeneo:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// app holds the global options and the streams of the command
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	config  string
+	profile string
+	output  string
+
+	pim *goakeneo.Client
+}
+
+// commands are the subcommands by name
+var commands = map[string]func(a *app, ctx context.Context, args []string) error{
+	"get":            (*app).get,
+	"list":           (*app).list,
+	"search":         (*app).search,
+	"export":         (*app).export,
+	"import":         (*app).importCmd,
+	"diff":           (*app).diff,
+	"download-media": (*app).downloadMedia,
+	"patch":          (*app).patch,
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	fs := a.flagSet("akeneo")
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "usage: akeneo [-profile name] [-output json|jsonl|table] <command> [arguments]")
+		fmt.Fprintln(a.stderr, "commands: get, list, search, export, import, diff, download-media, patch")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+	return cmd(a, ctx, fs.Args()[1:])
+}
+
+// flagSet returns a flag set with the global flags, so they can also follow the command
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	if a.output == "" {
+		a.output = outputJSON
+	}
+	fs.StringVar(&a.config, "config", a.config, "config file, $AKENEO_CONFIG or akeneo/config.json in the user config directory")
+	fs.StringVar(&a.profile, "profile", a.profile, "profile of the config file, $AKENEO_PROFILE")
+	fs.StringVar(&a.output, "output", a.output, "output format: json, jsonl or table")
+	return fs
+}
+
+// parse parses flags placed before or after the positional arguments, which are returned
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// listFlag is a flag which can be repeated
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
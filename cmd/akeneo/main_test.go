@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ezifyio/go-akeneo/akeneotest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestApp returns an app whose default profile is a fake PIM loaded with the fixtures of the library
+func newTestApp(t *testing.T, stdin string) (*app, *bytes.Buffer, *akeneotest.Server) {
+	t.Helper()
+	srv := akeneotest.NewServer()
+	t.Cleanup(srv.Close)
+	if err := srv.LoadFixtures("../../testdata"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{DefaultProfile: "fake", Profiles: map[string]*profile{
+		"fake": {URL: srv.URL, ClientID: srv.ClientID, Secret: srv.Secret, Username: srv.Username, Password: srv.Password},
+	}}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"AKENEO_CONFIG": path}
+	var stdout bytes.Buffer
+	a := &app{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &bytes.Buffer{}, getenv: func(k string) string { return env[k] }}
+	return a, &stdout, srv
+}
+
+func TestResolveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"default_profile":"staging","profiles":{
+		"staging":{"url":"https://staging.example.com","client_id":"id","secret":"s","username":"u","password":"p"},
+		"production":{"url":"https://pim.example.com"}}}`), 0600))
+	env := map[string]string{"AKENEO_CONFIG": path, "AKENEO_PASSWORD": "secret"}
+	a := &app{getenv: func(k string) string { return env[k] }}
+
+	p, err := a.resolveProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, &profile{URL: "https://staging.example.com", ClientID: "id", Secret: "s", Username: "u", Password: "secret"}, p)
+	a.profile = "production"
+	p, err = a.resolveProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://pim.example.com", p.URL)
+	p, err = a.resolveProfile("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, "p", p.Password, "the environment only overrides the selected profile")
+	_, err = a.resolveProfile("test")
+	assert.EqualError(t, err, `unknown profile "test"`)
+
+	// without config file the environment is enough
+	env = map[string]string{"AKENEO_CONFIG": filepath.Join(t.TempDir(), "missing.json")}
+	a = &app{getenv: func(k string) string { return env[k] }}
+	_, err = a.resolveProfile("")
+	assert.Error(t, err, "an explicit config file must exist")
+	a = &app{config: "", getenv: func(k string) string { return map[string]string{"AKENEO_URL": "https://pim.example.com"}[k] }}
+	_, err = a.resolveProfile("")
+	assert.NoError(t, err)
+}
+
+func TestParseFilter(t *testing.T) {
+	for expr, want := range map[string]string{
+		"family IN shoes, boots":                `{"operator":"IN","value":["shoes","boots"]}`,
+		"enabled = true":                        `{"operator":"=","value":true}`,
+		"completeness >= 80":                    `{"operator":">=","value":80}`,
+		`identifier = "123"`:                    `{"operator":"=","value":"123"}`,
+		"categories not in children master":     `{"operator":"NOT IN CHILDREN","value":["master"]}`,
+		"description EMPTY":                     `{"operator":"EMPTY"}`,
+		`updated > 2023-01-01 00:00:00`:         `{"operator":">","value":"2023-01-01 00:00:00"}`,
+		"name CONTAINS shoe":                    `{"operator":"CONTAINS","value":"shoe"}`,
+		"updated SINCE LAST N DAYS 4":           `{"operator":"SINCE LAST N DAYS","value":4}`,
+		`family IN ["shoes","accessories"]`:     `{"operator":"IN","value":["shoes","accessories"]}`,
+		"categories IN OR UNCLASSIFIED a,b":     `{"operator":"IN OR UNCLASSIFIED","value":["a","b"]}`,
+		"updated BETWEEN 2023-01-01,2023-02-01": `{"operator":"BETWEEN","value":["2023-01-01","2023-02-01"]}`,
+	} {
+		_, condition, err := parseFilter(expr)
+		if assert.NoError(t, err, expr) {
+			b, _ := json.Marshal(condition)
+			assert.JSONEq(t, want, string(b), expr)
+		}
+	}
+	_, _, err := parseFilter("family INCH shoes")
+	assert.Error(t, err)
+	_, _, err = parseFilter("family")
+	assert.Error(t, err)
+}
+
+func TestCommands(t *testing.T) {
+	ctx := context.Background()
+	a, stdout, _ := newTestApp(t, "")
+	assert.NoError(t, a.run(ctx, []string{"list", "families", "-output", "table"}))
+	assert.Equal(t, "CODE         ATTRIBUTE_AS_LABEL  LABELS\n"+
+		"accessories  name                en_US=Accessories\n"+
+		"shoes        name                en_US=Shoes\n", stdout.String())
+
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"-output", "json", "get", "product", "runner-40"}))
+	var p map[string]any
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &p))
+	assert.Equal(t, "runner", p["parent"])
+	assert.NotContains(t, p, "_links")
+
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"search", "products", "-output", "jsonl", "-filter", "parent = runner", "-limit", "2"}))
+	assert.Equal(t, 2, strings.Count(stdout.String(), "\n"))
+	assert.Contains(t, stdout.String(), `"parent":"runner"`)
+
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"search", "product", "-output", "json", "-filter", "family IN unknown"}))
+	assert.Equal(t, "[]\n", stdout.String())
+
+	err := a.run(ctx, []string{"get", "currency", "EUR"})
+	assert.EqualError(t, err, "currencies can only be listed")
+}
+
+func TestPatchCommand(t *testing.T) {
+	ctx := context.Background()
+	a, stdout, _ := newTestApp(t, `{"identifier":"runner-40","values":{"size":[{"locale":null,"scope":null,"data":"size_42"}]}}`+"\n\n"+
+		`{"family":"shoes"}`+"\n")
+	err := a.run(ctx, []string{"patch", "products", "-output", "table"})
+	assert.EqualError(t, err, "1 of 2 products could not be patched")
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, []string{"LINE", "IDENTIFIER", "STATUS_CODE", "MESSAGE"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"1", "runner-40", "204"}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{"3", "422"}, strings.Fields(lines[2])[:2])
+	}
+
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"-output", "json", "get", "products", "runner-40"}))
+	assert.Contains(t, stdout.String(), `"size_42"`)
+	assert.EqualError(t, a.run(ctx, []string{"patch", "families"}), "only products can be patched")
+}
+
+func TestExportImportDiffCommands(t *testing.T) {
+	ctx := context.Background()
+	a, stdout, srv := newTestApp(t, "")
+	dir := t.TempDir()
+	assert.NoError(t, a.run(ctx, []string{"export", "-media", filepath.Join(dir, "backup")}))
+	assert.Contains(t, stdout.String(), `"products.jsonl": 12`)
+
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"diff", "-output", "table", filepath.Join(dir, "backup"), "fake"}))
+	assert.Equal(t, "", stdout.String())
+	assert.True(t, srv.Delete("families", "accessories"))
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"diff", "-output", "table", "-resources", "families", filepath.Join(dir, "backup"), "fake"}))
+	assert.Contains(t, stdout.String(), "accessories")
+
+	csvPath := filepath.Join(dir, "products.csv")
+	assert.NoError(t, a.run(ctx, []string{"export", "-csv", "products", "-filter", "parent = runner", csvPath}))
+	b, err := os.ReadFile(csvPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(b), "runner-"))
+
+	stdout.Reset()
+	a.stdin = strings.NewReader(strings.ReplaceAll(string(b), "0.8500", "0.9000"))
+	assert.NoError(t, a.run(ctx, []string{"import", "-csv", "products", "-output", "json"}))
+	var report map[string]any
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, map[string]any{"products.csv": map[string]any{"created": 0.0, "updated": 2.0, "failed": 0.0}}, report["files"])
+
+	stdout.Reset()
+	assert.NoError(t, a.run(ctx, []string{"download-media", "-dir", filepath.Join(dir, "media"), "-output", "table"}))
+	assert.Contains(t, stdout.String(), "REFERENCED")
+	entries, err := os.ReadDir(filepath.Join(dir, "media"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, entries)
+}
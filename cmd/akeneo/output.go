@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputTable = "table"
+)
+
+// printer writes items in an output format, a JSON array is streamed item by item
+type printer struct {
+	format  string
+	w       io.Writer
+	columns []string
+	table   *tabwriter.Writer
+	count   int
+}
+
+func newPrinter(format string, w io.Writer, columns []string) (*printer, error) {
+	switch format {
+	case outputJSON, outputJSONL, outputTable:
+	default:
+		return nil, fmt.Errorf("unknown output %q, want json, jsonl or table", format)
+	}
+	if len(columns) == 0 {
+		columns = []string{"code"}
+	}
+	return &printer{format: format, w: w, columns: columns}, nil
+}
+
+// print writes one item of a list
+func (p *printer) print(item any) error {
+	defer func() { p.count++ }()
+	switch p.format {
+	case outputJSONL:
+		b, err := marshal(item, "", "")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", b)
+		return err
+	case outputTable:
+		if p.table == nil {
+			p.table = tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+			if _, err := fmt.Fprintln(p.table, strings.ToUpper(strings.Join(p.columns, "\t"))); err != nil {
+				return err
+			}
+		}
+		doc, err := document(item)
+		if err != nil {
+			return err
+		}
+		cells := make([]string, len(p.columns))
+		for i, column := range p.columns {
+			cells[i] = cell(doc[column])
+		}
+		_, err = fmt.Fprintln(p.table, strings.Join(cells, "\t"))
+		return err
+	default:
+		b, err := marshal(item, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if p.count == 0 {
+			sep = "[\n  "
+		}
+		_, err = fmt.Fprintf(p.w, "%s%s", sep, b)
+		return err
+	}
+}
+
+// close ends the list
+func (p *printer) close() error {
+	switch p.format {
+	case outputTable:
+		if p.table == nil {
+			return nil
+		}
+		return p.table.Flush()
+	case outputJSON:
+		end := "\n]\n"
+		if p.count == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(p.w, end)
+		return err
+	}
+	return nil
+}
+
+// printOne writes a single item, an object rather than a list in JSON
+func printOne(format string, w io.Writer, columns []string, item any) error {
+	if format == outputJSON {
+		b, err := marshal(item, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+	p, err := newPrinter(format, w, columns)
+	if err != nil {
+		return err
+	}
+	if err := p.print(item); err != nil {
+		return err
+	}
+	return p.close()
+}
+
+// marshal encodes an item without the "_links" of the PIM and without escaping HTML,
+// the lines are indented with indent after prefix when indent is not empty
+func marshal(item any, prefix, indent string) ([]byte, error) {
+	var v any = item
+	if doc, err := document(item); err == nil {
+		v = doc
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if indent != "" {
+		encoder.SetIndent(prefix, indent)
+	}
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// document decodes an item as a JSON object without its "_links"
+func document(item any) (map[string]any, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "_links")
+	return doc, nil
+}
+
+// cell formats a property in a table, lists are comma separated and labels are shown as locale=label
+func cell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []any:
+		cells := make([]string, len(t))
+		for i, e := range t {
+			cells[i] = cell(e)
+		}
+		return strings.Join(cells, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		cells := make([]string, len(keys))
+		for i, k := range keys {
+			cells[i] = k + "=" + cell(t[k])
+		}
+		return strings.Join(cells, ",")
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+
+// resource is a kind of entity the commands can get and list
+type resource struct {
+	columns     []string // columns are the properties shown in a table
+	searchAfter bool     // searchAfter lists with the search_after pagination, the page pagination stops at 10 000 items
+	list        func(c *goakeneo.Client) func(options any) ([]any, goakeneo.Links, error)
+	get         func(ctx context.Context, c *goakeneo.Client, code string) (any, error)
+}
+
+// resources are the resources by name, the singular names are aliases
+var resources = map[string]resource{
+	"products": {
+		searchAfter: true,
+		columns:     []string{"identifier", "family", "parent", "enabled", "categories", "updated"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Product.ListWithPagination)
+		},
+		get: func(_ context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.Product.GetProduct(code, nil)
+		},
+	},
+	"product-models": {
+		searchAfter: true,
+		columns:     []string{"code", "family", "family_variant", "parent", "categories", "updated"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.ProductModel.ListWithPagination)
+		},
+		get: func(_ context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.ProductModel.GetProductModel(code, nil)
+		},
+	},
+	"families": {
+		columns: []string{"code", "attribute_as_label", "labels"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Family.ListWithPagination)
+		},
+		get: func(_ context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.Family.GetFamily(code, nil)
+		},
+	},
+	"attributes": {
+		columns: []string{"code", "type", "group", "localizable", "scopable"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Attribute.ListWithPagination)
+		},
+		get: func(_ context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.Attribute.GetAttribute(code, nil)
+		},
+	},
+	"categories": {
+		columns: []string{"code", "parent", "labels"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Category.ListWithPagination)
+		},
+		get: func(_ context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.Category.Get(code)
+		},
+	},
+	"channels": {
+		columns: []string{"code", "locales", "currencies", "category_tree"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Channel.ListWithPagination)
+		},
+		get: func(ctx context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.Catalog.Channel(ctx, code)
+		},
+	},
+	"locales": {
+		columns: []string{"code", "enabled"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Locale.ListWithPagination)
+		},
+		get: func(ctx context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.Catalog.Locale(ctx, code)
+		},
+	},
+	"currencies": {
+		columns: []string{"code", "enabled"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.Currency.ListWithPagination)
+		},
+	},
+	"media-files": {
+		columns: []string{"code", "original_filename", "mime_type", "size"},
+		list: func(c *goakeneo.Client) func(any) ([]any, goakeneo.Links, error) {
+			return items(c.MediaFile.ListPagination)
+		},
+		get: func(_ context.Context, c *goakeneo.Client, code string) (any, error) {
+			return c.MediaFile.GetByCode(code, nil)
+		},
+	},
+}
+
+// resourceAliases are the singular names of the resources
+var resourceAliases = map[string]string{
+	"product":       "products",
+	"product-model": "product-models",
+	"family":        "families",
+	"attribute":     "attributes",
+	"category":      "categories",
+	"channel":       "channels",
+	"locale":        "locales",
+	"currency":      "currencies",
+	"media-file":    "media-files",
+}
+
+func lookupResource(name string) (string, resource, error) {
+	if alias, ok := resourceAliases[name]; ok {
+		name = alias
+	}
+	r, ok := resources[name]
+	if !ok {
+		return "", resource{}, fmt.Errorf("unknown resource %q, want one of %s", name, strings.Join(resourceNames(), ", "))
+	}
+	return name, r, nil
+}
+
+// resourceNames returns the sorted names of the resources
+func resourceNames() []string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// items adapts the ListWithPagination method of a service
+func items[T any](list func(options any) ([]T, goakeneo.Links, error)) func(any) ([]any, goakeneo.Links, error) {
+	return func(options any) ([]any, goakeneo.Links, error) {
+		page, links, err := list(options)
+		if err != nil {
+			return nil, links, err
+		}
+		result := make([]any, len(page))
+		for i, item := range page {
+			result[i] = item
+		}
+		return result, links, nil
+	}
+}
+
+// each calls fn for the items of every page of r, limit stops after as many items when positive
+func each(ctx context.Context, r resource, c *goakeneo.Client, options url.Values, limit int, fn func(any) error) error {
+	pageSize := 100
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	options.Set("limit", strconv.Itoa(pageSize))
+	if r.searchAfter {
+		options.Set("pagination_type", "search_after")
+	}
+	list := r.list(c)
+	var opts any = options
+	n := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, links, err := list(opts)
+		if err != nil {
+			return err
+		}
+		for _, item := range page {
+			if err := fn(item); err != nil {
+				return err
+			}
+			n++
+			if limit > 0 && n >= limit {
+				return nil
+			}
+		}
+		if !links.HasNext() {
+			return nil
+		}
+		opts = links.NextOptions()
+	}
+}
@@ -0,0 +1,226 @@
+// Package codegen generates Go structs with typed fields from the families and attributes of a PIM.
+//
+// Each family becomes a struct with one field per attribute, with conversions from and to goakeneo.Product:
+//
+//	shoes, err := catalog.ShoesFromProduct(p)
+//	shoes.Name["en_US"] = "Runner"
+//	_, err = client.Product.UpdateOrCreateProducts([]goakeneo.Product{shoes.Product()})
+//
+// The empty values, whose data is null, are skipped. Localizable fields are maps by locale, scopable fields are maps by channel
+// and fields both scopable and localizable are maps by channel then locale.
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/pkg/errors"
+)
+
+// Config is the configuration of Generate
+type Config struct {
+	Package  string   // Package is the name of the generated package
+	Families []string // Families are the codes of the generated families, every family when empty
+}
+
+// Load reads the families and the attributes of a PIM or of a snapshot
+func Load(ctx context.Context, source goakeneo.CatalogSource) ([]goakeneo.Family, []goakeneo.Attribute, error) {
+	var families []goakeneo.Family
+	if err := loadDocuments(ctx, source, goakeneo.CatalogFamilies, &families); err != nil {
+		return nil, nil, err
+	}
+	var attributes []goakeneo.Attribute
+	if err := loadDocuments(ctx, source, goakeneo.CatalogAttributes, &attributes); err != nil {
+		return nil, nil, err
+	}
+	return families, attributes, nil
+}
+
+func loadDocuments(ctx context.Context, source goakeneo.CatalogSource, resource string, out any) error {
+	docs, err := source.Documents(ctx, resource)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read %s", resource)
+	}
+	b, err := json.Marshal(docs)
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal %s", resource)
+	}
+	return errors.Wrapf(json.Unmarshal(b, out), "unable to unmarshal %s", resource)
+}
+
+// Generate returns the formatted Go source of the structs of the families
+func Generate(cfg Config, families []goakeneo.Family, attributes []goakeneo.Attribute) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, errors.New("package name is required")
+	}
+	byCode := make(map[string]goakeneo.Attribute, len(attributes))
+	for _, a := range attributes {
+		byCode[a.Code] = a
+	}
+	selected := make(map[string]bool, len(cfg.Families))
+	for _, code := range cfg.Families {
+		selected[code] = true
+	}
+	families = append([]goakeneo.Family(nil), families...)
+	sort.Slice(families, func(i, j int) bool { return families[i].Code < families[j].Code })
+	data := fileData{Package: cfg.Package}
+	typeNames := make(map[string]bool)
+	for _, f := range families {
+		if len(selected) > 0 && !selected[f.Code] {
+			continue
+		}
+		delete(selected, f.Code)
+		data.Families = append(data.Families, familyData(f, byCode, typeNames))
+	}
+	for code := range selected {
+		return nil, errors.Errorf("family %s does not exist", code)
+	}
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, errors.Wrap(err, "unable to generate code")
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to format generated code")
+	}
+	return src, nil
+}
+
+type fileData struct {
+	Package  string
+	Families []family
+}
+
+type family struct {
+	Code       string
+	TypeName   string
+	Identifier string // Identifier is the code of the identifier attribute of the family
+	Fields     []field
+}
+
+type field struct {
+	Name        string
+	Code        string
+	Type        string // Type is the type of one value
+	Decode      string // Decode is the goakeneo function decoding the data, empty for any
+	Set         string // Set is the condition of a set non localizable and non scopable field
+	Pointer     bool   // Pointer fields tell an unset value from a false boolean
+	Localizable bool
+	Scopable    bool
+	Comment     string
+}
+
+// GoType returns the type of the field
+func (f field) GoType() string {
+	t := f.Type
+	if f.Pointer {
+		t = "*" + t
+	}
+	switch {
+	case f.Localizable && f.Scopable:
+		return "map[string]map[string]" + f.Type
+	case f.Localizable || f.Scopable:
+		return "map[string]" + f.Type
+	default:
+		return t
+	}
+}
+
+// reservedFields are the fields of the product properties
+var reservedFields = map[string]bool{
+	"UUID": true, "Identifier": true, "Enabled": true, "Categories": true, "Groups": true, "Parent": true,
+	"Associations": true, "QuantifiedAssociations": true, "Product": true,
+}
+
+func familyData(f goakeneo.Family, attributes map[string]goakeneo.Attribute, typeNames map[string]bool) family {
+	fam := family{Code: f.Code, TypeName: uniqueName(goName(f.Code), typeNames)}
+	names := make(map[string]bool, len(reservedFields))
+	for name := range reservedFields {
+		names[name] = true
+	}
+	for _, code := range f.Attributes {
+		a, ok := attributes[code]
+		if !ok {
+			continue
+		}
+		if a.Type == "pim_catalog_identifier" {
+			fam.Identifier = a.Code
+			continue
+		}
+		fd := field{
+			Name:        uniqueName(goName(a.Code), names),
+			Code:        a.Code,
+			Localizable: a.Localizable,
+			Scopable:    a.Scopable,
+			Comment:     a.Code + " (" + strings.TrimPrefix(a.Type, "pim_catalog_") + ")",
+		}
+		switch {
+		case a.Localizable && a.Scopable:
+			fd.Comment += " by channel then locale"
+		case a.Localizable:
+			fd.Comment += " by locale"
+		case a.Scopable:
+			fd.Comment += " by channel"
+		}
+		switch a.Type {
+		case "pim_catalog_text", "pim_catalog_textarea", "pim_catalog_date", "pim_catalog_file", "pim_catalog_image",
+			"pim_catalog_simpleselect", "pim_reference_data_simpleselect":
+			fd.Type, fd.Decode, fd.Set = "string", "DecodeText", `!= ""`
+		case "pim_catalog_multiselect", "pim_reference_data_multiselect", "pim_catalog_asset_collection":
+			fd.Type, fd.Decode, fd.Set = "[]string", "DecodeTexts", "!= nil"
+		case "pim_catalog_number":
+			fd.Type, fd.Decode, fd.Set = "goakeneo.Decimal", "DecodeDecimal", `!= ""`
+		case "pim_catalog_metric":
+			fd.Type, fd.Decode, fd.Set = "goakeneo.Metric", "DecodeMetric", "!= (goakeneo.Metric{})"
+		case "pim_catalog_price_collection":
+			fd.Type, fd.Decode, fd.Set = "[]goakeneo.Price", "DecodePrices", "!= nil"
+		case "pim_catalog_boolean":
+			fd.Type, fd.Decode, fd.Set, fd.Pointer = "bool", "DecodeBool", "!= nil", true
+		default:
+			fd.Type, fd.Set = "any", "!= nil"
+		}
+		fam.Fields = append(fam.Fields, fd)
+	}
+	return fam
+}
+
+// goName converts a code to an exported Go identifier, i.e. "skc_detail_image_set" to "SkcDetailImageSet"
+func goName(code string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range code {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("A")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "Attribute"
+	}
+	return b.String()
+}
+
+// uniqueName suffixes name with a number when it is already used
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,113 @@
+package codegen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/ezifyio/go-akeneo/akeneotest"
+	"github.com/stretchr/testify/assert"
+)
+
+// examplePath is generated from the fixtures of the root package, run the tests with UPDATE_GOLDEN=1 to refresh it
+const examplePath = "example/catalog_gen.go"
+
+func loadFixtures(t *testing.T) ([]goakeneo.Family, []goakeneo.Attribute) {
+	t.Helper()
+	srv := akeneotest.NewServer()
+	t.Cleanup(srv.Close)
+	if err := srv.LoadFixtures("../testdata"); err != nil {
+		t.Fatal(err)
+	}
+	con := goakeneo.Connector{ClientID: srv.ClientID, Secret: srv.Secret, UserName: srv.Username, Password: srv.Password}
+	client, err := con.NewClient(goakeneo.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	families, attributes, err := Load(context.Background(), goakeneo.ClientCatalogSource(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return families, attributes
+}
+
+func TestGenerate(t *testing.T) {
+	families, attributes := loadFixtures(t)
+	src, err := Generate(Config{Package: "example"}, families, attributes)
+	assert.NoError(t, err)
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		assert.NoError(t, os.WriteFile(examplePath, src, 0644))
+	}
+	expected, err := os.ReadFile(examplePath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+
+	// the families of the caller are not sorted in place
+	reversed := []goakeneo.Family{families[1], families[0]}
+	_, err = Generate(Config{Package: "example"}, reversed, attributes)
+	assert.NoError(t, err)
+	assert.Equal(t, families[1].Code, reversed[0].Code)
+
+	_, err = Generate(Config{Package: "example", Families: []string{"bags"}}, families, attributes)
+	assert.EqualError(t, err, "family bags does not exist")
+	_, err = Generate(Config{}, families, attributes)
+	assert.Error(t, err)
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "SkcDetailImageSet", goName("skc_detail_image_set"))
+	assert.Equal(t, "Spu", goName("<spu>"))
+	assert.Equal(t, "A3dModel", goName("3d_model"))
+	used := map[string]bool{"Name": true}
+	assert.Equal(t, "Name2", uniqueName("Name", used))
+}
+
+func TestJSONSchema(t *testing.T) {
+	srv := akeneotest.NewServer()
+	t.Cleanup(srv.Close)
+	assert.NoError(t, srv.LoadFixtures("../testdata"))
+	con := goakeneo.Connector{ClientID: srv.ClientID, Secret: srv.Secret, UserName: srv.Username, Password: srv.Password}
+	client, err := con.NewClient(goakeneo.WithBaseURL(srv.URL), goakeneo.WithRateLimit(1000, time.Second))
+	assert.NoError(t, err)
+	defs, err := LoadDefinitions(context.Background(), goakeneo.ClientCatalogSource(client))
+	assert.NoError(t, err)
+
+	schema, err := JSONSchema(*defs, "shoes")
+	assert.NoError(t, err)
+	golden := filepath.Join("testdata", SchemaFileName("shoes"))
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		assert.NoError(t, os.WriteFile(golden, schema, 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(schema))
+
+	_, err = JSONSchema(*defs, "bags")
+	assert.EqualError(t, err, "family bags does not exist")
+}
+
+func TestExtensionPattern(t *testing.T) {
+	re := regexp.MustCompile(extensionPattern([]string{"jpg", "tar.gz"}))
+	assert.True(t, re.MatchString("shoe.JPG"))
+	assert.True(t, re.MatchString("archive.tar.gz"))
+	assert.False(t, re.MatchString("archive.tarxgz"))
+	assert.False(t, re.MatchString("manual.pdf"))
+}
+
+func TestPIMPattern(t *testing.T) {
+	pattern, ok := pimPattern("/^[A-Z]+$/")
+	assert.True(t, ok)
+	assert.Equal(t, "^[A-Z]+$", pattern)
+	// the case insensitive flag can not be expressed, the pattern is omitted
+	_, ok = pimPattern("/^[A-Z]+$/i")
+	assert.False(t, ok)
+	pattern, ok = pimPattern("^[0-9]{13}$")
+	assert.True(t, ok)
+	assert.Equal(t, "^[0-9]{13}$", pattern)
+	_, ok = pimPattern("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,2 @@
+// Package example is generated by the codegen tests from the fixtures of the goakeneo package.
+package example
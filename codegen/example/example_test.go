@@ -0,0 +1,56 @@
+package example
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShoes(t *testing.T) {
+	b, err := os.ReadFile("../../testdata/products.json")
+	assert.NoError(t, err)
+	var products []goakeneo.Product
+	assert.NoError(t, json.Unmarshal(b, &products))
+	p := products[0]
+	shoes, err := ShoesFromProduct(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "code-a90521134-6r948km3pcwxnvdy", shoes.Identifier)
+	assert.Equal(t, "Trail shoe", shoes.Name["en_US"])
+	assert.Len(t, shoes.SkcDetailImageSet, 2)
+	assert.Equal(t, "1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_0______.jpeg", shoes.Image)
+
+	shoes.Weight = goakeneo.Metric{Amount: "0.85", Unit: "KILOGRAM"}
+	shoes.Price = []goakeneo.Price{{Amount: "99.00", Currency: "EUR"}}
+	shoes.Description = map[string]map[string]string{"ecommerce": {"en_US": "A trail shoe"}}
+	back := shoes.Product()
+	assert.Equal(t, "shoes", back.Family)
+	assert.Equal(t, []goakeneo.ProductValue{{Data: shoes.Identifier}}, back.Values["sku"])
+	assert.Equal(t, []goakeneo.ProductValue{{Locale: "en_US", Scope: "ecommerce", Data: "A trail shoe"}}, back.Values["description"])
+	assert.Equal(t, []goakeneo.ProductValue{{Data: shoes.Weight}}, back.Values["weight"])
+	assert.NotContains(t, back.Values, "color")
+
+	// the typed values are decoded again
+	again, err := ShoesFromProduct(back)
+	assert.NoError(t, err)
+	assert.Equal(t, shoes, again)
+
+	// the uuid and the associations are kept and a null data is an empty value
+	p.UUID = "0d9f5a2c-bd4e-4d55-9a4e-0c1f3bb3d8a1"
+	p.Associations = map[string]goakeneo.Association{"X_SELL": {Products: []string{"runner-41"}}}
+	p.Values["color"] = []goakeneo.ProductValue{{Data: nil}}
+	shoes, err = ShoesFromProduct(p)
+	assert.NoError(t, err)
+	assert.Empty(t, shoes.Color)
+	back = shoes.Product()
+	assert.Equal(t, p.UUID, back.UUID)
+	assert.Equal(t, p.Associations, back.Associations)
+
+	_, err = AccessoriesFromProduct(p)
+	assert.Error(t, err)
+	p.Values["weight"] = []goakeneo.ProductValue{{Data: "heavy"}}
+	_, err = ShoesFromProduct(p)
+	assert.ErrorContains(t, err, "weight")
+}
@@ -0,0 +1,318 @@
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+	"github.com/pkg/errors"
+)
+
+// jsonSchemaDraft is the JSON Schema version of the generated schemas
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// numberPattern matches the decimals the PIM sends as strings
+const numberPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// LoadDefinitions reads the definitions used by JSONSchema from a PIM or a snapshot
+func LoadDefinitions(ctx context.Context, source goakeneo.CatalogSource) (*goakeneo.CatalogDefinitions, error) {
+	defs := &goakeneo.CatalogDefinitions{}
+	resources := []struct {
+		name string
+		out  any
+	}{
+		{goakeneo.CatalogFamilies, &defs.Families},
+		{goakeneo.CatalogAttributes, &defs.Attributes},
+		{goakeneo.CatalogAttributeOptions, &defs.Options},
+		{goakeneo.CatalogChannels, &defs.Channels},
+		{goakeneo.CatalogLocales, &defs.Locales},
+	}
+	for _, r := range resources {
+		if err := loadDocuments(ctx, source, r.name, r.out); err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+// SchemaFileName is the name of the schema file of a family
+func SchemaFileName(family string) string {
+	return family + ".schema.json"
+}
+
+// JSONSchema returns the JSON Schema of the PATCH payload of a product of a family: the shape of the data
+// of each attribute type, the locales and channels of the localizable and scopable values,
+// and the options of the select attributes
+func JSONSchema(defs goakeneo.CatalogDefinitions, familyCode string) ([]byte, error) {
+	var family *goakeneo.Family
+	for i := range defs.Families {
+		if defs.Families[i].Code == familyCode {
+			family = &defs.Families[i]
+		}
+	}
+	if family == nil {
+		return nil, errors.Errorf("family %s does not exist", familyCode)
+	}
+	s := newSchemaBuilder(defs)
+	values := make(map[string]any)
+	for _, code := range family.Attributes {
+		if a, ok := s.attributes[code]; ok {
+			values[code] = s.value(a)
+		}
+	}
+	stringList := map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "uniqueItems": true}
+	schema := map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   "Product of the family " + family.Code,
+		"type":    "object",
+		// a product is identified by its identifier, or by its uuid since Akeneo 7
+		"anyOf":                []any{map[string]any{"required": []string{"identifier"}}, map[string]any{"required": []string{"uuid"}}},
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"identifier":              map[string]any{"type": "string", "minLength": 1},
+			"uuid":                    map[string]any{"type": "string", "format": "uuid"},
+			"enabled":                 map[string]any{"type": "boolean"},
+			"family":                  map[string]any{"const": family.Code},
+			"categories":              stringList,
+			"groups":                  stringList,
+			"parent":                  map[string]any{"type": []string{"string", "null"}},
+			"associations":            map[string]any{"type": "object"},
+			"quantified_associations": map[string]any{"type": "object"},
+			"values": map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties":           values,
+			},
+		},
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return nil, errors.Wrap(err, "unable to marshal schema")
+	}
+	return buf.Bytes(), nil
+}
+
+// schemaBuilder holds the definitions indexed for JSONSchema
+type schemaBuilder struct {
+	attributes map[string]goakeneo.Attribute
+	options    map[string][]string
+	locales    []string // locales are the enabled locales
+	channels   []string
+	currencies []string // currencies are the currencies of the channels
+}
+
+func newSchemaBuilder(defs goakeneo.CatalogDefinitions) *schemaBuilder {
+	s := &schemaBuilder{
+		attributes: make(map[string]goakeneo.Attribute, len(defs.Attributes)),
+		options:    make(map[string][]string),
+	}
+	for _, a := range defs.Attributes {
+		s.attributes[a.Code] = a
+	}
+	for _, o := range defs.Options {
+		s.options[o.Attribute] = append(s.options[o.Attribute], o.Code)
+	}
+	for _, l := range defs.Locales {
+		if l.Enabled {
+			s.locales = append(s.locales, l.Code)
+		}
+	}
+	currencies := make(map[string]bool)
+	for _, c := range defs.Channels {
+		s.channels = append(s.channels, c.Code)
+		for _, currency := range c.Currencies {
+			currencies[currency] = true
+		}
+	}
+	for currency := range currencies {
+		s.currencies = append(s.currencies, currency)
+	}
+	sort.Strings(s.currencies)
+	return s
+}
+
+// value returns the schema of the values of an attribute
+func (s *schemaBuilder) value(a goakeneo.Attribute) map[string]any {
+	locale := map[string]any{"type": "null"}
+	if a.Localizable {
+		locales := s.locales
+		if len(a.AvailableLocales) > 0 {
+			locales = a.AvailableLocales
+		}
+		locale = map[string]any{"enum": locales}
+	}
+	scope := map[string]any{"type": "null"}
+	if a.Scopable {
+		scope = map[string]any{"enum": s.channels}
+	}
+	return map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type":                 "object",
+			"required":             []string{"locale", "scope", "data"},
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"locale": locale,
+				"scope":  scope,
+				"data":   nullable(s.data(a)),
+			},
+		},
+	}
+}
+
+// data returns the schema of the data of an attribute type
+func (s *schemaBuilder) data(a goakeneo.Attribute) map[string]any {
+	switch a.Type {
+	case "pim_catalog_identifier", "pim_catalog_textarea":
+		return map[string]any{"type": "string"}
+	case "pim_catalog_text":
+		text := map[string]any{"type": "string"}
+		if a.MaxCharacters > 0 {
+			text["maxLength"] = a.MaxCharacters
+		}
+		if pattern, ok := pimPattern(a.ValidationRegexp); ok {
+			text["pattern"] = pattern
+		}
+		return text
+	case "pim_catalog_number":
+		return number(a)
+	case "pim_catalog_metric":
+		return map[string]any{
+			"type":                 "object",
+			"required":             []string{"amount", "unit"},
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"amount": number(a),
+				"unit":   map[string]any{"type": "string"},
+			},
+		}
+	case "pim_catalog_price_collection":
+		currency := map[string]any{"type": "string"}
+		if len(s.currencies) > 0 {
+			currency = map[string]any{"enum": s.currencies}
+		}
+		return map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":                 "object",
+				"required":             []string{"amount", "currency"},
+				"additionalProperties": false,
+				"properties":           map[string]any{"amount": number(a), "currency": currency},
+			},
+		}
+	case "pim_catalog_boolean":
+		return map[string]any{"type": "boolean"}
+	case "pim_catalog_date":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "pim_catalog_file", "pim_catalog_image":
+		file := map[string]any{"type": "string"}
+		if len(a.AllowedExtensions) > 0 {
+			file["pattern"] = extensionPattern(a.AllowedExtensions)
+		}
+		return file
+	case "pim_catalog_simpleselect":
+		return s.option(a)
+	case "pim_catalog_multiselect":
+		return map[string]any{"type": "array", "items": s.option(a), "uniqueItems": true}
+	case "pim_reference_data_simpleselect":
+		return map[string]any{"type": "string"}
+	case "pim_reference_data_multiselect", "pim_catalog_asset_collection":
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "uniqueItems": true}
+	default:
+		return map[string]any{}
+	}
+}
+
+// nullable adds null to the accepted data, a null data clears a value
+func nullable(schema map[string]any) map[string]any {
+	switch {
+	case len(schema) == 0:
+		// any data
+		return schema
+	case schema["anyOf"] != nil:
+		schema["anyOf"] = append(schema["anyOf"].([]any), map[string]any{"type": "null"})
+	case schema["enum"] != nil:
+		codes := schema["enum"].([]string)
+		enum := make([]any, 0, len(codes)+1)
+		for _, code := range codes {
+			enum = append(enum, code)
+		}
+		schema["enum"] = append(enum, nil)
+	default:
+		schema["type"] = []string{schema["type"].(string), "null"}
+	}
+	return schema
+}
+
+// option returns the schema of an option code, an enumeration when the options are known
+func (s *schemaBuilder) option(a goakeneo.Attribute) map[string]any {
+	if options, ok := s.options[a.Code]; ok {
+		return map[string]any{"enum": options}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// number returns the schema of a number, a JSON number or a decimal string as sent by the PIM
+func number(a goakeneo.Attribute) map[string]any {
+	n := map[string]any{"type": "number"}
+	pattern := numberPattern
+	if !a.DecimalsAllowed {
+		n["type"] = "integer"
+		pattern = strings.Replace(pattern, `(\.[0-9]+)?`, "", 1)
+	}
+	if !a.NegativeAllowed {
+		n["minimum"] = 0
+		pattern = strings.Replace(pattern, "^-?", "^", 1)
+	}
+	if a.NumberMin != "" {
+		n["minimum"] = json.Number(a.NumberMin)
+	}
+	if a.NumberMax != "" {
+		n["maximum"] = json.Number(a.NumberMax)
+	}
+	return map[string]any{"anyOf": []any{n, map[string]any{"type": "string", "pattern": pattern}}}
+}
+
+// extensionPattern matches the file names with an extension in any case,
+// JSON Schema patterns have no case insensitive flag
+func extensionPattern(extensions []string) string {
+	alternatives := make([]string, len(extensions))
+	for i, ext := range extensions {
+		var b strings.Builder
+		for _, r := range strings.ToLower(ext) {
+			upper := strings.ToUpper(string(r))
+			if upper == string(r) {
+				b.WriteString(regexpQuote(string(r)))
+				continue
+			}
+			b.WriteString("[" + string(r) + upper + "]")
+		}
+		alternatives[i] = b.String()
+	}
+	return `\.(` + strings.Join(alternatives, "|") + `)$`
+}
+
+// pimPattern removes the delimiters of a PHP regexp such as "/^[A-Z]+$/", it returns false for an empty
+// regexp and for a regexp with flags such as "/^[A-Z]+$/i", which JSON Schema patterns can not express
+func pimPattern(expr string) (string, bool) {
+	if end := strings.LastIndex(expr, "/"); strings.HasPrefix(expr, "/") && end > 0 {
+		if end != len(expr)-1 {
+			return "", false
+		}
+		expr = expr[1:end]
+	}
+	return expr, expr != ""
+}
+
+func regexpQuote(s string) string {
+	if strings.ContainsAny(s, `\.+*?()|[]{}^$`) {
+		return `\` + s
+	}
+	return s
+}
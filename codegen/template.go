@@ -0,0 +1,123 @@
+package codegen
+
+import "text/template"
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by akeneo-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	goakeneo "github.com/ezifyio/go-akeneo"
+)
+{{range .Families}}{{$f := .}}
+// {{.TypeName}} is a product of the family {{.Code}}
+type {{.TypeName}} struct {
+	UUID                   string
+	Identifier             string
+	Enabled                bool
+	Categories             []string
+	Groups                 []string
+	Parent                 string
+	Associations           map[string]goakeneo.Association
+	QuantifiedAssociations map[string]goakeneo.QuantifiedAssociation
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} // {{.Comment}}
+{{- end}}
+}
+
+// {{.TypeName}}FromProduct converts a product of the family {{.Code}}
+func {{.TypeName}}FromProduct(p goakeneo.Product) (*{{.TypeName}}, error) {
+	if p.Family != "{{.Code}}" {
+		return nil, fmt.Errorf("product %s is not in family {{.Code}}", p.Identifier)
+	}
+	s := &{{.TypeName}}{
+		UUID:                   p.UUID,
+		Identifier:             p.Identifier,
+		Enabled:                p.Enabled,
+		Categories:             p.Categories,
+		Groups:                 p.Groups,
+		Parent:                 p.Parent,
+		Associations:           p.Associations,
+		QuantifiedAssociations: p.QuantifiedAssociations,
+	}
+{{- range .Fields}}
+	for _, v := range p.Values["{{.Code}}"] {
+		if v.Data == nil {
+			continue
+		}
+	{{- if .Decode}}
+		data, err := goakeneo.{{.Decode}}(v.Data)
+		if err != nil {
+			return nil, fmt.Errorf("{{.Code}}: %w", err)
+		}
+	{{- else}}
+		data := v.Data
+	{{- end}}
+	{{- if and .Localizable .Scopable}}
+		if s.{{.Name}} == nil {
+			s.{{.Name}} = make({{.GoType}})
+		}
+		if s.{{.Name}}[v.Scope] == nil {
+			s.{{.Name}}[v.Scope] = make(map[string]{{.Type}})
+		}
+		s.{{.Name}}[v.Scope][v.Locale] = data
+	{{- else if or .Localizable .Scopable}}
+		if s.{{.Name}} == nil {
+			s.{{.Name}} = make({{.GoType}})
+		}
+		s.{{.Name}}[v.{{if .Localizable}}Locale{{else}}Scope{{end}}] = data
+	{{- else if .Pointer}}
+		s.{{.Name}} = &data
+	{{- else}}
+		s.{{.Name}} = data
+	{{- end}}
+	}
+{{- end}}
+	return s, nil
+}
+
+// Product converts to a product of the family {{.Code}}, the empty fields have no value
+func (s *{{.TypeName}}) Product() goakeneo.Product {
+	p := goakeneo.Product{
+		UUID:                   s.UUID,
+		Identifier:             s.Identifier,
+		Enabled:                s.Enabled,
+		Family:                 "{{.Code}}",
+		Categories:             s.Categories,
+		Groups:                 s.Groups,
+		Parent:                 s.Parent,
+		Values:                 make(map[string][]goakeneo.ProductValue),
+		Associations:           s.Associations,
+		QuantifiedAssociations: s.QuantifiedAssociations,
+	}
+{{- if .Identifier}}
+	if s.Identifier != "" {
+		p.Values["{{.Identifier}}"] = []goakeneo.ProductValue{{"{{"}}Data: s.Identifier{{"}}"}}
+	}
+{{- end}}
+{{- range .Fields}}
+{{- if and .Localizable .Scopable}}
+	for scope, byLocale := range s.{{.Name}} {
+		for locale, data := range byLocale {
+			p.Values["{{.Code}}"] = append(p.Values["{{.Code}}"], goakeneo.ProductValue{Locale: locale, Scope: scope, Data: data})
+		}
+	}
+{{- else if .Localizable}}
+	for locale, data := range s.{{.Name}} {
+		p.Values["{{.Code}}"] = append(p.Values["{{.Code}}"], goakeneo.ProductValue{Locale: locale, Data: data})
+	}
+{{- else if .Scopable}}
+	for scope, data := range s.{{.Name}} {
+		p.Values["{{.Code}}"] = append(p.Values["{{.Code}}"], goakeneo.ProductValue{Scope: scope, Data: data})
+	}
+{{- else}}
+	if s.{{.Name}} {{.Set}} {
+		p.Values["{{.Code}}"] = []goakeneo.ProductValue{{"{{"}}Data: {{if .Pointer}}*{{end}}s.{{.Name}}{{"}}"}}
+	}
+{{- end}}
+{{- end}}
+	return p
+}
+{{end}}`))
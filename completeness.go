@@ -0,0 +1,196 @@
+package goakeneo
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const priceCollectionAttributeType = "pim_catalog_price_collection"
+
+// LocalCompleteness is a completeness computed by CompletenessCalculator
+type LocalCompleteness struct {
+	Completeness
+	Required int      `json:"required"`          // Required is the number of required attributes
+	Missing  []string `json:"missing,omitempty"` // Missing are the codes of the required attributes without value
+}
+
+// CompletenessCalculator computes the completeness of products and product models from the
+// attribute requirements of their family, like the PIM does. It is safe for concurrent use
+type CompletenessCalculator struct {
+	families   map[string]Family
+	variants   map[string]map[string]FamilyVariant // variants by family and code
+	attributes map[string]Attribute
+	channels   []Channel
+}
+
+// NewCompletenessCalculator returns a calculator of the definitions
+func NewCompletenessCalculator(defs CatalogDefinitions) *CompletenessCalculator {
+	c := &CompletenessCalculator{
+		families:   make(map[string]Family, len(defs.Families)),
+		variants:   make(map[string]map[string]FamilyVariant, len(defs.FamilyVariants)),
+		attributes: make(map[string]Attribute, len(defs.Attributes)),
+		channels:   defs.Channels,
+	}
+	for _, f := range defs.Families {
+		c.families[f.Code] = f
+	}
+	for family, variants := range defs.FamilyVariants {
+		c.variants[family] = make(map[string]FamilyVariant, len(variants))
+		for _, v := range variants {
+			c.variants[family][v.Code] = v
+		}
+	}
+	for _, a := range defs.Attributes {
+		c.attributes[a.Code] = a
+	}
+	return c
+}
+
+// Product computes the completeness of a product per channel and locale of its family requirements.
+// The values of a variant product are resolved from its parents, nearest first, see ResolveVariantValues.
+// A product without family has no completeness
+func (c *CompletenessCalculator) Product(p Product, parents ...ProductModel) []LocalCompleteness {
+	family, ok := c.families[p.Family]
+	if !ok {
+		return nil
+	}
+	values := ResolveVariantValues(p.Values, parents...)
+	return c.compute(family, values, func(a Attribute) bool { return true }, p.Identifier != "")
+}
+
+// ProductModel computes the completeness of a product model: only the required attributes
+// held by the model or by its parents are counted, the others are set on the variants.
+// The parents are ordered nearest first
+func (c *CompletenessCalculator) ProductModel(pm ProductModel, parents ...ProductModel) []LocalCompleteness {
+	family, ok := c.families[pm.Family]
+	if !ok {
+		return nil
+	}
+	level := len(parents)
+	variantLevel := make(map[string]int)
+	if v, ok := c.variants[pm.Family][pm.FamilyVariant]; ok {
+		for _, set := range v.VariantAttributeSets {
+			for _, code := range append(append([]string(nil), set.Axes...), set.Attributes...) {
+				variantLevel[code] = set.Level
+			}
+		}
+	}
+	values := ResolveVariantValues(pm.Values, parents...)
+	held := func(a Attribute) bool {
+		if a.Type == identifierAttributeType {
+			return false
+		}
+		l, ok := variantLevel[a.Code]
+		return !ok || l <= level
+	}
+	return c.compute(family, values, held, false)
+}
+
+// compute counts the required attributes accepted by held, per channel and locale.
+// The identifier attribute is complete when hasIdentifier is true
+func (c *CompletenessCalculator) compute(f Family, values map[string][]ProductValue, held func(Attribute) bool, hasIdentifier bool) []LocalCompleteness {
+	var result []LocalCompleteness
+	for _, channel := range c.channels {
+		required, ok := f.AttributeRequirements[channel.Code]
+		if !ok {
+			continue
+		}
+		for _, locale := range channel.Locales {
+			lc := LocalCompleteness{Completeness: Completeness{Scope: channel.Code, Locale: locale}}
+			for _, code := range required {
+				a, ok := c.attributes[code]
+				if !ok || !held(a) {
+					continue
+				}
+				if a.Localizable && len(a.AvailableLocales) > 0 && !contains(a.AvailableLocales, locale) {
+					continue
+				}
+				lc.Required++
+				complete := hasIdentifier && a.Type == identifierAttributeType
+				if !complete {
+					complete = isComplete(values[code], a, channel, locale)
+				}
+				if !complete {
+					lc.Missing = append(lc.Missing, code)
+				}
+			}
+			lc.Data = 100
+			if lc.Required > 0 {
+				lc.Data = int(math.Floor(100 * float64(lc.Required-len(lc.Missing)) / float64(lc.Required)))
+			}
+			result = append(result, lc)
+		}
+	}
+	return result
+}
+
+// isComplete reports whether a value of the channel and locale is set,
+// a price collection needs an amount in each currency of the channel
+func isComplete(values []ProductValue, a Attribute, channel Channel, locale string) bool {
+	if !a.Localizable {
+		locale = ""
+	}
+	if !hasValue(values, a, locale, channel.Code) {
+		return false
+	}
+	if a.Type != priceCollectionAttributeType {
+		return true
+	}
+	for _, v := range values {
+		if a.Scopable && v.Scope != channel.Code || v.Locale != locale {
+			continue
+		}
+		prices, _ := v.Data.([]any)
+		amounts := make(map[string]bool, len(prices))
+		for _, p := range prices {
+			m, _ := p.(map[string]any)
+			currency, _ := m["currency"].(string)
+			amounts[currency] = m["amount"] != nil && m["amount"] != ""
+		}
+		for _, currency := range channel.Currencies {
+			if !amounts[currency] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ResolveVariantValues returns the values of a variant product or product model with the values
+// of its parents, nearest first, for the attributes it does not hold itself
+func ResolveVariantValues(values map[string][]ProductValue, parents ...ProductModel) map[string][]ProductValue {
+	resolved := make(map[string][]ProductValue, len(values))
+	for code, list := range values {
+		resolved[code] = list
+	}
+	for _, parent := range parents {
+		for code, list := range parent.Values {
+			if _, ok := resolved[code]; !ok {
+				resolved[code] = list
+			}
+		}
+	}
+	return resolved
+}
+
+// ProductModelParents returns the product model of a code and its parents, nearest first,
+// the parents of a variant product are those of the code of its Parent
+func (c *Client) ProductModelParents(code string) ([]ProductModel, error) {
+	var models []ProductModel
+	seen := make(map[string]bool)
+	for code != "" {
+		if seen[code] {
+			return nil, errors.Errorf("product model %s is its own ancestor", code)
+		}
+		seen[code] = true
+		pm, err := c.ProductModel.GetProductModel(code, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get product model %s", code)
+		}
+		models = append(models, *pm)
+		code = pm.Parent
+	}
+	return models, nil
+}
@@ -0,0 +1,54 @@
+package goakeneo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProduct_Completenesses(t *testing.T) {
+	var p Product
+	err := json.Unmarshal([]byte(`{"identifier":"sku-1","completenesses":[
+		{"scope":"ecommerce","locale":"en_US","data":75},{"scope":"print","locale":"en_US","data":0}]}`), &p)
+	assert.NoError(t, err)
+	assert.Equal(t, []Completeness{{Scope: "ecommerce", Locale: "en_US", Data: 75}, {Scope: "print", Locale: "en_US", Data: 0}}, p.Completenesses)
+}
+
+func TestCompletenessCalculator(t *testing.T) {
+	c, _ := newFakeClient(t, WithRateLimit(1000, time.Second))
+	defs, err := c.LoadCatalogDefinitions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, defs.FamilyVariants["shoes"], 1)
+	calc := NewCompletenessCalculator(*defs)
+
+	p, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	parents, err := c.ProductModelParents(p.Parent)
+	assert.NoError(t, err)
+	assert.Len(t, parents, 1)
+	assert.Equal(t, []LocalCompleteness{
+		{Completeness: Completeness{Scope: "ecommerce", Locale: "en_US", Data: 75}, Required: 4, Missing: []string{"price"}},
+		{Completeness: Completeness{Scope: "ecommerce", Locale: "fr_FR", Data: 25}, Required: 4, Missing: []string{"name", "description", "price"}},
+		{Completeness: Completeness{Scope: "print", Locale: "en_US", Data: 100}, Required: 2},
+	}, calc.Product(*p, parents...))
+
+	// a price is required in every currency of the channel
+	p.Values["price"] = []ProductValue{{Data: []any{map[string]any{"amount": "99.00", "currency": "EUR"}}}}
+	assert.Equal(t, []string{"price"}, calc.Product(*p, parents...)[0].Missing)
+	p.Values["price"][0].Data = append(p.Values["price"][0].Data.([]any), map[string]any{"amount": "109.00", "currency": "USD"})
+	assert.Equal(t, 100, calc.Product(*p, parents...)[0].Data)
+
+	// the sku is set on the variants, not on the model
+	assert.Equal(t, []LocalCompleteness{
+		{Completeness: Completeness{Scope: "ecommerce", Locale: "en_US", Data: 66}, Required: 3, Missing: []string{"price"}},
+		{Completeness: Completeness{Scope: "ecommerce", Locale: "fr_FR", Data: 0}, Required: 3, Missing: []string{"name", "description", "price"}},
+		{Completeness: Completeness{Scope: "print", Locale: "en_US", Data: 100}, Required: 1},
+	}, calc.ProductModel(parents[0]))
+
+	assert.Nil(t, calc.Product(Product{Identifier: "no-family"}))
+	_, err = c.ProductModelParents("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package goakeneo
+
+const (
+	currencyBasePath = "/api/rest/v1/currencies"
+)
+
+// CurrencyService is the interface to interact with the Akeneo Currency API
+type CurrencyService interface {
+	ListWithPagination(options any) ([]Currency, Links, error)
+}
+
+type currencyOp struct {
+	client *Client
+}
+
+// ListWithPagination lists currencies with pagination
+func (c *currencyOp) ListWithPagination(options any) ([]Currency, Links, error) {
+	currencyResponse := new(CurrenciesResponse)
+	if err := c.client.GET(
+		currencyBasePath,
+		options,
+		nil,
+		currencyResponse,
+	); err != nil {
+		return nil, Links{}, err
+	}
+	return currencyResponse.Embedded.Items, currencyResponse.Links, nil
+}
+
+// CurrenciesResponse is the struct for a akeneo currencies response
+type CurrenciesResponse struct {
+	Links       Links         `json:"_links" mapstructure:"_links"`
+	CurrentPage int           `json:"current_page" mapstructure:"current_page"`
+	Embedded    currencyItems `json:"_embedded" mapstructure:"_embedded"`
+}
+
+// currencyItems is the struct for a akeneo currency items response
+type currencyItems struct {
+	Items []Currency `json:"items" mapstructure:"items"`
+}
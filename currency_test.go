@@ -0,0 +1,14 @@
+package goakeneo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyOp_ListWithPagination(t *testing.T) {
+	c, _ := newFakeClient(t)
+	currencies, _, err := c.Currency.ListWithPagination(nil)
+	assert.NoError(t, err)
+	assert.Len(t, currencies, 3)
+}
@@ -3,16 +3,20 @@ package goakeneo
 import "time"
 
 const (
-	defaultHTTPTimeout       = 10 * time.Second
-	defaultAccept            = "application/json"
-	defaultContentType       = "application/json"
-	defaultUploadContentType = "multipart/form-data"
-	defaultUserAgent         = "go-akeneo v1.0.0"
-	defaultRateLimit         = 5 // 5 requests per second
-	defaultVersion           = AkeneoPimVersion6
-	defaultRetry             = 2
-	defaultRetryWaitTime     = 3 * time.Second
-	defaultRetryMaxWaitTime  = 30 * time.Second
+	defaultHTTPTimeout      = 10 * time.Second
+	defaultAccept           = "application/json"
+	defaultContentType      = "application/json"
+	defaultPatchContentType = "application/vnd.akeneo.collection+json" // to patch several resources at once
+	defaultUserAgent        = "go-akeneo v1.0.0"
+	defaultRateLimit        = 5 // 5 requests per second
+	defaultVersion          = AkeneoPimVersion6
+	defaultRetry            = 2
+	defaultRetryWaitTime    = 3 * time.Second
+	defaultRetryMaxWaitTime = 30 * time.Second
+	defaultRetryJitter      = 0.5 // up to half of the backoff is removed at random
+	defaultDownloadResumes  = 3   // consecutive resumes of a download without receiving any byte
+	defaultCatalogTTL       = 10 * time.Minute
+	defaultEventMaxAge      = 5 * time.Minute // older webhook requests are rejected as replays
 )
 
 const (
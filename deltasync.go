@@ -0,0 +1,157 @@
+package goakeneo
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Resources synchronized by DeltaSync and Reconciler, they are the keys of their stores
+const (
+	DeltaResourceProducts      = "products"
+	DeltaResourceProductModels = "product-models"
+	DeltaResourceCategories    = "categories"
+	DeltaResourceFamilies      = "families"
+)
+
+const (
+	defaultDeltaOverlap  = 5 * time.Minute
+	defaultDeltaPageSize = 100
+)
+
+// DeltaKind tells whether an item was created or updated since the previous synchronization
+type DeltaKind int
+
+const (
+	// DeltaCreated items were created since the previous synchronization, every item is created on the first one
+	DeltaCreated DeltaKind = iota + 1
+	// DeltaUpdated items existed at the previous synchronization, or have no creation date
+	DeltaUpdated
+)
+
+// String returns the name of the kind
+func (k DeltaKind) String() string {
+	switch k {
+	case DeltaCreated:
+		return "created"
+	case DeltaUpdated:
+		return "updated"
+	default:
+		return "unknown"
+	}
+}
+
+// DeltaSync lists the items updated since the previous run of each resource.
+// The watermark of a resource is the start of its last successful run, the next run lists the items
+// updated after the watermark minus the overlap, which absorbs the clock skew between the client and the PIM
+// and the items updated while the previous run was listing. Items in the overlap are emitted twice,
+// so the handlers must be idempotent
+type DeltaSync struct {
+	client  *Client
+	store   WatermarkStore
+	Overlap time.Duration // Overlap is subtracted from the watermark, 5 minutes by default
+	now     func() time.Time
+}
+
+// DeltaResult summarizes one run of a resource
+type DeltaResult struct {
+	Resource  string
+	Since     time.Time // Since is the lower bound of the updated date, zero on the first run
+	Watermark time.Time // Watermark is the start of the run, saved when the run succeeded
+	Created   int
+	Updated   int
+}
+
+// NewDeltaSync returns a delta synchronization of the client persisting its watermarks in store
+func NewDeltaSync(c *Client, store WatermarkStore) *DeltaSync {
+	return &DeltaSync{
+		client:  c,
+		store:   store,
+		Overlap: defaultDeltaOverlap,
+		now:     time.Now,
+	}
+}
+
+// Products calls fn for every product created or updated since the previous run
+func (d *DeltaSync) Products(ctx context.Context, fn func(DeltaKind, Product) error) (DeltaResult, error) {
+	return deltaRun(ctx, d, productDeltaSearch(DeltaResourceProducts), d.client.Product.ListWithPagination,
+		func(p Product) string { return p.Created }, fn)
+}
+
+// ProductModels calls fn for every product model created or updated since the previous run
+func (d *DeltaSync) ProductModels(ctx context.Context, fn func(DeltaKind, ProductModel) error) (DeltaResult, error) {
+	return deltaRun(ctx, d, productDeltaSearch(DeltaResourceProductModels), d.client.ProductModel.ListWithPagination,
+		func(pm ProductModel) string { return pm.Created }, fn)
+}
+
+// Categories calls fn for every category updated since the previous run,
+// categories have no creation date so they are updated after the first run
+func (d *DeltaSync) Categories(ctx context.Context, fn func(DeltaKind, Category) error) (DeltaResult, error) {
+	return deltaRun(ctx, d, deltaSearch{DeltaResourceCategories, time.RFC3339, false}, d.client.Category.ListWithPagination,
+		func(Category) string { return "" }, fn)
+}
+
+// Families calls fn for every family updated since the previous run,
+// families have no creation date so they are updated after the first run
+func (d *DeltaSync) Families(ctx context.Context, fn func(DeltaKind, Family) error) (DeltaResult, error) {
+	return deltaRun(ctx, d, deltaSearch{DeltaResourceFamilies, time.RFC3339, false}, d.client.Family.ListWithPagination,
+		func(Family) string { return "" }, fn)
+}
+
+// deltaSearch is how the updated items of a resource are searched
+type deltaSearch struct {
+	resource    string
+	dateLayout  string // dateLayout is the layout of the updated filter, which differs between the resources
+	searchAfter bool   // searchAfter lists with the search_after pagination, the page pagination stops at 10 000 items
+}
+
+// productDeltaSearch searches the products and the product models, whose dates have no time zone
+func productDeltaSearch(resource string) deltaSearch {
+	return deltaSearch{resource: resource, dateLayout: searchDateLayout, searchAfter: true}
+}
+
+// deltaRun lists the items of a resource updated since its watermark and saves the new watermark
+// once every item was handled
+func deltaRun[T any](ctx context.Context, d *DeltaSync, search deltaSearch, list func(options any) ([]T, Links, error),
+	created func(T) string, fn func(DeltaKind, T) error) (DeltaResult, error) {
+	resource := search.resource
+	result := DeltaResult{Resource: resource, Watermark: d.now()}
+	watermark, ok, err := d.store.Load(resource)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to load the watermark of %s", resource)
+	}
+	options := url.Values{"limit": {strconv.Itoa(defaultDeltaPageSize)}}
+	if search.searchAfter {
+		setSearchAfter(options)
+	}
+	if ok {
+		result.Since = watermark.Add(-d.Overlap)
+		sf := make(SearchFilter)
+		sf.Add("updated", ">", result.Since.UTC().Format(search.dateLayout))
+		options.Set("search", sf.String())
+	}
+	err = listAll(ctx, options, list, func(item T) error {
+		kind := DeltaUpdated
+		if !ok {
+			kind = DeltaCreated
+		} else if t, err := time.Parse(time.RFC3339, created(item)); err == nil && t.After(result.Since) {
+			kind = DeltaCreated
+		}
+		if kind == DeltaCreated {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		return fn(kind, item)
+	})
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to synchronize %s", resource)
+	}
+	if err := d.store.Save(resource, result.Watermark); err != nil {
+		return result, errors.Wrapf(err, "unable to save the watermark of %s", resource)
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package goakeneo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeltaSync_Products(t *testing.T) {
+	c, srv := newFakeClient(t)
+	store := NewMemoryWatermarkStore()
+	ds := NewDeltaSync(c, store)
+	firstRun := time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC)
+	ds.now = func() time.Time { return firstRun }
+
+	kinds := make(map[string]DeltaKind)
+	collect := func(kind DeltaKind, p Product) error {
+		kinds[p.Identifier] = kind
+		return nil
+	}
+	result, err := ds.Products(context.Background(), collect)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, result.Created)
+	assert.True(t, result.Since.IsZero())
+	watermark, ok, err := store.Load(DeltaResourceProducts)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, firstRun, watermark)
+
+	// one product is updated and one is created after the first run
+	srv.Now = func() time.Time { return firstRun.Add(time.Hour) }
+	resp, err := c.Product.UpdateOrCreateProducts([]Product{
+		{Identifier: "accessory-01", Enabled: true},
+		{Identifier: "accessory-09", Family: "accessories"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp, 2)
+	ds.now = func() time.Time { return firstRun.Add(24 * time.Hour) }
+	kinds = make(map[string]DeltaKind)
+	result, err = ds.Products(context.Background(), collect)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]DeltaKind{"accessory-01": DeltaUpdated, "accessory-09": DeltaCreated}, kinds)
+	assert.Equal(t, firstRun.Add(-ds.Overlap), result.Since)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+}
+
+func TestDeltaSync_UpdatedFilter(t *testing.T) {
+	searches := make(map[string]url.Values)
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		searches[r.URL.Path] = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"_links":{},"_embedded":{"items":[]}}`))
+	})
+	c := newTestClient(t, srv)
+	store := NewMemoryWatermarkStore()
+	watermark := time.Date(2023, 3, 2, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	for _, resource := range []string{DeltaResourceProducts, DeltaResourceFamilies, DeltaResourceCategories} {
+		assert.NoError(t, store.Save(resource, watermark))
+	}
+	ds := NewDeltaSync(c, store)
+	_, err := ds.Products(context.Background(), func(DeltaKind, Product) error { return nil })
+	assert.NoError(t, err)
+	_, err = ds.Families(context.Background(), func(DeltaKind, Family) error { return nil })
+	assert.NoError(t, err)
+	_, err = ds.Categories(context.Background(), func(DeltaKind, Category) error { return nil })
+	assert.NoError(t, err)
+
+	// the products take a date and a time, the families and the categories an ISO 8601 date
+	products := searches[productBasePath]
+	assert.Equal(t, `{"updated":[{"operator":">","value":"2023-03-01 22:55:00"}]}`, products.Get("search"))
+	assert.Equal(t, "search_after", products.Get("pagination_type"))
+	assert.Equal(t, `{"updated":[{"operator":">","value":"2023-03-01T22:55:00Z"}]}`, searches[familyBasePath].Get("search"))
+	assert.Equal(t, `{"updated":[{"operator":">","value":"2023-03-01T22:55:00Z"}]}`, searches[categoryBasePath].Get("search"))
+	assert.Empty(t, searches[familyBasePath].Get("pagination_type"))
+}
+
+func TestDeltaSync_HandlerError(t *testing.T) {
+	c, _ := newFakeClient(t)
+	store := NewMemoryWatermarkStore()
+	ds := NewDeltaSync(c, store)
+	_, err := ds.Categories(context.Background(), func(DeltaKind, Category) error {
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	// the failed run is listed again next time
+	_, ok, err := store.Load(DeltaResourceCategories)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFileWatermarkStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "watermarks.json")
+	store := NewFileWatermarkStore(path)
+	_, ok, err := store.Load(DeltaResourceFamilies)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	watermark := time.Date(2023, 3, 2, 1, 2, 3, 0, time.UTC)
+	assert.NoError(t, store.Save(DeltaResourceFamilies, watermark))
+	assert.NoError(t, store.Save(DeltaResourceProducts, watermark.Add(time.Hour)))
+	loaded, ok, err := NewFileWatermarkStore(path).Load(DeltaResourceFamilies)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, watermark.Equal(loaded))
+}
@@ -55,15 +55,22 @@ type Product struct {
 	Groups                 []string                         `json:"groups,omitempty" mapstructure:"groups"`
 	Parent                 string                           `json:"parent,omitempty" mapstructure:"parent"` // code of the parent product model when the product is a variant
 	Values                 map[string][]ProductValue        `json:"values,omitempty" mapstructure:"values"`
-	Associations           map[string]association           `json:"associations,omitempty" mapstructure:"associations"`
-	QuantifiedAssociations map[string]quantifiedAssociation `json:"quantified_associations,omitempty" mapstructure:"quantified_associations"` // Since Akeneo 5.0
+	Associations           map[string]Association           `json:"associations,omitempty" mapstructure:"associations"`
+	QuantifiedAssociations map[string]QuantifiedAssociation `json:"quantified_associations,omitempty" mapstructure:"quantified_associations"` // Since Akeneo 5.0
 	Created                string                           `json:"created,omitempty" mapstructure:"created"`
 	Updated                string                           `json:"updated,omitempty" mapstructure:"updated"`
 	QualityScores          []QualityScore                   `json:"quality_scores,omitempty" mapstructure:"quality_scores"` // Since Akeneo 5.0,WithQualityScores must be true in the request
-	Completenesses         []any                            `json:"completenesses,omitempty" mapstructure:"completenesses"` // Since Akeneo 6.0,WithCompleteness must be true in the request
+	Completenesses         []Completeness                   `json:"completenesses,omitempty" mapstructure:"completenesses"` // Since Akeneo 6.0,WithCompleteness must be true in the request
 	Metadata               map[string]string                `json:"metadata,omitempty" mapstructure:"metadata"`             // Enterprise Edition only
 }
 
+// Completeness is the completeness of a product for a channel and a locale
+type Completeness struct {
+	Scope  string `json:"scope,omitempty" mapstructure:"scope"`
+	Locale string `json:"locale,omitempty" mapstructure:"locale"`
+	Data   int    `json:"data" mapstructure:"data"` // Data is the percentage of the required attributes having a value
+}
+
 // Links is the struct for akeneo links
 type Links struct {
 	Self     Link `json:"self,omitempty"`
@@ -494,8 +501,8 @@ type ProductModel struct {
 	Parent                 string                           `json:"parent,omitempty" mapstructure:"parent"`
 	Categories             []string                         `json:"categories,omitempty" mapstructure:"categories"`
 	Values                 map[string][]ProductValue        `json:"values,omitempty" mapstructure:"values"`
-	Associations           map[string]association           `json:"associations,omitempty" mapstructure:"associations"`
-	QuantifiedAssociations map[string]quantifiedAssociation `json:"quantified_associations,omitempty" mapstructure:"quantified_associations"`
+	Associations           map[string]Association           `json:"associations,omitempty" mapstructure:"associations"`
+	QuantifiedAssociations map[string]QuantifiedAssociation `json:"quantified_associations,omitempty" mapstructure:"quantified_associations"`
 	Metadata               map[string]string                `json:"metadata,omitempty" mapstructure:"metadata"`
 	Created                string                           `json:"created,omitempty" mapstructure:"created"`
 	Updated                string                           `json:"updated,omitempty" mapstructure:"updated"`
@@ -513,24 +520,27 @@ func (p ProductModel) validateBeforeCreate() error {
 	return nil
 }
 
-type association struct {
+// Association lists the groups, products and product models associated with an association type
+type Association struct {
 	Groups        []string `json:"groups,omitempty" mapstructure:"groups"`
 	Products      []string `json:"products,omitempty" mapstructure:"products"`
 	ProductModels []string `json:"product_models,omitempty" mapstructure:"product_models"`
 }
 
-// QuantifiedAssociations is the struct for an akeneo quantified associations
-type quantifiedAssociation struct {
-	Products      []productQuantity      `json:"products,omitempty" mapstructure:"products"`
-	ProductModels []productModelQuantity `json:"product_models,omitempty" mapstructure:"product_models"`
+// QuantifiedAssociation is the struct for an akeneo quantified association
+type QuantifiedAssociation struct {
+	Products      []ProductQuantity      `json:"products,omitempty" mapstructure:"products"`
+	ProductModels []ProductModelQuantity `json:"product_models,omitempty" mapstructure:"product_models"`
 }
 
-type productQuantity struct {
+// ProductQuantity is a product of a quantified association
+type ProductQuantity struct {
 	Identifier string `json:"identifier,omitempty" mapstructure:"identifier"`
 	Quantity   int    `json:"quantity,omitempty" mapstructure:"quantity"`
 }
 
-type productModelQuantity struct {
+// ProductModelQuantity is a product model of a quantified association
+type ProductModelQuantity struct {
 	Code     string `json:"code,omitempty" mapstructure:"code"`
 	Quantity int    `json:"quantity,omitempty" mapstructure:"quantity"`
 }
@@ -567,6 +577,16 @@ type variantAttributeSet struct {
 	Attributes []string `json:"attributes,omitempty" mapstructure:"attributes"` // The attributes of the variant attribute set
 }
 
+// AttributeGroup is the struct for an akeneo attribute group,see:
+// https://api.akeneo.com/api-reference.html#Attributegroup
+type AttributeGroup struct {
+	Links      *Links            `json:"_links,omitempty" mapstructure:"_links"`
+	Code       string            `json:"code,omitempty" mapstructure:"code"`
+	SortOrder  int               `json:"sort_order,omitempty" mapstructure:"sort_order"`
+	Attributes []string          `json:"attributes,omitempty" mapstructure:"attributes"`
+	Labels     map[string]string `json:"labels,omitempty" mapstructure:"labels"`
+}
+
 // Attribute is the struct for an akeneo attribute,see:
 // https://api.akeneo.com/api-reference.html#Attribute
 type Attribute struct {
@@ -649,6 +669,14 @@ type Locale struct {
 	Enabled bool   `json:"enabled,omitempty" mapstructure:"enabled"`
 }
 
+// Currency is the struct for an akeneo currency
+type Currency struct {
+	Links   Links  `json:"_links,omitempty" mapstructure:"_links"`
+	Code    string `json:"code,omitempty" mapstructure:"code"`
+	Enabled bool   `json:"enabled,omitempty" mapstructure:"enabled"`
+	Label   string `json:"label,omitempty" mapstructure:"label"` // since Akeneo 7.0
+}
+
 // MediaFile is the struct for an akeneo media file
 type MediaFile struct {
 	Code             string `json:"code,omitempty" mapstructure:"code"`
@@ -1,5 +1,19 @@
 package goakeneo
 
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 const (
 	EventTypeProductCreated      = "product.created"
 	EventTypeProductUpdated      = "product.updated"
@@ -52,9 +66,93 @@ type resource struct {
 	Categories             []string                         `json:"categories,omitempty" mapstructure:"categories"`
 	Groups                 []string                         `json:"groups,omitempty" mapstructure:"groups"`
 	Parent                 string                           `json:"parent,omitempty" mapstructure:"parent"`
-	Values                 []ProductValue                   `json:"values,omitempty" mapstructure:"values"`
-	QuantifiedAssociations map[string]quantifiedAssociation `json:"quantified_associations,omitempty" mapstructure:"quantified_associations"`
-	Associations           map[string]association           `json:"associations,omitempty" mapstructure:"associations"`
+	Values                 map[string][]ProductValue        `json:"values,omitempty" mapstructure:"values"`
+	QuantifiedAssociations map[string]QuantifiedAssociation `json:"quantified_associations,omitempty" mapstructure:"quantified_associations"`
+	Associations           map[string]Association           `json:"associations,omitempty" mapstructure:"associations"`
 	Created                string                           `json:"created,omitempty" mapstructure:"created"`
 	Updated                string                           `json:"updated,omitempty" mapstructure:"updated"`
 }
+
+// Headers of the requests of the Akeneo event platform
+const (
+	EventSignatureHeader = "X-Akeneo-Request-Signature"
+	EventTimestampHeader = "X-Akeneo-Request-Timestamp"
+)
+
+// EventFunc handles one event received by an EventHandler
+type EventFunc func(ctx context.Context, e Event) error
+
+// EventHandler serves the webhook of an event subscription, it checks the signature of the requests
+// and calls its functions for each event of the request
+type EventHandler struct {
+	secret string
+	funcs  []EventFunc
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewEventHandler returns a webhook handler of the events signed with the secret of the subscription.
+// The functions are called in order, i.e. Catalog.HandleEvent before the function processing the events
+func NewEventHandler(secret string, funcs ...EventFunc) *EventHandler {
+	return &EventHandler{secret: secret, funcs: funcs, maxAge: defaultEventMaxAge, now: time.Now}
+}
+
+// ServeHTTP answers 401 to the requests with an invalid signature or too old,
+// and 500 when a function fails so the PIM delivers the events again
+func (h *EventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "unable to read the request", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var payload struct {
+		Events []Event `json:"events"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid events", http.StatusBadRequest)
+		return
+	}
+	for _, e := range payload.Events {
+		for _, fn := range h.funcs {
+			if err := fn(r.Context(), e); err != nil {
+				http.Error(w, "unable to handle event "+e.EventID, http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the signature, a HMAC SHA256 of the timestamp, a dot and the body
+func (h *EventHandler) verify(header http.Header, body []byte) error {
+	timestamp := header.Get(EventTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid request timestamp")
+	}
+	if h.now().Sub(time.Unix(seconds, 0)) > h.maxAge {
+		return errors.New("request timestamp is too old")
+	}
+	signature, err := hex.DecodeString(header.Get(EventSignatureHeader))
+	if err != nil || !hmac.Equal(signature, SignEvent(h.secret, timestamp, body)) {
+		return errors.New("invalid request signature")
+	}
+	return nil
+}
+
+// SignEvent returns the signature of an event request, i.e. to test an EventHandler
+func SignEvent(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,55 @@
+package goakeneo
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testEvents = `{"events":[{"action":"product.updated","event_id":"e-1","author":"julia","author_type":"ui",
+"event_datetime":"2023-03-01T10:00:00+00:00","pim_source":"https://pim.example.com","data":{"resource":{
+"identifier":"runner-40","family":"shoes","values":{"name":[{"locale":"en_US","scope":null,"data":"Runner"}]}}}}]}`
+
+func signedEventRequest(secret string, sentAt time.Time, body string) *http.Request {
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	r.Header.Set(EventTimestampHeader, timestamp)
+	r.Header.Set(EventSignatureHeader, hex.EncodeToString(SignEvent(secret, timestamp, []byte(body))))
+	return r
+}
+
+func TestEventHandler(t *testing.T) {
+	var received []Event
+	h := NewEventHandler("webhook-secret", func(_ context.Context, e Event) error {
+		received = append(received, e)
+		return nil
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedEventRequest("webhook-secret", time.Now(), testEvents))
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, "runner-40", received[0].ID())
+		assert.Equal(t, "Runner", received[0].Data.Resource.Values["name"][0].Data)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, signedEventRequest("other-secret", time.Now(), testEvents))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, signedEventRequest("webhook-secret", time.Now().Add(-time.Hour), testEvents))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Len(t, received, 1)
+
+	failing := NewEventHandler("webhook-secret", func(context.Context, Event) error { return errors.New("queue is full") })
+	w = httptest.NewRecorder()
+	failing.ServeHTTP(w, signedEventRequest("webhook-secret", time.Now(), testEvents))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,364 @@
+package goakeneo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// exportLayoutVersion is the version of the export directory layout, it changes when a file changes
+const exportLayoutVersion = 1
+
+// Export files, their names match the fixture names of the akeneotest package
+const (
+	ExportFileChannels         = "channels.jsonl"
+	ExportFileLocales          = "locales.jsonl"
+	ExportFileCurrencies       = "currencies.jsonl"
+	ExportFileAttributeGroups  = "attribute-groups.jsonl" // groups are written without their "attributes"
+	ExportFileAttributes       = "attributes.jsonl"
+	ExportFileAttributeOptions = "attribute-options.jsonl" // one option per line with its "attribute"
+	ExportFileFamilies         = "families.jsonl"
+	ExportFileFamilyVariants   = "family-variants.jsonl" // one variant per line with its "family"
+	ExportFileCategories       = "categories.jsonl"
+	ExportFileProductModels    = "product-models.jsonl"
+	ExportFileProducts         = "products.jsonl"
+	ExportFileMediaFiles       = "media-files.jsonl"
+	ExportFileManifest         = "manifest.json"
+	ExportMediaDir             = "media" // media file contents are stored under media/<code>
+)
+
+// ExportOptions are the options of Client.Export
+type ExportOptions struct {
+	ProductOptions      any  // ProductOptions such as ProductListOptions filter the exported products
+	ProductModelOptions any  // ProductModelOptions such as ProductModelListOptions filter the exported product models
+	Media               bool // Media exports the media files referenced by the products and product models
+}
+
+// ExportManifest describes an export, it is written last so a directory without manifest is incomplete
+type ExportManifest struct {
+	LayoutVersion int            `json:"layout_version"`
+	PIMVersion    string         `json:"pim_version,omitempty"` // PIMVersion is read from the PIM, empty before Akeneo 7
+	PIMEdition    string         `json:"pim_edition,omitempty"`
+	ClientVersion string         `json:"client_version"` // ClientVersion is the version set with WithVersion
+	ExportedAt    time.Time      `json:"exported_at"`
+	Counts        map[string]int `json:"counts"` // Counts are the number of items by file
+}
+
+// selectAttributeTypes are the attribute types having options
+var selectAttributeTypes = map[string]bool{
+	"pim_catalog_simpleselect": true,
+	"pim_catalog_multiselect":  true,
+}
+
+// Export dumps the catalog to dir with one JSONL file per resource and a manifest.
+// The "_links" of the PIM are removed so the files can be loaded in another PIM or in akeneotest
+func (c *Client) Export(ctx context.Context, dir string, opts ExportOptions) (*ExportManifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create dir, path: %s", dir)
+	}
+	manifest := &ExportManifest{
+		LayoutVersion: exportLayoutVersion,
+		ClientVersion: pimVersionMap[c.osVersion],
+		ExportedAt:    time.Now().UTC(),
+		Counts:        make(map[string]int),
+	}
+	// the version is informative, an export from a client without HTTP such as akeneomem has none
+	if info, err := c.SystemInformation(ctx); err != nil {
+		c.logger.Warn("akeneo system information unavailable", slog.Any("error", err))
+	} else if info != nil {
+		manifest.PIMVersion, manifest.PIMEdition = info.Version, info.Edition
+	}
+	e := &exporter{ctx: ctx, dir: dir, manifest: manifest, media: make(map[string]struct{})}
+	steps := []func() error{
+		func() error {
+			return exportList(e, ExportFileChannels, nil, c.Channel.ListWithPagination, nil)
+		},
+		func() error {
+			return exportList(e, ExportFileLocales, nil, c.Locale.ListWithPagination, nil)
+		},
+		func() error {
+			return exportList(e, ExportFileCurrencies, nil, c.Currency.ListWithPagination, nil)
+		},
+		func() error {
+			return e.attributeGroups(c)
+		},
+		func() error {
+			return e.attributes(c)
+		},
+		func() error {
+			return e.families(c)
+		},
+		func() error {
+			return exportList(e, ExportFileCategories, nil, c.Category.ListWithPagination, nil)
+		},
+		func() error {
+			options, err := searchAfterOptions(opts.ProductModelOptions)
+			if err != nil {
+				return err
+			}
+			return exportList(e, ExportFileProductModels, options, c.ProductModel.ListWithPagination, func(pm ProductModel) {
+				e.collectMedia(pm.Values)
+			})
+		},
+		func() error {
+			options, err := searchAfterOptions(opts.ProductOptions)
+			if err != nil {
+				return err
+			}
+			return exportList(e, ExportFileProducts, options, c.Product.ListWithPagination, func(p Product) {
+				e.collectMedia(p.Values)
+			})
+		},
+	}
+	if opts.Media {
+		steps = append(steps, func() error {
+			return e.mediaFiles(c)
+		})
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal manifest")
+	}
+	if err := writeFileAtomic(filepath.Join(dir, ExportFileManifest), b); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// exporter holds the state of one export
+type exporter struct {
+	ctx      context.Context
+	dir      string
+	manifest *ExportManifest
+	media    map[string]struct{} // media are the codes of the media files referenced by the exported values
+}
+
+// exportList writes every item listed from options to one file, fn is called for each item when not nil
+func exportList[T any](e *exporter, name string, options any, list func(options any) ([]T, Links, error), fn func(T)) error {
+	return e.write(name, func(w *jsonlWriter) error {
+		return listAll(e.ctx, options, list, func(item T) error {
+			if fn != nil {
+				fn(item)
+			}
+			return w.write(item, nil)
+		})
+	})
+}
+
+// write creates the file name and calls fill to write its lines, the file is renamed once complete
+func (e *exporter) write(name string, fill func(w *jsonlWriter) error) error {
+	w, err := newJSONLWriter(filepath.Join(e.dir, name))
+	if err != nil {
+		return err
+	}
+	if err := fill(w); err != nil {
+		w.abort()
+		return errors.Wrapf(err, "unable to export %s", name)
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+	e.manifest.Counts[name] = w.count
+	return nil
+}
+
+// attributeGroups exports the attribute groups without their attributes, which do not exist yet when
+// the groups are imported, the attributes are added back to their group when they are imported
+func (e *exporter) attributeGroups(c *Client) error {
+	return e.write(ExportFileAttributeGroups, func(w *jsonlWriter) error {
+		return listAll(e.ctx, nil, c.AttributeGroup.ListWithPagination, func(g AttributeGroup) error {
+			g.Attributes = nil
+			return w.write(g, nil)
+		})
+	})
+}
+
+// attributes exports the attributes and the options of the select attributes
+func (e *exporter) attributes(c *Client) error {
+	var selects []string
+	err := exportList(e, ExportFileAttributes, nil, c.Attribute.ListWithPagination, func(a Attribute) {
+		if selectAttributeTypes[a.Type] {
+			selects = append(selects, a.Code)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return e.write(ExportFileAttributeOptions, func(w *jsonlWriter) error {
+		for _, code := range selects {
+			list := func(options any) ([]AttributeOption, Links, error) {
+				return c.Attribute.GetAttributeOptions(code, options)
+			}
+			err := listAll(e.ctx, AttributeOptionListOptions{Limit: defaultExportPageSize}, list, func(o AttributeOption) error {
+				return w.write(o, map[string]any{"attribute": code})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// families exports the families and their variants
+func (e *exporter) families(c *Client) error {
+	var codes []string
+	err := exportList(e, ExportFileFamilies, nil, c.Family.ListWithPagination, func(f Family) {
+		codes = append(codes, f.Code)
+	})
+	if err != nil {
+		return err
+	}
+	return e.write(ExportFileFamilyVariants, func(w *jsonlWriter) error {
+		for _, code := range codes {
+			variants, err := allFamilyVariants(e.ctx, c.Family, code)
+			if err != nil {
+				return err
+			}
+			for _, v := range variants {
+				if err := w.write(v, map[string]any{"family": code}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// collectMedia records the media files referenced by values
+func (e *exporter) collectMedia(values map[string][]ProductValue) {
+	for _, code := range productMediaCodes(Product{Values: values}) {
+		e.media[code] = struct{}{}
+	}
+}
+
+// mediaFiles exports the referenced media files and their content
+func (e *exporter) mediaFiles(c *Client) error {
+	codes := make([]string, 0, len(e.media))
+	for code := range e.media {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return e.write(ExportFileMediaFiles, func(w *jsonlWriter) error {
+		for _, code := range codes {
+			if !isLocalCode(code) {
+				return errors.Errorf("invalid media file code %q", code)
+			}
+			media, err := c.MediaFile.GetByCode(code, nil)
+			if err != nil {
+				return err
+			}
+			fp := filepath.Join(e.dir, ExportMediaDir, filepath.FromSlash(code))
+			if err := downloadFile(e.ctx, c.MediaFile, code, fp); err != nil {
+				return err
+			}
+			if err := w.write(media, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// jsonlWriter writes one JSON document per line to a temporary file renamed on close
+type jsonlWriter struct {
+	path  string
+	file  *os.File
+	buf   *bufio.Writer
+	count int
+}
+
+func newJSONLWriter(path string) (*jsonlWriter, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create file, path: %s", path)
+	}
+	return &jsonlWriter{path: path, file: f, buf: bufio.NewWriter(f)}, nil
+}
+
+// write writes v without its "_links", with the extra properties
+func (w *jsonlWriter) write(v any, extra map[string]any) error {
+	doc, err := toDocument(v, extra)
+	if err != nil {
+		return err
+	}
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return errors.Wrap(err, "unable to marshal item")
+	}
+	if _, err := w.buf.Write(line.Bytes()); err != nil {
+		return errors.Wrapf(err, "failed to write file, path: %s", w.path)
+	}
+	w.count++
+	return nil
+}
+
+func (w *jsonlWriter) close() error {
+	defer os.Remove(w.file.Name()) // no-op once renamed
+	if err := w.buf.Flush(); err != nil {
+		_ = w.file.Close()
+		return errors.Wrapf(err, "failed to write file, path: %s", w.path)
+	}
+	if err := w.file.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write file, path: %s", w.path)
+	}
+	if err := os.Rename(w.file.Name(), w.path); err != nil {
+		return errors.Wrapf(err, "failed to rename file, path: %s", w.path)
+	}
+	return nil
+}
+
+func (w *jsonlWriter) abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// toDocument converts v to a JSON document without its "_links", with the extra properties
+func toDocument(v any, extra map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal item")
+	}
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber() // keep the numbers as they were sent by the PIM
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal item")
+	}
+	for k, v := range extra {
+		doc[k] = v
+	}
+	withoutLinks(doc)
+	return doc, nil
+}
+
+// withoutLinks removes the "_links" of a decoded JSON document and of its nested objects
+func withoutLinks(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		delete(t, "_links")
+		for k, e := range t {
+			t[k] = withoutLinks(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = withoutLinks(e)
+		}
+	}
+	return v
+}
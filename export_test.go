@@ -0,0 +1,78 @@
+package goakeneo
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezifyio/go-akeneo/akeneotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Export(t *testing.T) {
+	c, _ := newFakeClient(t, WithRateLimit(1000, time.Second))
+	dir := filepath.Join(t.TempDir(), "export")
+	manifest, err := c.Export(context.Background(), dir, ExportOptions{Media: true})
+	assert.NoError(t, err)
+	assert.Equal(t, exportLayoutVersion, manifest.LayoutVersion)
+	assert.Equal(t, "7.0.0", manifest.PIMVersion)
+	assert.Equal(t, "CE", manifest.PIMEdition)
+	assert.Equal(t, "6.0", manifest.ClientVersion)
+	assert.Equal(t, 12, manifest.Counts[ExportFileProducts])
+	assert.Equal(t, 3, manifest.Counts[ExportFileCurrencies])
+	assert.Equal(t, 2, manifest.Counts[ExportFileMediaFiles])
+	assert.Equal(t, 4, manifest.Counts[ExportFileAttributeGroups])
+	assert.NotZero(t, manifest.Counts[ExportFileAttributeOptions])
+	assert.NotZero(t, manifest.Counts[ExportFileFamilyVariants])
+
+	b, err := os.ReadFile(filepath.Join(dir, ExportFileManifest))
+	assert.NoError(t, err)
+	var written ExportManifest
+	assert.NoError(t, json.Unmarshal(b, &written))
+	assert.Equal(t, manifest.Counts, written.Counts)
+	products, err := os.ReadFile(filepath.Join(dir, ExportFileProducts))
+	assert.NoError(t, err)
+	assert.Equal(t, 12, strings.Count(string(products), "\n"))
+	assert.NotContains(t, string(products), "_links")
+	groups, err := os.ReadFile(filepath.Join(dir, ExportFileAttributeGroups))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(groups), `"attributes"`, "the attributes are not imported yet when the groups are")
+
+	// the export is a fixture directory of the fake PIM
+	srv := akeneotest.NewServer()
+	defer srv.Close()
+	assert.NoError(t, srv.LoadFixtures(dir))
+	con := Connector{ClientID: srv.ClientID, Secret: srv.Secret, UserName: srv.Username, Password: srv.Password}
+	copied, err := con.NewClient(WithBaseURL(srv.URL))
+	assert.NoError(t, err)
+	expected, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	actual, err := copied.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	// the links point to each server, empty lists are omitted by the export
+	expected.Links, actual.Links = Links{}, Links{}
+	expected.Groups = nil
+	assert.Equal(t, expected, actual)
+	variant, err := copied.Family.GetFamilyVariant("shoes", "shoes_by_size")
+	assert.NoError(t, err)
+	assert.Equal(t, "shoes_by_size", variant.Code)
+	var buf strings.Builder
+	assert.NoError(t, copied.MediaFile.DownloadTo(context.Background(), "1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_0______.jpeg", &buf))
+	assert.Equal(t, "\xff\xd8\xff fake shoe image", buf.String())
+}
+
+func TestClient_ExportFilteredProducts(t *testing.T) {
+	c, _ := newFakeClient(t, WithRateLimit(1000, time.Second))
+	sf := make(SearchFilter)
+	sf.Add("family", "IN", []string{"shoes"})
+	manifest, err := c.Export(context.Background(), t.TempDir(), ExportOptions{
+		ProductOptions: ProductListOptions{ListOptions: ListOptions{Search: sf.String(), Limit: 2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, manifest.Counts[ExportFileProducts])
+	assert.NotContains(t, manifest.Counts, ExportFileMediaFiles)
+}
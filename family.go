@@ -1,6 +1,7 @@
 package goakeneo
 
 import (
+	"context"
 	"path"
 )
 
@@ -132,3 +133,22 @@ type FamilyVariantsResponse struct {
 type familyVariantItems struct {
 	Items []FamilyVariant `json:"items,omitempty" mapstructure:"items"`
 }
+
+// allFamilyVariants lists every variant of a family page by page, GetFamilyVariants returns a page
+// without its links so the listing stops at the first page which is not full
+func allFamilyVariants(ctx context.Context, families FamilyService, family string) ([]FamilyVariant, error) {
+	var variants []FamilyVariant
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		items, err := families.GetFamilyVariants(family, FamilyVariantListOptions{Page: page, Limit: defaultExportPageSize})
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, items...)
+		if len(items) < defaultExportPageSize {
+			return variants, nil
+		}
+	}
+}
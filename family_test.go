@@ -1,6 +1,13 @@
 package goakeneo
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestFamilyOp_CreateFamily(t *testing.T) {
 	tests := []struct {
@@ -23,7 +30,7 @@ func TestFamilyOp_CreateFamily(t *testing.T) {
 			wantErr: false,
 		},
 	}
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if err := c.Family.CreateFamily(tt.family); (err != nil) != tt.wantErr {
@@ -32,3 +39,19 @@ func TestFamilyOp_CreateFamily(t *testing.T) {
 		})
 	}
 }
+
+func TestAllFamilyVariants(t *testing.T) {
+	c, srv := newFakeClient(t, WithRateLimit(1000, time.Second))
+	existing, err := c.Family.GetFamilyVariants("shoes", nil)
+	assert.NoError(t, err)
+	for i := 0; i < 2*defaultExportPageSize; i++ {
+		code := fmt.Sprintf("shoes_variant_%03d", i)
+		assert.NoError(t, srv.Seed("families/shoes/variants", FamilyVariant{Code: code, VariantAttributeSets: existing[0].VariantAttributeSets}))
+	}
+	variants, err := allFamilyVariants(context.Background(), c.Family, "shoes")
+	assert.NoError(t, err)
+	assert.Len(t, variants, len(existing)+2*defaultExportPageSize)
+	defs, err := c.LoadCatalogDefinitions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, defs.FamilyVariants["shoes"], len(variants))
+}
@@ -1,8 +1,9 @@
 package goakeneo
 
 import (
+	"bytes"
 	"encoding/json"
-	"html"
+	"strings"
 )
 
 // SearchFilter is a map of search filters,see :
@@ -10,8 +11,12 @@ import (
 type SearchFilter map[string][]map[string]interface{}
 
 func (sf SearchFilter) String() string {
-	t, _ := json.Marshal(sf)
-	return html.UnescapeString(string(t))
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// keep the operators such as < and > readable
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(sf)
+	return strings.TrimSuffix(buf.String(), "\n")
 }
 
 // Add adds a new filter to the search filter
@@ -1,6 +1,7 @@
 package goakeneo
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,3 +14,13 @@ func TestSearchFilter(t *testing.T) {
 	result := sf.String()
 	assert.Equal(t, `{"CREATED":[{"operator":">","value":"2019-01-01T00:00:00+01:00"}],"UPDATED":[{"operator":"<","value":"2019-01-01T00:00:00+01:00"}]}`, result)
 }
+
+func TestSearchFilter_StringIsNotHTMLEscaped(t *testing.T) {
+	sf := make(SearchFilter)
+	sf.Add("name", "CONTAINS", "R&D <beta>")
+	result := sf.String()
+	assert.Equal(t, `{"name":[{"operator":"CONTAINS","value":"R&D <beta>"}]}`, result)
+	var decoded SearchFilter
+	assert.NoError(t, json.Unmarshal([]byte(result), &decoded))
+	assert.Equal(t, "R&D <beta>", decoded["name"][0]["value"])
+}
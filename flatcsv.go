@@ -0,0 +1,818 @@
+package goakeneo
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Flat files, the names are the keys of the ImportReport of ImportProductsCSV and ImportProductModelsCSV
+const (
+	FlatFileProducts      = "products.csv"
+	FlatFileProductModels = "product-models.csv"
+)
+
+// FlatDelimiter is the default field delimiter of the flat files, as in the PIM exports
+const FlatDelimiter = ';'
+
+const (
+	flatListSeparator     = ","
+	flatQuantitySeparator = "|"
+	flatUnitSuffix        = "unit"
+	flatQuantitySuffix    = "quantity"
+)
+
+// flatAssociationKinds are the kinds of associated entities, the second part of an association column
+var flatAssociationKinds = map[string]bool{"products": true, "product_models": true, "groups": true}
+
+// FlatRow is a product or a product model in Akeneo's flat format, cells are keyed by column
+type FlatRow map[string]string
+
+// FlatConverter converts products and product models to and from flat rows.
+// Columns are named as in the PIM: "name-en_US-ecommerce" for a localizable and scopable value,
+// "price-EUR" for the amount of a price, "weight" and "weight-unit" for a metric,
+// "X_SELL-products" for an association and lists such as "categories" are comma separated
+type FlatConverter struct {
+	attributes map[string]Attribute
+	order      map[string]int
+	identifier string
+}
+
+// NewFlatConverter returns a converter naming the columns after the given attribute definitions,
+// the identifier column is the code of the identifier attribute, "sku" when there is none
+func NewFlatConverter(attributes []Attribute) *FlatConverter {
+	sorted := append([]Attribute(nil), attributes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SortOrder < sorted[j].SortOrder
+	})
+	c := &FlatConverter{
+		attributes: make(map[string]Attribute, len(attributes)),
+		order:      make(map[string]int, len(attributes)),
+		identifier: "sku",
+	}
+	for i, a := range sorted {
+		c.attributes[a.Code] = a
+		c.order[a.Code] = i
+		if a.Type == identifierAttributeType {
+			c.identifier = a.Code
+		}
+	}
+	return c
+}
+
+// IdentifierColumn returns the column of the product identifiers
+func (c *FlatConverter) IdentifierColumn() string {
+	return c.identifier
+}
+
+// ProductRow converts a product to a flat row, empty values have no cell
+func (c *FlatConverter) ProductRow(p Product) (FlatRow, error) {
+	row := FlatRow{
+		c.identifier: p.Identifier,
+		"enabled":    flatBool(p.Enabled),
+	}
+	setFlat(row, "family", p.Family)
+	setFlat(row, "parent", p.Parent)
+	setFlat(row, "categories", strings.Join(p.Categories, flatListSeparator))
+	setFlat(row, "groups", strings.Join(p.Groups, flatListSeparator))
+	if err := c.valueCells(row, p.Values); err != nil {
+		return nil, errors.Wrapf(err, "unable to convert product %s", p.Identifier)
+	}
+	associationCells(row, p.Associations, p.QuantifiedAssociations)
+	return row, nil
+}
+
+// ProductModelRow converts a product model to a flat row, empty values have no cell
+func (c *FlatConverter) ProductModelRow(pm ProductModel) (FlatRow, error) {
+	row := FlatRow{"code": pm.Code}
+	setFlat(row, "family", pm.Family)
+	setFlat(row, "family_variant", pm.FamilyVariant)
+	setFlat(row, "parent", pm.Parent)
+	setFlat(row, "categories", strings.Join(pm.Categories, flatListSeparator))
+	if err := c.valueCells(row, pm.Values); err != nil {
+		return nil, errors.Wrapf(err, "unable to convert product model %s", pm.Code)
+	}
+	associationCells(row, pm.Associations, pm.QuantifiedAssociations)
+	return row, nil
+}
+
+// ProductFromRow converts a flat row to a product. Empty cells are skipped, they do not clear values,
+// an error is returned for a column which is neither a property, a value of a known attribute nor an association
+func (c *FlatConverter) ProductFromRow(row FlatRow) (Product, error) {
+	p := Product{
+		Identifier: row[c.identifier],
+		Family:     row["family"],
+		Parent:     row["parent"],
+		Categories: splitFlat(row["categories"]),
+		Groups:     splitFlat(row["groups"]),
+	}
+	if enabled := row["enabled"]; enabled != "" {
+		b, err := parseFlatBool(enabled)
+		if err != nil {
+			return Product{}, errors.Wrap(err, "invalid enabled")
+		}
+		p.Enabled = b
+	}
+	properties := map[string]bool{c.identifier: true, "enabled": true, "family": true, "parent": true, "categories": true, "groups": true}
+	values, associations, quantified, err := c.fromCells(row, properties)
+	if err != nil {
+		return Product{}, errors.Wrapf(err, "unable to convert product %s", p.Identifier)
+	}
+	p.Values, p.Associations, p.QuantifiedAssociations = values, associations, quantified
+	if p.Identifier != "" {
+		if p.Values == nil {
+			p.Values = make(map[string][]ProductValue)
+		}
+		p.Values[c.identifier] = []ProductValue{{Data: p.Identifier}}
+	}
+	return p, nil
+}
+
+// ProductModelFromRow converts a flat row to a product model, see ProductFromRow
+func (c *FlatConverter) ProductModelFromRow(row FlatRow) (ProductModel, error) {
+	pm := ProductModel{
+		Code:          row["code"],
+		Family:        row["family"],
+		FamilyVariant: row["family_variant"],
+		Parent:        row["parent"],
+		Categories:    splitFlat(row["categories"]),
+	}
+	properties := map[string]bool{"code": true, "family": true, "family_variant": true, "parent": true, "categories": true}
+	values, associations, quantified, err := c.fromCells(row, properties)
+	if err != nil {
+		return ProductModel{}, errors.Wrapf(err, "unable to convert product model %s", pm.Code)
+	}
+	pm.Values, pm.Associations, pm.QuantifiedAssociations = values, associations, quantified
+	return pm, nil
+}
+
+// SortColumns sorts columns as the PIM does: the properties first,
+// then the values in the order of the attributes and the associations last
+func (c *FlatConverter) SortColumns(columns []string) {
+	properties := map[string]int{c.identifier: 0, "code": 0, "categories": 1, "enabled": 2, "family": 3, "family_variant": 4, "parent": 5, "groups": 6}
+	rank := func(column string) (int, int) {
+		if i, ok := properties[column]; ok {
+			return 0, i
+		}
+		code, _, _ := strings.Cut(column, "-")
+		if i, ok := c.order[code]; ok {
+			return 1, i
+		}
+		return 2, 0
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		gi, oi := rank(columns[i])
+		gj, oj := rank(columns[j])
+		if gi != gj {
+			return gi < gj
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return columns[i] < columns[j]
+	})
+}
+
+func (c *FlatConverter) valueCells(row FlatRow, values map[string][]ProductValue) error {
+	for code, list := range values {
+		a, ok := c.attributes[code]
+		if !ok {
+			return errors.Errorf("unknown attribute %s", code)
+		}
+		if a.Type == identifierAttributeType {
+			continue
+		}
+		for _, v := range list {
+			if v.Data == nil {
+				continue
+			}
+			column := flatColumn(code, v.Locale, v.Scope)
+			if err := flatCells(row, a, column, v.Data); err != nil {
+				return errors.Wrapf(err, "invalid %s", column)
+			}
+		}
+	}
+	return nil
+}
+
+// flatCells writes the cells of one value
+func flatCells(row FlatRow, a Attribute, column string, data any) error {
+	switch a.Type {
+	case "pim_catalog_boolean":
+		b, err := DecodeBool(data)
+		if err != nil {
+			return err
+		}
+		row[column] = flatBool(b)
+	case "pim_catalog_number":
+		n, err := DecodeDecimal(data)
+		if err != nil {
+			return err
+		}
+		row[column] = string(n)
+	case "pim_catalog_metric":
+		m, err := DecodeMetric(data)
+		if err != nil {
+			return err
+		}
+		row[column] = string(m.Amount)
+		row[column+"-"+flatUnitSuffix] = m.Unit
+	case "pim_catalog_price_collection":
+		prices, err := DecodePrices(data)
+		if err != nil {
+			return err
+		}
+		for _, price := range prices {
+			row[column+"-"+price.Currency] = string(price.Amount)
+		}
+	case "pim_catalog_multiselect", "pim_catalog_asset_collection", "pim_reference_data_multiselect", "akeneo_reference_entity_collection":
+		list, err := DecodeTexts(data)
+		if err != nil {
+			return err
+		}
+		row[column] = strings.Join(list, flatListSeparator)
+	case "pim_catalog_table":
+		b, err := json.Marshal(data)
+		if err != nil {
+			return errors.Wrap(err, "unable to marshal table")
+		}
+		row[column] = string(b)
+	default:
+		s, err := DecodeText(data)
+		if err != nil {
+			return err
+		}
+		row[column] = s
+	}
+	return nil
+}
+
+// associationCells writes the association columns, the quantities of a quantified association
+// are in a "-quantity" column separated by "|" in the order of the associated entities
+func associationCells(row FlatRow, associations map[string]Association, quantified map[string]QuantifiedAssociation) {
+	for code, a := range associations {
+		setFlat(row, code+"-groups", strings.Join(a.Groups, flatListSeparator))
+		setFlat(row, code+"-products", strings.Join(a.Products, flatListSeparator))
+		setFlat(row, code+"-product_models", strings.Join(a.ProductModels, flatListSeparator))
+	}
+	for code, q := range quantified {
+		if len(q.Products) > 0 {
+			ids, quantities := make([]string, len(q.Products)), make([]string, len(q.Products))
+			for i, p := range q.Products {
+				ids[i], quantities[i] = p.Identifier, strconv.Itoa(p.Quantity)
+			}
+			row[code+"-products"] = strings.Join(ids, flatListSeparator)
+			row[code+"-products-"+flatQuantitySuffix] = strings.Join(quantities, flatQuantitySeparator)
+		}
+		if len(q.ProductModels) > 0 {
+			codes, quantities := make([]string, len(q.ProductModels)), make([]string, len(q.ProductModels))
+			for i, pm := range q.ProductModels {
+				codes[i], quantities[i] = pm.Code, strconv.Itoa(pm.Quantity)
+			}
+			row[code+"-product_models"] = strings.Join(codes, flatListSeparator)
+			row[code+"-product_models-"+flatQuantitySuffix] = strings.Join(quantities, flatQuantitySeparator)
+		}
+	}
+}
+
+// flatValueKey identifies a value while the cells of a row are read
+type flatValueKey struct {
+	attribute, locale, scope string
+}
+
+func (c *FlatConverter) fromCells(row FlatRow, properties map[string]bool) (map[string][]ProductValue, map[string]Association, map[string]QuantifiedAssociation, error) {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns) // the values are built in the same order whatever the order of the columns
+	data := make(map[flatValueKey]any)
+	keys := make([]flatValueKey, 0)
+	units := make(map[flatValueKey]string)
+	associations := make(map[string]Association)
+	quantified := make(map[string]QuantifiedAssociation)
+	for _, column := range columns {
+		cell := row[column]
+		if properties[column] || cell == "" {
+			continue
+		}
+		parts := strings.Split(column, "-")
+		a, ok := c.attributes[parts[0]]
+		if !ok {
+			if len(parts) < 2 || !flatAssociationKinds[parts[1]] || len(parts) > 3 || len(parts) == 3 && parts[2] != flatQuantitySuffix {
+				return nil, nil, nil, errors.Errorf("unknown column %s", column)
+			}
+			if len(parts) == 3 {
+				continue // the quantities are read with their association
+			}
+			if err := associationFromCells(row, parts[0], parts[1], associations, quantified); err != nil {
+				return nil, nil, nil, errors.Wrapf(err, "invalid %s", column)
+			}
+			continue
+		}
+		if a.Type == identifierAttributeType {
+			continue
+		}
+		rest := parts[1:]
+		var unit, currency bool
+		switch a.Type {
+		case "pim_catalog_metric":
+			unit = len(rest) > 0 && rest[len(rest)-1] == flatUnitSuffix
+			if unit {
+				rest = rest[:len(rest)-1]
+			}
+		case "pim_catalog_price_collection":
+			if len(rest) == 0 {
+				return nil, nil, nil, errors.Errorf("missing currency in column %s", column)
+			}
+			currency = true
+		}
+		key := flatValueKey{attribute: a.Code}
+		want := 0
+		if a.Localizable {
+			want++
+		}
+		if a.Scopable {
+			want++
+		}
+		if currency {
+			want++
+		}
+		if len(rest) != want {
+			return nil, nil, nil, errors.Errorf("unknown column %s", column)
+		}
+		if a.Localizable {
+			key.locale, rest = rest[0], rest[1:]
+		}
+		if a.Scopable {
+			key.scope, rest = rest[0], rest[1:]
+		}
+		if unit {
+			units[key] = cell
+			continue
+		}
+		if _, ok := data[key]; !ok {
+			keys = append(keys, key)
+		}
+		switch {
+		case currency:
+			prices, _ := data[key].([]Price)
+			data[key] = append(prices, Price{Amount: Decimal(cell), Currency: rest[0]})
+		default:
+			v, err := parseFlatCell(a, cell)
+			if err != nil {
+				return nil, nil, nil, errors.Wrapf(err, "invalid %s", column)
+			}
+			data[key] = v
+		}
+	}
+	var values map[string][]ProductValue
+	for _, key := range keys {
+		v := data[key]
+		if m, ok := v.(Metric); ok {
+			m.Unit = units[key]
+			if m.Unit == "" {
+				m.Unit = c.attributes[key.attribute].DefaultMetricUnit
+			}
+			v = m
+		}
+		if values == nil {
+			values = make(map[string][]ProductValue)
+		}
+		values[key.attribute] = append(values[key.attribute], ProductValue{Locale: key.locale, Scope: key.scope, Data: v})
+	}
+	if len(associations) == 0 {
+		associations = nil
+	}
+	if len(quantified) == 0 {
+		quantified = nil
+	}
+	return values, associations, quantified, nil
+}
+
+// associationFromCells reads an association column, which is quantified when it has a "-quantity" column
+func associationFromCells(row FlatRow, code, kind string, associations map[string]Association, quantified map[string]QuantifiedAssociation) error {
+	quantities, isQuantified := row[code+"-"+kind+"-"+flatQuantitySuffix]
+	if !isQuantified {
+		a := associations[code]
+		list := splitFlat(row[code+"-"+kind])
+		switch kind {
+		case "products":
+			a.Products = list
+		case "product_models":
+			a.ProductModels = list
+		default:
+			a.Groups = list
+		}
+		associations[code] = a
+		return nil
+	}
+	ids := splitFlat(row[code+"-"+kind])
+	counts := strings.Split(quantities, flatQuantitySeparator)
+	if len(counts) != len(ids) {
+		return errors.Errorf("%d quantities for %d associated entities", len(counts), len(ids))
+	}
+	q := quantified[code]
+	for i, id := range ids {
+		n, err := strconv.Atoi(strings.TrimSpace(counts[i]))
+		if err != nil {
+			return errors.Wrapf(err, "invalid quantity %q", counts[i])
+		}
+		switch kind {
+		case "products":
+			q.Products = append(q.Products, ProductQuantity{Identifier: id, Quantity: n})
+		case "product_models":
+			q.ProductModels = append(q.ProductModels, ProductModelQuantity{Code: id, Quantity: n})
+		default:
+			return errors.New("groups can not be quantified")
+		}
+	}
+	quantified[code] = q
+	return nil
+}
+
+// parseFlatCell converts the cell of a value to the data expected by the API
+func parseFlatCell(a Attribute, cell string) (any, error) {
+	switch a.Type {
+	case "pim_catalog_boolean":
+		return parseFlatBool(cell)
+	case "pim_catalog_number":
+		if a.DecimalsAllowed {
+			return cell, nil
+		}
+		n, err := strconv.Atoi(cell)
+		return n, errors.Wrapf(err, "invalid integer %q", cell)
+	case "pim_catalog_metric":
+		return Metric{Amount: Decimal(cell)}, nil
+	case "pim_catalog_multiselect", "pim_catalog_asset_collection", "pim_reference_data_multiselect", "akeneo_reference_entity_collection":
+		return splitFlat(cell), nil
+	case "pim_catalog_table":
+		var table any
+		err := json.Unmarshal([]byte(cell), &table)
+		return table, errors.Wrap(err, "invalid table")
+	default:
+		return cell, nil
+	}
+}
+
+// flatColumn returns the column of a value without its unit or currency suffix
+func flatColumn(code, locale, scope string) string {
+	column := code
+	if locale != "" {
+		column += "-" + locale
+	}
+	if scope != "" {
+		column += "-" + scope
+	}
+	return column
+}
+
+func setFlat(row FlatRow, column, cell string) {
+	if cell != "" {
+		row[column] = cell
+	}
+}
+
+func splitFlat(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+	list := strings.Split(cell, flatListSeparator)
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
+
+func flatBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func parseFlatBool(cell string) (bool, error) {
+	switch strings.ToLower(cell) {
+	case "1", "true", "yes":
+		return true, nil
+	case "0", "false", "no":
+		return false, nil
+	}
+	return false, errors.Errorf("invalid boolean %q", cell)
+}
+
+// FlatWriter writes flat rows as CSV, the header is written with the first row
+type FlatWriter struct {
+	Comma   rune // Comma is the field delimiter, FlatDelimiter by default, it must be set before the first Write
+	w       *csv.Writer
+	columns []string
+	index   map[string]int
+	started bool
+}
+
+// NewFlatWriter returns a writer of the given columns, see FlatConverter.SortColumns
+func NewFlatWriter(w io.Writer, columns []string) *FlatWriter {
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		index[column] = i
+	}
+	return &FlatWriter{Comma: FlatDelimiter, w: csv.NewWriter(w), columns: columns, index: index}
+}
+
+// Write writes a row, an error is returned when a non-empty cell has no column
+func (fw *FlatWriter) Write(row FlatRow) error {
+	if err := fw.header(); err != nil {
+		return err
+	}
+	record := make([]string, len(fw.columns))
+	for column, cell := range row {
+		i, ok := fw.index[column]
+		if !ok {
+			if cell == "" {
+				continue
+			}
+			return errors.Errorf("no column %s in the header", column)
+		}
+		record[i] = cell
+	}
+	return errors.Wrap(fw.w.Write(record), "unable to write row")
+}
+
+// Flush writes the buffered rows, and the header when no row was written
+func (fw *FlatWriter) Flush() error {
+	if err := fw.header(); err != nil {
+		return err
+	}
+	fw.w.Flush()
+	return errors.Wrap(fw.w.Error(), "unable to flush rows")
+}
+
+func (fw *FlatWriter) header() error {
+	if fw.started {
+		return nil
+	}
+	fw.started = true
+	fw.w.Comma = fw.Comma
+	return errors.Wrap(fw.w.Write(fw.columns), "unable to write header")
+}
+
+// FlatReader reads flat rows from CSV, the first record is the header
+type FlatReader struct {
+	Comma   rune // Comma is the field delimiter, FlatDelimiter by default, it must be set before the first Read
+	r       *csv.Reader
+	columns []string
+}
+
+// NewFlatReader returns a reader of the CSV in r
+func NewFlatReader(r io.Reader) *FlatReader {
+	return &FlatReader{Comma: FlatDelimiter, r: csv.NewReader(r)}
+}
+
+// Columns returns the header
+func (fr *FlatReader) Columns() ([]string, error) {
+	if fr.columns != nil {
+		return fr.columns, nil
+	}
+	fr.r.Comma = fr.Comma
+	header, err := fr.r.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read header")
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // spreadsheets write a byte order mark
+	}
+	fr.columns = header
+	return header, nil
+}
+
+// Read returns the next row and its line, io.EOF is returned after the last row
+func (fr *FlatReader) Read() (FlatRow, int, error) {
+	columns, err := fr.Columns()
+	if err != nil {
+		return nil, 0, err
+	}
+	record, err := fr.r.Read()
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "unable to read row")
+	}
+	line, _ := fr.r.FieldPos(0)
+	row := make(FlatRow, len(columns))
+	for i, column := range columns {
+		if i < len(record) {
+			row[column] = record[i]
+		}
+	}
+	return row, line, nil
+}
+
+// ExportProductsCSV writes the products listed with options, such as ProductListOptions, to w in the flat format.
+// The rows are spooled to a temporary file so that the header only has the columns of the exported values
+func (c *Client) ExportProductsCSV(ctx context.Context, w io.Writer, options any) (int, error) {
+	converter, err := c.flatConverter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	values, err := searchAfterOptions(options)
+	if err != nil {
+		return 0, err
+	}
+	return exportFlat(ctx, w, converter, values, c.Product.ListWithPagination, converter.ProductRow)
+}
+
+// ExportProductModelsCSV writes the product models listed with options to w in the flat format, see ExportProductsCSV
+func (c *Client) ExportProductModelsCSV(ctx context.Context, w io.Writer, options any) (int, error) {
+	converter, err := c.flatConverter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	values, err := searchAfterOptions(options)
+	if err != nil {
+		return 0, err
+	}
+	return exportFlat(ctx, w, converter, values, c.ProductModel.ListWithPagination, converter.ProductModelRow)
+}
+
+func (c *Client) flatConverter(ctx context.Context) (*FlatConverter, error) {
+	attributes, err := c.Catalog.Attributes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load attributes")
+	}
+	return NewFlatConverter(attributes), nil
+}
+
+func exportFlat[T any](ctx context.Context, w io.Writer, converter *FlatConverter, options any, list func(options any) ([]T, Links, error), toRow func(T) (FlatRow, error)) (int, error) {
+	spool, err := os.CreateTemp("", "akeneo-flat-*.jsonl")
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to create spool file")
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	buf := bufio.NewWriter(spool)
+	encoder := json.NewEncoder(buf)
+	seen := make(map[string]bool)
+	columns := make([]string, 0)
+	count := 0
+	err = listAll(ctx, options, list, func(item T) error {
+		row, err := toRow(item)
+		if err != nil {
+			return err
+		}
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+		count++
+		return encoder.Encode(row)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to export rows")
+	}
+	if err := buf.Flush(); err != nil {
+		return 0, errors.Wrap(err, "unable to write spool file")
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return 0, errors.Wrap(err, "unable to read spool file")
+	}
+	converter.SortColumns(columns)
+	fw := NewFlatWriter(w, columns)
+	decoder := json.NewDecoder(bufio.NewReader(spool))
+	for i := 0; i < count; i++ {
+		var row FlatRow
+		if err := decoder.Decode(&row); err != nil {
+			return 0, errors.Wrap(err, "unable to read spool file")
+		}
+		if err := fw.Write(row); err != nil {
+			return 0, err
+		}
+	}
+	return count, fw.Flush()
+}
+
+// FlatImportOptions are the options of ImportProductsCSV and ImportProductModelsCSV
+type FlatImportOptions struct {
+	BatchSize int  // BatchSize is the number of items per PATCH request, 100 at most and by default
+	Comma     rune // Comma is the field delimiter, FlatDelimiter by default
+}
+
+// ImportProductsCSV creates or updates the products of a flat file read from r.
+// A row which can not be converted or is rejected by the PIM is reported with its line and does not stop the import
+func (c *Client) ImportProductsCSV(ctx context.Context, r io.Reader, opts FlatImportOptions) (*ImportReport, error) {
+	converter, err := c.flatConverter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.importFlat(ctx, r, opts, FlatFileProducts, productBasePath, converter.IdentifierColumn(), func(row FlatRow) (map[string]any, error) {
+		p, err := converter.ProductFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := toDocument(p, nil)
+		if err != nil {
+			return nil, err
+		}
+		if row["enabled"] != "" {
+			doc["enabled"] = p.Enabled // false is omitted by the json tags
+		}
+		return doc, nil
+	})
+}
+
+// ImportProductModelsCSV creates or updates the product models of a flat file read from r, see ImportProductsCSV.
+// Root product models must come before their sub product models
+func (c *Client) ImportProductModelsCSV(ctx context.Context, r io.Reader, opts FlatImportOptions) (*ImportReport, error) {
+	converter, err := c.flatConverter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.importFlat(ctx, r, opts, FlatFileProductModels, productModelBasePath, "code", func(row FlatRow) (map[string]any, error) {
+		pm, err := converter.ProductModelFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		return toDocument(pm, nil)
+	})
+}
+
+func (c *Client) importFlat(ctx context.Context, r io.Reader, opts FlatImportOptions, name, relPath, codeColumn string, toDoc func(FlatRow) (map[string]any, error)) (*ImportReport, error) {
+	if opts.BatchSize <= 0 || opts.BatchSize > MaxPatchBatchSize {
+		opts.BatchSize = MaxPatchBatchSize
+	}
+	report := &ImportReport{Files: map[string]*ImportFileResult{name: {}}}
+	fr := NewFlatReader(r)
+	if opts.Comma != 0 {
+		fr.Comma = opts.Comma
+	}
+	var docs []map[string]any
+	var lines []int
+	flush := func() error {
+		if len(docs) == 0 {
+			return nil
+		}
+		responses, err := c.patchCollection(relPath, docs)
+		if err != nil {
+			return errors.Wrapf(err, "unable to import %s", name)
+		}
+		result := report.file(name)
+		for _, response := range responses {
+			switch response.StatusCode {
+			case http.StatusCreated:
+				result.Created++
+			case http.StatusNoContent:
+				result.Updated++
+			default:
+				line := 0
+				if response.Line >= 1 && response.Line <= len(lines) {
+					line = lines[response.Line-1]
+				}
+				code := response.Code
+				if code == "" {
+					code = response.Identifier
+				}
+				report.fail(ImportFailure{File: name, Line: line, Code: code, StatusCode: response.StatusCode, Message: response.Message})
+			}
+		}
+		docs, lines = docs[:0], lines[:0]
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		row, line, err := fr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, errors.Wrapf(err, "unable to read %s", name)
+		}
+		doc, err := toDoc(row)
+		if err != nil {
+			report.fail(ImportFailure{File: name, Line: line, Code: row[codeColumn], Message: err.Error()})
+			continue
+		}
+		docs, lines = append(docs, doc), append(lines, line)
+		if len(docs) == opts.BatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, flush()
+}
@@ -0,0 +1,188 @@
+package goakeneo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func flatTestConverter() *FlatConverter {
+	return NewFlatConverter([]Attribute{
+		{Code: "sku", Type: identifierAttributeType},
+		{Code: "name", Type: "pim_catalog_text", Localizable: true, SortOrder: 1},
+		{Code: "description", Type: "pim_catalog_textarea", Localizable: true, Scopable: true, SortOrder: 2},
+		{Code: "tags", Type: "pim_catalog_multiselect", SortOrder: 3},
+		{Code: "weight", Type: "pim_catalog_metric", DecimalsAllowed: true, DefaultMetricUnit: "KILOGRAM", SortOrder: 4},
+		{Code: "price", Type: "pim_catalog_price_collection", Scopable: true, DecimalsAllowed: true, SortOrder: 5},
+		{Code: "stock", Type: "pim_catalog_number", SortOrder: 6},
+		{Code: "waterproof", Type: "pim_catalog_boolean", SortOrder: 7},
+	})
+}
+
+func TestFlatConverter_Product(t *testing.T) {
+	c := flatTestConverter()
+	p := Product{
+		Identifier: "runner-40",
+		Family:     "shoes",
+		Categories: []string{"master_shoes", "sale"},
+		Values: map[string][]ProductValue{
+			"sku":         {{Data: "runner-40"}},
+			"name":        {{Locale: "en_US", Data: "Runner"}, {Locale: "fr_FR", Data: "Coureur"}},
+			"description": {{Locale: "en_US", Scope: "ecommerce", Data: "A running shoe"}},
+			"tags":        {{Data: []any{"sport", "trail"}}},
+			"weight":      {{Data: map[string]any{"amount": "0.8500", "unit": "KILOGRAM"}}},
+			"price":       {{Scope: "ecommerce", Data: []any{map[string]any{"amount": "89.00", "currency": "EUR"}, map[string]any{"amount": "99.00", "currency": "USD"}}}},
+			"stock":       {{Data: json.Number("12")}},
+			"waterproof":  {{Data: false}},
+		},
+		Associations:           map[string]Association{"X_SELL": {Products: []string{"runner-41", "runner-42"}}},
+		QuantifiedAssociations: map[string]QuantifiedAssociation{"PACK": {Products: []ProductQuantity{{Identifier: "laces", Quantity: 2}}}},
+	}
+	row, err := c.ProductRow(p)
+	assert.NoError(t, err)
+	assert.Equal(t, FlatRow{
+		"sku":                         "runner-40",
+		"enabled":                     "0",
+		"family":                      "shoes",
+		"categories":                  "master_shoes,sale",
+		"name-en_US":                  "Runner",
+		"name-fr_FR":                  "Coureur",
+		"description-en_US-ecommerce": "A running shoe",
+		"tags":                        "sport,trail",
+		"weight":                      "0.8500",
+		"weight-unit":                 "KILOGRAM",
+		"price-ecommerce-EUR":         "89.00",
+		"price-ecommerce-USD":         "99.00",
+		"stock":                       "12",
+		"waterproof":                  "0",
+		"X_SELL-products":             "runner-41,runner-42",
+		"PACK-products":               "laces",
+		"PACK-products-quantity":      "2",
+	}, row)
+
+	back, err := c.ProductFromRow(row)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master_shoes", "sale"}, back.Categories)
+	assert.Equal(t, []ProductValue{{Data: "runner-40"}}, back.Values["sku"])
+	assert.Equal(t, []ProductValue{{Locale: "en_US", Data: "Runner"}, {Locale: "fr_FR", Data: "Coureur"}}, back.Values["name"])
+	assert.Equal(t, []ProductValue{{Data: []string{"sport", "trail"}}}, back.Values["tags"])
+	assert.Equal(t, []ProductValue{{Data: Metric{Amount: "0.8500", Unit: "KILOGRAM"}}}, back.Values["weight"])
+	assert.Equal(t, []ProductValue{{Scope: "ecommerce", Data: []Price{{Amount: "89.00", Currency: "EUR"}, {Amount: "99.00", Currency: "USD"}}}}, back.Values["price"])
+	assert.Equal(t, []ProductValue{{Data: 12}}, back.Values["stock"])
+	assert.Equal(t, []ProductValue{{Data: false}}, back.Values["waterproof"])
+	assert.Equal(t, p.Associations, back.Associations)
+	assert.Equal(t, p.QuantifiedAssociations, back.QuantifiedAssociations)
+
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	c.SortColumns(columns)
+	assert.Equal(t, []string{"sku", "categories", "enabled", "family", "name-en_US", "name-fr_FR", "description-en_US-ecommerce",
+		"tags", "weight", "weight-unit", "price-ecommerce-EUR", "price-ecommerce-USD", "stock", "waterproof",
+		"PACK-products", "PACK-products-quantity", "X_SELL-products"}, columns)
+}
+
+func TestFlatConverter_FromRowErrors(t *testing.T) {
+	c := flatTestConverter()
+	// the unit of a metric defaults to the unit of the attribute
+	p, err := c.ProductFromRow(FlatRow{"sku": "a", "weight": "1.5", "name-en_US": ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []ProductValue{{Data: Metric{Amount: "1.5", Unit: "KILOGRAM"}}}, p.Values["weight"])
+	assert.NotContains(t, p.Values, "name")
+
+	for row, message := range map[string]FlatRow{
+		"unknown column color":      {"sku": "a", "color": "red"},
+		"unknown column name":       {"sku": "a", "name": "Runner"},
+		"missing currency":          {"sku": "a", "price": "10"},
+		"invalid integer":           {"sku": "a", "stock": "1.5"},
+		"invalid boolean":           {"sku": "a", "waterproof": "maybe"},
+		"2 quantities for 1":        {"sku": "a", "PACK-products": "laces", "PACK-products-quantity": "1|2"},
+		"unknown column X_SELL-foo": {"sku": "a", "X_SELL-foo": "b"},
+	} {
+		_, err := c.ProductFromRow(message)
+		if assert.Error(t, err, row) {
+			assert.Contains(t, err.Error(), row)
+		}
+	}
+}
+
+func TestFlatWriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	fw := NewFlatWriter(&buf, []string{"sku", "name-en_US", "categories"})
+	assert.NoError(t, fw.Write(FlatRow{"sku": "a", "name-en_US": "Shoe; red", "categories": "x,y"}))
+	assert.NoError(t, fw.Write(FlatRow{"sku": "b", "description": ""}))
+	assert.Error(t, fw.Write(FlatRow{"sku": "c", "description": "text"}))
+	assert.NoError(t, fw.Flush())
+	assert.Equal(t, "sku;name-en_US;categories\na;\"Shoe; red\";x,y\nb;;\n", buf.String())
+
+	fr := NewFlatReader(strings.NewReader("\ufeff" + buf.String()))
+	columns, err := fr.Columns()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sku", "name-en_US", "categories"}, columns)
+	row, line, err := fr.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, FlatRow{"sku": "a", "name-en_US": "Shoe; red", "categories": "x,y"}, row)
+	_, line, err = fr.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, line)
+	_, _, err = fr.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestClient_ProductsCSV(t *testing.T) {
+	c, _ := newFakeClient(t, WithRateLimit(1000, time.Second))
+	ctx := context.Background()
+	var buf bytes.Buffer
+	n, err := c.ExportProductsCSV(ctx, &buf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+	header, _, _ := strings.Cut(buf.String(), "\n")
+	assert.True(t, strings.HasPrefix(header, "sku;categories;enabled;family;parent;"), header)
+	assert.True(t, strings.HasSuffix(header, ";weight;weight-unit"), header)
+
+	columns := strings.Split(header, ";")
+	line := func(row FlatRow) string {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		return strings.Join(record, ";") + "\n"
+	}
+	csv := strings.Replace(buf.String(), ";City shoe;", ";City sneaker;", 1)
+	csv += line(FlatRow{"sku": "new-shoe", "categories": "master_shoes", "enabled": "1", "family": "shoes", "name-en_US": "New shoe"})
+	csv += line(FlatRow{"sku": "bad-shoe", "enabled": "maybe", "family": "shoes"})
+	report, err := c.ImportProductsCSV(ctx, strings.NewReader(csv), FlatImportOptions{BatchSize: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, &ImportFileResult{Created: 1, Updated: 12, Failed: 1}, report.Files[FlatFileProducts])
+	if assert.Len(t, report.Failures, 1) {
+		assert.Equal(t, 15, report.Failures[0].Line)
+		assert.Equal(t, "bad-shoe", report.Failures[0].Code)
+	}
+
+	p, err := c.Product.GetProduct("code-9200-eprcg", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "City sneaker", p.Values["name"][0].Data)
+	p, err = c.Product.GetProduct("new-shoe", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "New shoe", p.Values["name"][0].Data)
+	assert.True(t, p.Enabled)
+
+	buf.Reset()
+	n, err = c.ExportProductModelsCSV(ctx, &buf, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "code;categories;family;family_variant;color;description-en_US-ecommerce;name-en_US\n"+
+		"runner;master_shoes_sneakers;shoes;shoes_by_size;red;A running shoe;Runner\n", buf.String())
+	report, err = c.ImportProductModelsCSV(ctx, &buf, FlatImportOptions{})
+	assert.NoError(t, err)
+	assert.False(t, report.HasFailures())
+	assert.Equal(t, 1, report.Files[FlatFileProductModels].Updated)
+}
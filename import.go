@@ -0,0 +1,574 @@
+package goakeneo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// MaxPatchBatchSize is the maximum number of items of a PATCH request, the PIM rejects bigger collections
+const MaxPatchBatchSize = 100
+
+// readOnlyProperties are removed from the products and the product models before they are imported
+var readOnlyProperties = []string{"created", "updated", "metadata", "quality_scores", "completenesses"}
+
+// ImportOptions are the options of Client.Import
+type ImportOptions struct {
+	BatchSize int  // BatchSize is the number of items per PATCH request, 100 at most and by default
+	SkipMedia bool // SkipMedia imports the media values as exported instead of uploading the media files again
+}
+
+// ImportReport is the result of an import, an item rejected by the PIM does not stop the import
+type ImportReport struct {
+	Files    map[string]*ImportFileResult `json:"files"`
+	Failures []ImportFailure              `json:"failures"`
+}
+
+// ImportFileResult counts the imported items of one file
+type ImportFileResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// ImportFailure is an item which could not be imported
+type ImportFailure struct {
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"` // Line is the line of the item in the file
+	Code       string `json:"code"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Message    string `json:"message"`
+}
+
+// HasFailures reports whether an item could not be imported
+func (r *ImportReport) HasFailures() bool {
+	return len(r.Failures) > 0
+}
+
+func (r *ImportReport) file(name string) *ImportFileResult {
+	result, ok := r.Files[name]
+	if !ok {
+		result = &ImportFileResult{}
+		r.Files[name] = result
+	}
+	return result
+}
+
+func (r *ImportReport) fail(f ImportFailure) {
+	r.file(f.File).Failed++
+	r.Failures = append(r.Failures, f)
+}
+
+// Import pushes an export of Client.Export to the PIM in dependency order: categories, channels, attribute groups,
+// attributes and their options, families and their variants, root then sub product models and products without
+// their associations, which are sent once every product model and product exists, and finally the media files,
+// which are uploaded again and linked to their values.
+// Locales and currencies can not be written through the API, they are only checked to be enabled.
+// Missing files are skipped, an error is returned when the snapshot can not be read or a request fails
+func (c *Client) Import(ctx context.Context, dir string, opts ImportOptions) (*ImportReport, error) {
+	if err := checkManifest(dir); err != nil {
+		return nil, err
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > MaxPatchBatchSize {
+		opts.BatchSize = MaxPatchBatchSize
+	}
+	im := &importer{
+		ctx:    ctx,
+		client: c,
+		dir:    dir,
+		opts:   opts,
+		report: &ImportReport{Files: make(map[string]*ImportFileResult)},
+		media:  make(map[string]MediaFile),
+	}
+	if !opts.SkipMedia {
+		if err := im.loadMediaFiles(); err != nil {
+			return nil, err
+		}
+	}
+	steps := []func() error{
+		func() error {
+			return verifyEnabled(im, ExportFileLocales, c.Locale.ListWithPagination, func(l Locale) (string, bool) { return l.Code, l.Enabled })
+		},
+		func() error {
+			return verifyEnabled(im, ExportFileCurrencies, c.Currency.ListWithPagination, func(cu Currency) (string, bool) { return cu.Code, cu.Enabled })
+		},
+		// the category tree of a channel must exist
+		im.categories,
+		func() error { return im.patchFile(ExportFileChannels, channelBasePath, nil) },
+		func() error { return im.patchFile(ExportFileAttributeGroups, attributeGroupBasePath, nil) },
+		func() error { return im.patchFile(ExportFileAttributes, attributeBasePath, nil) },
+		func() error {
+			return im.patchNested(ExportFileAttributeOptions, "attribute", attributeBasePath, "options", false)
+		},
+		func() error { return im.patchFile(ExportFileFamilies, familyBasePath, nil) },
+		func() error {
+			return im.patchNested(ExportFileFamilyVariants, "family", familyBasePath, "variants", true)
+		},
+		func() error {
+			return im.patchFile(ExportFileProductModels, productModelBasePath, func(doc map[string]any) bool {
+				return doc["parent"] == nil || doc["parent"] == ""
+			})
+		},
+		func() error {
+			return im.patchFile(ExportFileProductModels, productModelBasePath, func(doc map[string]any) bool {
+				return doc["parent"] != nil && doc["parent"] != ""
+			})
+		},
+		func() error { return im.patchFile(ExportFileProducts, productBasePath, nil) },
+		im.associate,
+		im.relinkMedia,
+	}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return im.report, err
+		}
+		if err := step(); err != nil {
+			return im.report, err
+		}
+	}
+	return im.report, nil
+}
+
+// checkManifest verifies the export was written with the current layout
+func checkManifest(dir string) error {
+	b, err := os.ReadFile(filepath.Join(dir, ExportFileManifest))
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the manifest of %s", dir)
+	}
+	var manifest ExportManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return errors.Wrapf(err, "invalid manifest in %s", dir)
+	}
+	if manifest.LayoutVersion != exportLayoutVersion {
+		return errors.Errorf("unsupported export layout version %d", manifest.LayoutVersion)
+	}
+	return nil
+}
+
+// importer holds the state of one import
+type importer struct {
+	ctx     context.Context
+	client  *Client
+	dir     string
+	opts    ImportOptions
+	report  *ImportReport
+	media   map[string]MediaFile // media are the exported media files by code
+	relinks []mediaRelink
+	// associations are the associations removed from the imported items, sent once their targets exist
+	associations []associationPatch
+}
+
+// snapshotItem is one line of an export file
+type snapshotItem struct {
+	line int
+	doc  map[string]any
+}
+
+// mediaRelink is a media value removed from an item, to upload again once the item exists
+type mediaRelink struct {
+	file        string
+	line        int
+	code        string
+	association MediaFileAssociation
+}
+
+// associationPatch holds the associations of an imported product or product model
+type associationPatch struct {
+	file    string
+	relPath string
+	line    int
+	doc     map[string]any // doc is the key of the item with its associations
+}
+
+// readFile calls fn for each line of an export file, a missing file has no line
+func (im *importer) readFile(name string, fn func(snapshotItem) error) error {
+	return readJSONL(filepath.Join(im.dir, name), fn)
+}
+
+// readJSONL calls fn for each line of a JSONL file, a missing file has no line
+func readJSONL(path string, fn func(snapshotItem) error) error {
+	name := filepath.Base(path)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %s", name)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var doc map[string]any
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return errors.Wrapf(err, "invalid line %d of %s", line, name)
+		}
+		if err := fn(snapshotItem{line: line, doc: doc}); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "failed to read file %s", name)
+	}
+	return nil
+}
+
+// readAll reads every line of an export file
+func (im *importer) readAll(name string) ([]snapshotItem, error) {
+	var items []snapshotItem
+	err := im.readFile(name, func(item snapshotItem) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// patchFile imports the items of a file accepted by filter, filter may be nil
+func (im *importer) patchFile(name, relPath string, filter func(map[string]any) bool) error {
+	var batch []snapshotItem
+	err := im.readFile(name, func(item snapshotItem) error {
+		if filter != nil && !filter(item.doc) {
+			return nil
+		}
+		batch = append(batch, item)
+		if len(batch) < im.opts.BatchSize {
+			return nil
+		}
+		err := im.patch(name, relPath, batch)
+		batch = nil
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return im.patch(name, relPath, batch)
+}
+
+// patchNested imports the items of a file grouped by their parent, i.e. the options of each attribute
+func (im *importer) patchNested(name, parentProperty, parentPath, child string, dropParent bool) error {
+	items, err := im.readAll(name)
+	if err != nil {
+		return err
+	}
+	var parents []string
+	byParent := make(map[string][]snapshotItem)
+	for _, item := range items {
+		parent, _ := item.doc[parentProperty].(string)
+		if parent == "" {
+			im.report.fail(ImportFailure{File: name, Line: item.line, Message: "property " + parentProperty + " is required"})
+			continue
+		}
+		if dropParent {
+			delete(item.doc, parentProperty)
+		}
+		if _, ok := byParent[parent]; !ok {
+			parents = append(parents, parent)
+		}
+		byParent[parent] = append(byParent[parent], item)
+	}
+	for _, parent := range parents {
+		relPath := path.Join(parentPath, parent, child)
+		for batch := byParent[parent]; len(batch) > 0; {
+			n := min(len(batch), im.opts.BatchSize)
+			if err := im.patch(name, relPath, batch[:n]); err != nil {
+				return err
+			}
+			batch = batch[n:]
+		}
+	}
+	return nil
+}
+
+// categories imports the categories, the parents before their children
+func (im *importer) categories() error {
+	items, err := im.readAll(ExportFileCategories)
+	if err != nil {
+		return err
+	}
+	byCode := make(map[string]snapshotItem, len(items))
+	for _, item := range items {
+		code, _ := item.doc["code"].(string)
+		byCode[code] = item
+	}
+	depth := func(item snapshotItem) int {
+		d := 0
+		for seen := map[string]bool{}; ; d++ {
+			parent, _ := item.doc["parent"].(string)
+			next, ok := byCode[parent]
+			if parent == "" || !ok || seen[parent] {
+				return d
+			}
+			seen[parent] = true
+			item = next
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return depth(items[i]) < depth(items[j])
+	})
+	for _, item := range items {
+		delete(item.doc, "updated")
+	}
+	for batch := items; len(batch) > 0; {
+		n := min(len(batch), im.opts.BatchSize)
+		if err := im.patch(ExportFileCategories, categoryBasePath, batch[:n]); err != nil {
+			return err
+		}
+		batch = batch[n:]
+	}
+	return nil
+}
+
+// patch sends one batch and records the result of each item
+func (im *importer) patch(name, relPath string, batch []snapshotItem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	docs := make([]map[string]any, len(batch))
+	associations := make([]map[string]any, len(batch))
+	for i, item := range batch {
+		if name == ExportFileProducts || name == ExportFileProductModels {
+			im.prepareProduct(name, item)
+			associations[i] = takeAssociations(item.doc)
+		}
+		docs[i] = item.doc
+	}
+	lines, err := im.client.patchCollection(relPath, docs)
+	if err != nil {
+		return errors.Wrapf(err, "unable to import %s", name)
+	}
+	result := im.report.file(name)
+	for _, line := range lines {
+		fileLine, imported := 0, line.StatusCode == http.StatusCreated || line.StatusCode == http.StatusNoContent
+		if line.Line >= 1 && line.Line <= len(batch) {
+			item := batch[line.Line-1]
+			fileLine = item.line
+			// the associations of a rejected item are not sent, they would create it without its values
+			if doc := associations[line.Line-1]; doc != nil && imported {
+				for _, key := range []string{"identifier", "uuid", "code"} {
+					if v, ok := item.doc[key]; ok {
+						doc[key] = v
+					}
+				}
+				im.associations = append(im.associations, associationPatch{file: name, relPath: relPath, line: item.line, doc: doc})
+			}
+		}
+		switch {
+		case line.StatusCode == http.StatusCreated:
+			result.Created++
+		case line.StatusCode == http.StatusNoContent:
+			result.Updated++
+		default:
+			code := line.Code
+			if code == "" {
+				code = line.Identifier
+			}
+			im.report.fail(ImportFailure{File: name, Line: fileLine, Code: code, StatusCode: line.StatusCode, Message: line.Message})
+		}
+	}
+	return nil
+}
+
+// associate sends the associations removed from the product models and the products, a rejected association
+// is reported as a failure of its item
+func (im *importer) associate() error {
+	for batch := im.associations; len(batch) > 0; {
+		n := 1
+		for n < len(batch) && n < im.opts.BatchSize && batch[n].relPath == batch[0].relPath {
+			n++
+		}
+		docs := make([]map[string]any, n)
+		for i, a := range batch[:n] {
+			docs[i] = a.doc
+		}
+		lines, err := im.client.patchCollection(batch[0].relPath, docs)
+		if err != nil {
+			return errors.Wrapf(err, "unable to import the associations of %s", batch[0].file)
+		}
+		for _, line := range lines {
+			if line.StatusCode == http.StatusNoContent || line.StatusCode == http.StatusCreated || line.Line < 1 || line.Line > n {
+				continue
+			}
+			a := batch[line.Line-1]
+			code := line.Code
+			if code == "" {
+				code = line.Identifier
+			}
+			im.report.fail(ImportFailure{File: a.file, Line: a.line, Code: code, StatusCode: line.StatusCode, Message: line.Message})
+		}
+		batch = batch[n:]
+	}
+	return nil
+}
+
+// takeAssociations removes the associations of a product or a product model, which may target items
+// imported later, it returns nil when the item has none
+func takeAssociations(doc map[string]any) map[string]any {
+	var associations map[string]any
+	for _, key := range []string{"associations", "quantified_associations"} {
+		v, ok := doc[key]
+		if !ok {
+			continue
+		}
+		delete(doc, key)
+		if !hasTargets(v) {
+			continue
+		}
+		if associations == nil {
+			associations = make(map[string]any)
+		}
+		associations[key] = v
+	}
+	return associations
+}
+
+// hasTargets reports whether associations have a target, the PIM exports every association type with empty lists
+func hasTargets(v any) bool {
+	switch t := v.(type) {
+	case map[string]any:
+		for _, e := range t {
+			if hasTargets(e) {
+				return true
+			}
+		}
+	case []any:
+		return len(t) > 0
+	}
+	return false
+}
+
+// prepareProduct removes the read-only properties of a product or a product model,
+// and its media values which are linked again once the media files are uploaded
+func (im *importer) prepareProduct(name string, item snapshotItem) {
+	for _, p := range readOnlyProperties {
+		delete(item.doc, p)
+	}
+	if im.client.osVersion < AkeneoPimVersion7 {
+		delete(item.doc, "uuid")
+	}
+	values, _ := item.doc["values"].(map[string]any)
+	if len(im.media) == 0 || values == nil {
+		return
+	}
+	for attribute, raw := range values {
+		list, _ := raw.([]any)
+		kept := list[:0]
+		for _, v := range list {
+			value, _ := v.(map[string]any)
+			code, _ := value["data"].(string)
+			if _, ok := im.media[code]; !ok {
+				kept = append(kept, v)
+				continue
+			}
+			locale, _ := value["locale"].(string)
+			scope, _ := value["scope"].(string)
+			var association MediaFileAssociation
+			if name == ExportFileProducts {
+				identifier, _ := item.doc["identifier"].(string)
+				association = AssociatedProduct{Identifier: identifier, Attribute: attribute, Locale: locale, Scope: scope}
+			} else {
+				modelCode, _ := item.doc["code"].(string)
+				association = AssociatedProductModel{Code: modelCode, Attribute: attribute, Locale: locale, Scope: scope}
+			}
+			im.relinks = append(im.relinks, mediaRelink{file: name, line: item.line, code: code, association: association})
+		}
+		if len(kept) == 0 {
+			delete(values, attribute)
+		} else {
+			values[attribute] = kept
+		}
+	}
+}
+
+// loadMediaFiles reads the exported media files
+func (im *importer) loadMediaFiles() error {
+	return im.readFile(ExportFileMediaFiles, func(item snapshotItem) error {
+		b, err := json.Marshal(item.doc)
+		if err != nil {
+			return errors.Wrap(err, "unable to marshal media file")
+		}
+		var media MediaFile
+		if err := json.Unmarshal(b, &media); err != nil {
+			return errors.Wrapf(err, "invalid line %d of %s", item.line, ExportFileMediaFiles)
+		}
+		im.media[media.Code] = media
+		return nil
+	})
+}
+
+// relinkMedia uploads the media files of the removed media values and links them to their items
+func (im *importer) relinkMedia() error {
+	for _, r := range im.relinks {
+		if err := im.ctx.Err(); err != nil {
+			return err
+		}
+		media := im.media[r.code]
+		if err := im.upload(media, r.association); err != nil {
+			im.report.fail(ImportFailure{File: r.file, Line: r.line, Code: r.code, Message: err.Error()})
+			continue
+		}
+		im.report.file(ExportFileMediaFiles).Created++
+	}
+	return nil
+}
+
+func (im *importer) upload(media MediaFile, association MediaFileAssociation) error {
+	if !isLocalCode(media.Code) {
+		return errors.Errorf("invalid media file code %q", media.Code)
+	}
+	f, err := os.Open(filepath.Join(im.dir, ExportMediaDir, filepath.FromSlash(media.Code)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open media file %s", media.Code)
+	}
+	defer f.Close()
+	filename := media.OriginalFilename
+	if filename == "" {
+		filename = path.Base(media.Code)
+	}
+	_, err = im.client.MediaFile.CreateFromReader(im.ctx, filename, f, association)
+	return err
+}
+
+// verifyEnabled checks that the enabled items of a read-only resource are enabled in the PIM
+func verifyEnabled[T any](im *importer, name string, list func(options any) ([]T, Links, error), code func(T) (string, bool)) error {
+	items, err := im.readAll(name)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+	enabled := make(map[string]bool)
+	err = listAll(im.ctx, ListOptions{Limit: MaxPatchBatchSize}, list, func(item T) error {
+		c, ok := code(item)
+		enabled[c] = ok
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "unable to verify %s", name)
+	}
+	for _, item := range items {
+		c, _ := item.doc["code"].(string)
+		if on, _ := item.doc["enabled"].(bool); !on {
+			continue
+		}
+		if !enabled[c] {
+			im.report.fail(ImportFailure{File: name, Line: item.line, Code: c, Message: c + " is not enabled in the PIM"})
+			continue
+		}
+		im.report.file(name).Updated++
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package goakeneo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ezifyio/go-akeneo/akeneotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Import(t *testing.T) {
+	source, sourceSrv := newFakeClient(t, WithRateLimit(1000, time.Second))
+	// the print channel uses its own tree, a product model and a product are associated with products
+	assert.NoError(t, sourceSrv.Seed("categories", map[string]any{"code": "print_catalog"}))
+	assert.NoError(t, sourceSrv.Seed("channels", map[string]any{"code": "print", "category_tree": "print_catalog"}))
+	assert.NoError(t, sourceSrv.Seed("product-models", map[string]any{"code": "runner",
+		"associations": map[string]any{"X_SELL": map[string]any{"products": []any{"accessory-01"}}}}))
+	assert.NoError(t, sourceSrv.Seed("products", map[string]any{"identifier": "accessory-01",
+		"associations": map[string]any{"UPSELL": map[string]any{"products": []any{"runner-40"}, "product_models": []any{"runner"}}}}))
+	dir := t.TempDir()
+	_, err := source.Export(context.Background(), dir, ExportOptions{Media: true})
+	assert.NoError(t, err)
+	f, err := os.OpenFile(filepath.Join(dir, ExportFileProducts), os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"family":"shoes"}` + "\n" + `{"identifier":"orphan","associations":{"X_SELL":{"products":["missing"]}}}` + "\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	// the target only has the read-only resources, fr_FR is not enabled
+	srv := akeneotest.NewServer()
+	defer srv.Close()
+	assert.NoError(t, srv.Seed("locales", Locale{Code: "en_US", Enabled: true}, Locale{Code: "fr_FR"}))
+	assert.NoError(t, srv.Seed("currencies", Currency{Code: "EUR", Enabled: true}, Currency{Code: "USD", Enabled: true}))
+	con := Connector{ClientID: srv.ClientID, Secret: srv.Secret, UserName: srv.Username, Password: srv.Password}
+	target, err := con.NewClient(WithBaseURL(srv.URL), WithRateLimit(1000, time.Second))
+	assert.NoError(t, err)
+
+	report, err := target.Import(context.Background(), dir, ImportOptions{BatchSize: 5})
+	assert.NoError(t, err)
+	assert.True(t, report.HasFailures())
+	assert.Equal(t, 13, report.Files[ExportFileProducts].Created)
+	assert.Equal(t, 2, report.Files[ExportFileProducts].Failed)
+	assert.Equal(t, 1, report.Files[ExportFileProductModels].Created)
+	assert.Equal(t, 1, report.Files[ExportFileMediaFiles].Created)
+	if assert.Len(t, report.Failures, 3) {
+		assert.Equal(t, ImportFailure{File: ExportFileLocales, Line: 3, Code: "fr_FR", Message: "fr_FR is not enabled in the PIM"}, report.Failures[0])
+		assert.Equal(t, ExportFileProducts, report.Failures[1].File)
+		assert.Equal(t, 13, report.Failures[1].Line)
+		assert.Equal(t, 422, report.Failures[1].StatusCode)
+		// the associations are sent once every product exists, their failures are reported per item
+		assert.Equal(t, ImportFailure{File: ExportFileProducts, Line: 14, Code: "orphan", StatusCode: 422,
+			Message: `property "associations" expects valid products for the association type "X_SELL", "missing" does not exist`}, report.Failures[2])
+	}
+
+	// the target had no attribute group, the groups of the attributes are imported first
+	assert.Equal(t, 4, report.Files[ExportFileAttributeGroups].Created)
+	groups, _, err := target.AttributeGroup.ListWithPagination(nil)
+	assert.NoError(t, err)
+	if assert.Len(t, groups, 4) {
+		assert.Equal(t, "general", groups[0].Code)
+		assert.Equal(t, map[string]string{"en_US": "General"}, groups[0].Labels)
+	}
+	variant, err := target.Family.GetFamilyVariant("shoes", "shoes_by_size")
+	assert.NoError(t, err)
+	assert.Equal(t, "shoes_by_size", variant.Code)
+	model, err := target.ProductModel.GetProductModel("runner", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "runner", model.Code)
+	assert.Equal(t, []string{"accessory-01"}, model.Associations["X_SELL"].Products)
+	accessory, err := target.Product.GetProduct("accessory-01", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Association{Products: []string{"runner-40"}, ProductModels: []string{"runner"}}, accessory.Associations["UPSELL"])
+	channel, err := target.Catalog.Channel(context.Background(), "print")
+	assert.NoError(t, err)
+	assert.Equal(t, "print_catalog", channel.CategoryTree)
+	p, err := target.Product.GetProduct("code-a90521134-6r948km3pcwxnvdy", nil)
+	assert.NoError(t, err)
+	code, _ := p.Values["image"][0].Data.(string)
+	assert.True(t, strings.HasSuffix(code, "_shoe.jpeg"), code)
+	content, ok := srv.MediaContent(code)
+	assert.True(t, ok)
+	assert.Equal(t, "\xff\xd8\xff fake shoe image", string(content))
+}
+
+func TestClient_ImportLayoutVersion(t *testing.T) {
+	c, _ := newFakeClient(t)
+	dir := t.TempDir()
+	_, err := c.Import(context.Background(), dir, ImportOptions{})
+	assert.ErrorContains(t, err, "manifest")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ExportFileManifest), []byte(`{"layout_version":99}`), 0644))
+	_, err = c.Import(context.Background(), dir, ImportOptions{})
+	assert.ErrorContains(t, err, "unsupported export layout version 99")
+}
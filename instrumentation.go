@@ -0,0 +1,158 @@
+package goakeneo
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const apiRestPrefix = "/api/rest/v1/"
+
+// Instrumentation receives the events of the client to build metrics and traces,
+// see Metrics for Prometheus style collectors and SpanHooks for tracing
+type Instrumentation interface {
+	// OnRequestStart is called before a request waits for the rate limiter,
+	// the returned context is passed to the other calls for the same request
+	OnRequestStart(ctx context.Context, info RequestInfo) context.Context
+	// OnRequestEnd is called once the request and its retries are done
+	OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult)
+	// OnRetry is called each time a request is going to be retried
+	OnRetry(ctx context.Context, info RequestInfo, attempt int, statusCode int, err error)
+	// OnAuth is called after each token grant, grantType is "password" or "refresh_token"
+	OnAuth(grantType string, duration time.Duration, err error)
+}
+
+// RequestInfo describes a request made by the client
+type RequestInfo struct {
+	Method       string
+	Path         string
+	Endpoint     string // Endpoint is the path with identifiers replaced, i.e. /api/rest/v1/products/{id}
+	ResourceType string // ResourceType is the akeneo resource, i.e. products, families, media-files
+	ResourceID   string // ResourceID is the identifier or code of the resource, empty for lists
+}
+
+// RequestResult describes the outcome of a request made by the client
+type RequestResult struct {
+	StatusCode    int
+	Attempts      int
+	Bytes         int64
+	Duration      time.Duration // Duration of the request including retries
+	RateLimitWait time.Duration // RateLimitWait is the time spent waiting for the rate limiter
+	Err           error
+}
+
+// WithInstrumentation sets the instrumentation of the client,
+// use MultiInstrumentation to combine several of them
+func WithInstrumentation(i Instrumentation) Option {
+	return func(c *Client) {
+		if i != nil {
+			c.hooks = i
+		}
+	}
+}
+
+// MultiInstrumentation dispatches every event to all the given instrumentations
+func MultiInstrumentation(is ...Instrumentation) Instrumentation {
+	return multiInstrumentation(is)
+}
+
+type multiInstrumentation []Instrumentation
+
+func (m multiInstrumentation) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	for _, i := range m {
+		ctx = i.OnRequestStart(ctx, info)
+	}
+	return ctx
+}
+
+func (m multiInstrumentation) OnRequestEnd(ctx context.Context, info RequestInfo, result RequestResult) {
+	for _, i := range m {
+		i.OnRequestEnd(ctx, info, result)
+	}
+}
+
+func (m multiInstrumentation) OnRetry(ctx context.Context, info RequestInfo, attempt int, statusCode int, err error) {
+	for _, i := range m {
+		i.OnRetry(ctx, info, attempt, statusCode, err)
+	}
+}
+
+func (m multiInstrumentation) OnAuth(grantType string, duration time.Duration, err error) {
+	for _, i := range m {
+		i.OnAuth(grantType, duration, err)
+	}
+}
+
+// nopInstrumentation is the default instrumentation of the client
+type nopInstrumentation struct{}
+
+func (nopInstrumentation) OnRequestStart(ctx context.Context, _ RequestInfo) context.Context {
+	return ctx
+}
+func (nopInstrumentation) OnRequestEnd(context.Context, RequestInfo, RequestResult) {}
+func (nopInstrumentation) OnRetry(context.Context, RequestInfo, int, int, error)    {}
+func (nopInstrumentation) OnAuth(string, time.Duration, error)                      {}
+
+// instrumentRetry reports a retry to the instrumentation, see onRetry
+func (c *Client) instrumentRetry(resp *resty.Response, err error) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+	u, perr := url.Parse(resp.Request.URL)
+	if perr != nil {
+		return
+	}
+	c.hooks.OnRetry(resp.Request.Context(), newRequestInfo(resp.Request.Method, u), resp.Request.Attempt, resp.StatusCode(), err)
+}
+
+// newRequestInfo extracts the akeneo resource type and identifier from the request url
+func newRequestInfo(method string, u *url.URL) RequestInfo {
+	info := RequestInfo{
+		Method:   method,
+		Path:     u.Path,
+		Endpoint: u.Path,
+	}
+	if strings.HasSuffix(u.Path, authBasePath) {
+		info.ResourceType = "token"
+		return info
+	}
+	rel, ok := strings.CutPrefix(u.Path, apiRestPrefix)
+	if !ok {
+		return info
+	}
+	resourceType, rest, _ := strings.Cut(rel, "/")
+	info.ResourceType = resourceType
+	info.Endpoint = apiRestPrefix + resourceType
+	if rest == "" {
+		return info
+	}
+	// media file codes contain slashes, i.e. /api/rest/v1/media-files/1/3/e/d/13ed..._file.jpeg/download
+	if resourceType == "media-files" {
+		code, download := strings.CutSuffix(rest, "/download")
+		info.ResourceID = code
+		info.Endpoint += "/{code}"
+		if download {
+			info.Endpoint += "/download"
+		}
+		return info
+	}
+	segments := strings.Split(rest, "/")
+	for i, segment := range segments {
+		// identifiers and sub resources alternate, i.e. families/{code}/variants/{code}
+		if i%2 == 1 {
+			info.Endpoint += "/" + segment
+			continue
+		}
+		if id, err := url.PathUnescape(segment); err == nil && info.ResourceID == "" {
+			info.ResourceID = id
+		}
+		info.Endpoint += "/{code}"
+	}
+	if resourceType == "products" || resourceType == "products-uuid" {
+		info.Endpoint = strings.Replace(info.Endpoint, "{code}", "{id}", 1)
+	}
+	return info
+}
@@ -0,0 +1,99 @@
+package goakeneo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequestInfo(t *testing.T) {
+	tests := []struct {
+		path         string
+		endpoint     string
+		resourceType string
+		resourceID   string
+	}{
+		{"/api/rest/v1/products", "/api/rest/v1/products", "products", ""},
+		{"/api/rest/v1/products/code-1", "/api/rest/v1/products/{id}", "products", "code-1"},
+		{"/api/rest/v1/families/shoes/variants/by_size", "/api/rest/v1/families/{code}/variants/{code}", "families", "shoes"},
+		{"/api/rest/v1/media-files/1/3/e/d/13ed_file.jpeg/download", "/api/rest/v1/media-files/{code}/download", "media-files", "1/3/e/d/13ed_file.jpeg"},
+		{"/api/oauth/v1/token", "/api/oauth/v1/token", "token", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info := newRequestInfo(http.MethodGet, &url.URL{Path: tt.path})
+			assert.Equal(t, tt.endpoint, info.Endpoint)
+			assert.Equal(t, tt.resourceType, info.ResourceType)
+			assert.Equal(t, tt.resourceID, info.ResourceID)
+		})
+	}
+}
+
+type recordedSpan struct {
+	name       string
+	attributes map[string]any
+	events     []string
+	ended      bool
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, attributes map[string]any) (context.Context, Span) {
+	s := &recordedSpan{name: name, attributes: attributes}
+	r.spans = append(r.spans, s)
+	return ctx, s
+}
+
+func (s *recordedSpan) AddEvent(name string, _ map[string]any) { s.events = append(s.events, name) }
+func (s *recordedSpan) End(attributes map[string]any, _ error) {
+	for k, v := range attributes {
+		s.attributes[k] = v
+	}
+	s.ended = true
+}
+
+func TestWithInstrumentation(t *testing.T) {
+	calls := 0
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":"shoes"}`))
+	})
+	metrics := NewMetrics()
+	tracer := &recordingTracer{}
+	c := newTestClient(t, srv,
+		WithRetryPolicy(testRetryPolicy()),
+		WithInstrumentation(MultiInstrumentation(metrics, SpanHooks{Tracer: tracer})),
+	)
+	_, err := c.Family.GetFamily("shoes", nil)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = metrics.WriteTo(&buf)
+	assert.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, `akeneo_requests_total{method="GET",endpoint="/api/rest/v1/families/{code}",status="200"} 1`)
+	assert.Contains(t, out, `akeneo_request_retries_total{method="GET",endpoint="/api/rest/v1/families/{code}"} 1`)
+	assert.Contains(t, out, `akeneo_request_duration_seconds_count{method="GET",endpoint="/api/rest/v1/families/{code}"} 1`)
+	assert.Contains(t, out, `akeneo_token_grants_total{grant_type="password",result="success"} 1`)
+	assert.Contains(t, out, "akeneo_rate_limit_wait_seconds_count 1")
+
+	assert.Len(t, tracer.spans, 2)
+	span := tracer.spans[1]
+	assert.True(t, span.ended)
+	assert.Equal(t, "families", span.attributes[SpanAttrResourceType])
+	assert.Equal(t, "shoes", span.attributes[SpanAttrResourceID])
+	assert.Equal(t, http.StatusOK, span.attributes[SpanAttrStatusCode])
+	assert.Equal(t, 2, span.attributes[SpanAttrAttempts])
+	assert.Equal(t, []string{"retry"}, span.events)
+}
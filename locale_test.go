@@ -7,7 +7,7 @@ import (
 )
 
 func TestLocaleOp_ListWithPagination(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	locales, pagi, err := c.Locale.ListWithPagination(nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, locales)
@@ -0,0 +1,131 @@
+package goakeneo
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveKeys are query or body keys whose values must never be logged
+var sensitiveKeys = map[string]struct{}{
+	"access_token":  {},
+	"refresh_token": {},
+	"token":         {},
+	"password":      {},
+	"secret":        {},
+	"client_secret": {},
+}
+
+// WithLogger sets the structured logger of the client,
+// every request, retry and auth event is logged with credentials and tokens redacted
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		if l != nil {
+			c.logger = l
+		}
+	}
+}
+
+// LogValue implements slog.LogValuer, the secret and the password are never logged
+func (c Connector) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("client_id", c.ClientID),
+		slog.String("secret", redacted),
+		slog.String("username", c.UserName),
+		slog.String("password", redacted),
+	)
+}
+
+// logRequest logs the outcome of a request
+func (c *Client) logRequest(method string, u *url.URL, resp *resty.Response, start time.Time, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("path", u.Path),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if q := redactQuery(u.Query()); q != "" {
+		attrs = append(attrs, slog.String("query", q))
+	}
+	level := slog.LevelDebug
+	if resp != nil {
+		attrs = append(attrs,
+			slog.Int("status", resp.StatusCode()),
+			slog.Int("attempt", resp.Request.Attempt),
+			slog.Int64("bytes", resp.Size()),
+		)
+		if resp.IsError() {
+			level = slog.LevelWarn
+		}
+	}
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.logger.LogAttrs(context.Background(), level, "akeneo request", attrs...)
+}
+
+// logRetry logs a retry of a request, see onRetry
+func (c *Client) logRetry(resp *resty.Response, err error) {
+	attrs := make([]slog.Attr, 0, 5)
+	if resp != nil && resp.Request != nil {
+		attrs = append(attrs,
+			slog.String("method", resp.Request.Method),
+			slog.Int("attempt", resp.Request.Attempt),
+			slog.Int("status", resp.StatusCode()),
+		)
+		if u, perr := url.Parse(resp.Request.URL); perr == nil {
+			attrs = append(attrs, slog.String("path", u.Path))
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.logger.LogAttrs(context.Background(), slog.LevelWarn, "akeneo request retry", attrs...)
+}
+
+// logAuth logs an auth event, the tokens are never logged
+func (c *Client) logAuth(grantType string, start, expiresAt time.Time, err error) {
+	attrs := []slog.Attr{
+		slog.String("grant_type", grantType),
+		slog.String("client_id", c.connector.ClientID),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		c.logger.LogAttrs(context.Background(), slog.LevelError, "akeneo auth failed", attrs...)
+		return
+	}
+	attrs = append(attrs, slog.Time("token_expires_at", expiresAt))
+	c.logger.LogAttrs(context.Background(), slog.LevelInfo, "akeneo auth", attrs...)
+}
+
+// redactQuery encodes the query with the sensitive values redacted
+func redactQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	for key := range q {
+		if _, ok := sensitiveKeys[strings.ToLower(key)]; ok {
+			q[key] = []string{redacted}
+		}
+	}
+	s, err := url.QueryUnescape(q.Encode())
+	if err != nil {
+		return q.Encode()
+	}
+	return s
+}
+
+// discardHandler is the default slog handler of the client, it drops every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,47 @@
+package goakeneo
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogger(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"_embedded":{"items":[{"code":"en_US","enabled":true}]}}`))
+	})
+	var buf bytes.Buffer
+	c := newTestClient(t, srv, WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	_, _, err := c.Locale.ListWithPagination(ListOptions{Limit: 10})
+	assert.NoError(t, err)
+
+	out := buf.String()
+	for _, secret := range []string{"secret-access", "secret-refresh", "client-secret", "user-password"} {
+		assert.NotContains(t, out, secret)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 2)
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "akeneo request", record["msg"])
+	assert.Equal(t, http.MethodGet, record["method"])
+	assert.Equal(t, localeBasePath, record["path"])
+	assert.Equal(t, "limit=10", record["query"])
+	assert.EqualValues(t, http.StatusOK, record["status"])
+	assert.EqualValues(t, 1, record["attempt"])
+}
+
+func TestConnector_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	l.Info("connector", "connector", Connector{ClientID: "id", Secret: "s3cr3t", UserName: "user", Password: "p4ss"})
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.NotContains(t, buf.String(), "p4ss")
+	assert.Contains(t, buf.String(), "connector.client_id=id")
+}
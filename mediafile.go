@@ -1,24 +1,35 @@
 package goakeneo
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/pkg/errors"
 	"io"
+	"mime"
 	"mime/multipart"
+	"net/textproto"
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 )
 
 const mediaBasePath = "/api/rest/v1/media-files"
 
+// ErrSizeMismatch is returned when a downloaded media file does not have the size of the media file,
+// or the size announced by the Content-Length or the Content-Range of the download
+var ErrSizeMismatch = errors.New("downloaded size does not match the media file size")
+
 // MediaFileService see: https://api.akeneo.com/api-reference.html#media-files
 type MediaFileService interface {
 	ListPagination(options any) ([]MediaFile, Links, error)
 	GetByCode(code string, options any) (*MediaFile, error)
 	Download(code, filePath string, options any) error
+	DownloadTo(ctx context.Context, code string, w io.Writer) error
 	Create(filePath string, association MediaFileAssociation) (string, error)
+	CreateFromReader(ctx context.Context, filename string, r io.Reader, association MediaFileAssociation) (string, error)
 }
 
 type mediaOp struct {
@@ -54,60 +65,158 @@ func (c *mediaOp) GetByCode(code string, options any) (*MediaFile, error) {
 	return result, nil
 }
 
-// Download downloads a media file by code
+// Download downloads a media file by code to filePath,
+// the file is written to a temporary file first and renamed once complete
 func (c *mediaOp) Download(code, filePath string, options any) error {
 	options = nil // options are not supported for downloading media files yet
-	sourcePath := path.Join(mediaBasePath, code, "download")
-	sourceP, _ := url.Parse(sourcePath)
-	downloadURL := c.client.baseURL.ResolveReference(sourceP).String()
-	if err := c.client.download(downloadURL, filePath); err != nil {
+	return downloadFile(context.Background(), c, code, filePath)
+}
+
+// DownloadMediaFile downloads a media file by code to filePath with the media file service, an existing file
+// is only replaced once the download is complete
+func (c *Client) DownloadMediaFile(ctx context.Context, code, filePath string) error {
+	return downloadFile(ctx, c.MediaFile, code, filePath)
+}
+
+// downloadFile downloads a media file by code to filePath with DownloadTo,
+// through a temporary file renamed once complete so a cancelled download leaves no partial file
+func downloadFile(ctx context.Context, media MediaFileService, code, filePath string) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create dir, path: %s", dir)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".*.part")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file, path: %s", filePath)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if err := media.DownloadTo(ctx, code, tmp); err != nil {
+		_ = tmp.Close()
 		return err
 	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write file, path: %s", filePath)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return errors.Wrapf(err, "failed to rename file, path: %s", filePath)
+	}
 	return nil
 }
 
-// Create creates a media file
-func (c *mediaOp) Create(filePath string, association MediaFileAssociation) (string, error) {
-	// check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return "", errors.Wrapf(err, "file %s does not exist", filePath)
+// DownloadTo streams a media file by code to w, an interrupted transfer is resumed with a Range request.
+// The downloaded size is verified against the size of the media file and the Content-Length of the download
+func (c *mediaOp) DownloadTo(ctx context.Context, code string, w io.Writer) error {
+	media, err := c.GetByCode(code, nil)
+	if err != nil {
+		return err
 	}
+	sourcePath := path.Join(mediaBasePath, code, "download")
+	sourceP, _ := url.Parse(sourcePath)
+	downloadURL := c.client.baseURL.ResolveReference(sourceP)
+	return c.client.download(ctx, downloadURL, w, int64(media.Size))
+}
+
+// Create uploads a local file as a media file, see CreateFromReader
+func (c *mediaOp) Create(filePath string, association MediaFileAssociation) (string, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to open file %s", filePath)
 	}
 	defer f.Close()
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-	fileWriter, err := writer.CreateFormFile("file", path.Base(filePath))
+	// the file is read again from the start when the upload is retried
+	newBody := func() (string, io.ReadCloser, error) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return "", nil, errors.Wrapf(err, "failed to seek file %s", filePath)
+		}
+		contentType, body := mediaForm(filepath.Base(filePath), f, association)
+		return contentType, body, nil
+	}
+	code, err := c.createMedia(context.Background(), newBody, true)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to create form file %s", filePath)
+		return "", errors.Wrapf(err, "failed to upload file %s", filePath)
 	}
-	// copy image file to form file writer
-	if _, err = io.Copy(fileWriter, f); err != nil {
-		return "", errors.Wrapf(err, "failed to copy file %s", filePath)
+	return code, nil
+}
+
+// CreateFromReader uploads the content of r as a media file named filename and returns its code,
+// the multipart body is streamed so the upload is not retried.
+// association links the media file to a product or a product model value, it may be nil
+func (c *mediaOp) CreateFromReader(ctx context.Context, filename string, r io.Reader, association MediaFileAssociation) (string, error) {
+	newBody := func() (string, io.ReadCloser, error) {
+		contentType, body := mediaForm(filename, r, association)
+		return contentType, body, nil
 	}
-	// add association
-	if association.Type() == "product" {
-		if err = writer.WriteField("product", association.ToJSONString()); err != nil {
-			return "", errors.Wrapf(err, "failed to write field product %s", filePath)
-		}
-	} else if association.Type() == "product_model" {
-		if err = writer.WriteField("product_model", association.ToJSONString()); err != nil {
-			return "", errors.Wrapf(err, "failed to write field product_model %s", filePath)
+	return c.createMedia(ctx, newBody, false)
+}
+
+// createMedia uploads a media file form and returns the code of the media file
+func (c *mediaOp) createMedia(ctx context.Context, newBody uploadBody, replayable bool) (string, error) {
+	location, err := c.client.upload(ctx, mediaBasePath, newBody, replayable)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(location)
+	if err != nil || !strings.HasPrefix(u.Path, mediaBasePath+"/") {
+		return "", errors.Errorf("unexpected media file location %q", location)
+	}
+	return strings.TrimPrefix(u.Path, mediaBasePath+"/"), nil
+}
+
+// mediaForm streams the multipart form of a media file upload from r,
+// closing the body stops the writer and waits until it no longer reads r
+func mediaForm(filename string, r io.Reader, association MediaFileAssociation) (string, io.ReadCloser) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(writeMediaForm(writer, filename, r, association))
+	}()
+	return writer.FormDataContentType(), &formBody{PipeReader: pr, done: done}
+}
+
+// formBody is the body of a streamed form
+type formBody struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+func (b *formBody) Close() error {
+	err := b.PipeReader.Close()
+	<-b.done
+	return err
+}
+
+// writeMediaForm writes the association field and the file part of a media file upload
+func writeMediaForm(writer *multipart.Writer, filename string, r io.Reader, association MediaFileAssociation) error {
+	if association != nil {
+		if err := writer.WriteField(association.Type(), association.ToJSONString()); err != nil {
+			return errors.Wrapf(err, "failed to write field %s", association.Type())
 		}
 	}
-	// close writer
-	if err = writer.Close(); err != nil {
-		return "", errors.Wrapf(err, "failed to close writer %s", filePath)
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
 	}
-	uri, err := c.client.upload(mediaBasePath, writer)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+	fileWriter, err := writer.CreatePart(header)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to upload file %s", filePath)
+		return errors.Wrapf(err, "failed to create form file %s", filename)
+	}
+	if _, err := io.Copy(fileWriter, r); err != nil {
+		return errors.Wrapf(err, "failed to copy file %s", filename)
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close writer %s", filename)
 	}
-	return uri, nil
+	return nil
 }
 
+// quoteEscaper escapes the file name like multipart.Writer.CreateFormFile
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
 type MediaFileAssociation interface {
 	ToJSONString() string
 	Type() string
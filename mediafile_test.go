@@ -1,12 +1,27 @@
 package goakeneo
 
 import (
-	"github.com/stretchr/testify/assert"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMediaOp_ListPagination(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	ms, links, err := c.MediaFile.ListPagination(nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, ms)
@@ -14,14 +29,226 @@ func TestMediaOp_ListPagination(t *testing.T) {
 }
 
 func TestMediaOp_GetByCode(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	m, err := c.MediaFile.GetByCode("b/a/7/9/ba795607155860d543ab1d1f97a91a0dba7d98a8_____________.png", nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, m)
 }
 
 func TestMediaOp_Download(t *testing.T) {
-	c := MockDLClient()
-	err := c.MediaFile.Download("/1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_0______.jpeg", "./media/test.png", nil)
+	c, _ := newFakeClient(t)
+	fp := filepath.Join(t.TempDir(), "media", "test.png")
+	err := c.MediaFile.Download("/1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_0______.jpeg", fp, nil)
+	assert.NoError(t, err)
+	content, err := os.ReadFile(fp)
+	assert.NoError(t, err)
+	assert.Equal(t, "\xff\xd8\xff fake shoe image", string(content))
+}
+
+func TestClient_DownloadMediaFile(t *testing.T) {
+	c, _ := newFakeClient(t)
+	fp := filepath.Join(t.TempDir(), "cache", "shoe.jpeg")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(fp), 0755))
+	assert.NoError(t, os.WriteFile(fp, []byte("cached"), 0644))
+	// a failed download keeps the cached copy
+	err := c.DownloadMediaFile(context.Background(), "0/0/0/0/missing.jpeg", fp)
+	assert.Error(t, err)
+	content, err := os.ReadFile(fp)
+	assert.NoError(t, err)
+	assert.Equal(t, "cached", string(content))
+
+	assert.NoError(t, c.DownloadMediaFile(context.Background(), "1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_0______.jpeg", fp))
+	content, err = os.ReadFile(fp)
+	assert.NoError(t, err)
+	assert.Equal(t, "\xff\xd8\xff fake shoe image", string(content))
+}
+
+// newMediaServer serves one media file of size bytes whose first download is cut after half of the content,
+// the resumed downloads serve resumed, content by default
+func newMediaServer(t *testing.T, content []byte, size int, resumed []byte) (*httptest.Server, *[]string) {
+	if resumed == nil {
+		resumed = content
+	}
+	var ranges []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case mediaBasePath + "/a/b/c/d/video.mp4":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(MediaFile{Code: "a/b/c/d/video.mp4", Size: size})
+		case mediaBasePath + "/a/b/c/d/video.mp4/download":
+			ranges = append(ranges, r.Header.Get("Range"))
+			if len(ranges) == 1 {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				_, _ = w.Write(content[:len(content)/2])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			http.ServeContent(w, r, "video.mp4", time.Time{}, bytes.NewReader(resumed))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return srv, &ranges
+}
+
+func TestMediaOp_DownloadToResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	srv, ranges := newMediaServer(t, content, len(content), nil)
+	c := newTestClient(t, srv)
+	var buf bytes.Buffer
+	err := c.MediaFile.DownloadTo(context.Background(), "a/b/c/d/video.mp4", &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, content, buf.Bytes())
+	assert.Equal(t, []string{"", fmt.Sprintf("bytes=%d-", len(content)/2)}, *ranges)
+}
+
+func TestMediaOp_DownloadSizeMismatch(t *testing.T) {
+	content := []byte("stale file in the PIM")
+	srv, _ := newMediaServer(t, content, len(content)+1, nil)
+	c := newTestClient(t, srv)
+	fp := filepath.Join(t.TempDir(), "video.mp4")
+	err := c.MediaFile.Download("a/b/c/d/video.mp4", fp, nil)
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+	// nothing is left behind, neither the file nor the temporary file
+	entries, err := os.ReadDir(filepath.Dir(fp))
 	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// bodyCounter counts the response bodies which are not closed
+type bodyCounter struct {
+	next http.RoundTripper
+	open atomic.Int32
+}
+
+func (b *bodyCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := b.next.RoundTrip(req)
+	if err == nil {
+		b.open.Add(1)
+		resp.Body = &countedBody{ReadCloser: resp.Body, open: &b.open}
+	}
+	return resp, err
+}
+
+type countedBody struct {
+	io.ReadCloser
+	open   *atomic.Int32
+	closed bool
+}
+
+func (b *countedBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		b.open.Add(-1)
+	}
+	return b.ReadCloser.Close()
+}
+
+func TestMediaOp_DownloadContentRangeMismatch(t *testing.T) {
+	content := []byte("replaced during the download")
+	srv, ranges := newMediaServer(t, content, len(content), append(content, " again"...))
+	c := newTestClient(t, srv)
+	err := c.MediaFile.DownloadTo(context.Background(), "a/b/c/d/video.mp4", io.Discard)
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+	assert.ErrorContains(t, err, "during the download")
+	assert.Len(t, *ranges, 2)
+}
+
+func TestMediaOp_DownloadRetriedBodiesClosed(t *testing.T) {
+	attempts := 0
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/download") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(MediaFile{Code: "a/b/c/d/video.mp4", Size: len("content")})
+			return
+		}
+		if attempts++; attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("content"))
+	})
+	c := newTestClient(t, srv, WithRetryPolicy(testRetryPolicy()))
+	counter := &bodyCounter{next: http.DefaultTransport}
+	c.httpClient.Transport = counter
+	var buf bytes.Buffer
+	assert.NoError(t, c.MediaFile.DownloadTo(context.Background(), "a/b/c/d/video.mp4", &buf))
+	assert.Equal(t, "content", buf.String())
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, int32(0), counter.open.Load(), "the bodies of the retried responses are closed")
+}
+
+func TestMediaOp_CreateFromReader(t *testing.T) {
+	c, srv := newFakeClient(t)
+	content := []byte("\x89PNG streamed image")
+	code, err := c.MediaFile.CreateFromReader(context.Background(), "accessory.png", bytes.NewReader(content),
+		AssociatedProduct{Identifier: "accessory-01", Attribute: "image"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]/[0-9a-f]/[0-9a-f]/[0-9a-f]/[0-9a-f]{40}_accessory\.png$`, code)
+	stored, ok := srv.MediaContent(code)
+	assert.True(t, ok)
+	assert.Equal(t, content, stored)
+
+	p, err := c.Product.GetProduct("accessory-01", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, code, p.Values["image"][0].Data)
+}
+
+func TestMediaOp_Create(t *testing.T) {
+	c, srv := newFakeClient(t)
+	fp := filepath.Join(t.TempDir(), "manual.pdf")
+	assert.NoError(t, os.WriteFile(fp, []byte("%PDF-1.4"), 0644))
+	code, err := c.MediaFile.Create(fp, nil)
+	assert.NoError(t, err)
+	stored, ok := srv.MediaContent(code)
+	assert.True(t, ok)
+	assert.Equal(t, "%PDF-1.4", string(stored))
+
+	_, err = c.MediaFile.Create(filepath.Join(t.TempDir(), "missing.pdf"), nil)
+	assert.Error(t, err)
+}
+
+func TestMediaOp_CreateRetried(t *testing.T) {
+	var uploads []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(f)
+		uploads = append(uploads, string(content))
+		if len(uploads) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Location", "http://"+r.Host+mediaBasePath+"/a/b/c/d/abcd_manual.pdf")
+		w.WriteHeader(http.StatusCreated)
+	})
+	c := newTestClient(t, srv, WithRetryPolicy(testRetryPolicy()))
+	fp := filepath.Join(t.TempDir(), "manual.pdf")
+	assert.NoError(t, os.WriteFile(fp, []byte("%PDF-1.4"), 0644))
+	code, err := c.MediaFile.Create(fp, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "a/b/c/d/abcd_manual.pdf", code)
+	// the file is sent again from the start
+	assert.Equal(t, []string{"%PDF-1.4", "%PDF-1.4"}, uploads)
+
+	// a streamed upload is not replayed
+	uploads = nil
+	_, err = c.MediaFile.CreateFromReader(context.Background(), "manual.pdf", strings.NewReader("%PDF-1.4"), nil)
+	assert.Error(t, err)
+	assert.Len(t, uploads, 1)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk failure")
+}
+
+func TestMediaOp_CreateFromReaderError(t *testing.T) {
+	c, _ := newFakeClient(t)
+	_, err := c.MediaFile.CreateFromReader(context.Background(), "broken.png", failingReader{}, nil)
+	assert.ErrorContains(t, err, "disk failure")
 }
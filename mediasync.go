@@ -0,0 +1,200 @@
+package goakeneo
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const defaultMediaSyncConcurrency = 4
+
+// MediaSync mirrors the media files referenced by the products in a local cache.
+// The cache is content addressed: a file is stored under its Akeneo code, which embeds the hash
+// of the file, so a cached file never changes and only the missing files are downloaded
+type MediaSync struct {
+	client         *Client
+	CacheDir       string // CacheDir is the root of the cache
+	Concurrency    int    // Concurrency is the number of parallel downloads, 4 by default
+	ProductOptions any    // ProductOptions are passed to GetAllProducts, e.g. to filter the products
+}
+
+// MediaSyncReport is the result of a media synchronization, codes are sorted
+type MediaSyncReport struct {
+	Referenced int              // Referenced is the number of distinct media files referenced by the products
+	Downloaded []string         // Downloaded are the codes of the files missing from the cache
+	Skipped    []string         // Skipped are the codes of the files already cached
+	Failed     map[string]error // Failed are the downloads which failed by code
+	Orphans    []string         // Orphans are the codes of the cached files no product references anymore
+}
+
+// NewMediaSync returns a media synchronization of the client into cacheDir
+func NewMediaSync(c *Client, cacheDir string) *MediaSync {
+	return &MediaSync{
+		client:      c,
+		CacheDir:    cacheDir,
+		Concurrency: defaultMediaSyncConcurrency,
+	}
+}
+
+// Path returns the path of a media file in the cache
+func (m *MediaSync) Path(code string) string {
+	return filepath.Join(m.CacheDir, filepath.FromSlash(strings.TrimPrefix(code, "/")))
+}
+
+// Run downloads the media files referenced by the products and missing from the cache,
+// it returns the partial report with an error when the products can not be listed
+func (m *MediaSync) Run(ctx context.Context) (*MediaSyncReport, error) {
+	report := &MediaSyncReport{Failed: make(map[string]error)}
+	var mu sync.Mutex
+	concurrency := m.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for code := range jobs {
+				err := downloadFile(ctx, m.client.MediaFile, code, m.Path(code))
+				mu.Lock()
+				if err != nil {
+					report.Failed[code] = err
+				} else {
+					report.Downloaded = append(report.Downloaded, code)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	referenced := make(map[string]struct{})
+	err := m.eachProduct(ctx, func(p Product) {
+		for _, code := range productMediaCodes(p) {
+			if _, ok := referenced[code]; ok {
+				continue
+			}
+			referenced[code] = struct{}{}
+			if !isLocalCode(code) {
+				mu.Lock()
+				report.Failed[code] = errors.Errorf("invalid media file code %q", code)
+				mu.Unlock()
+				continue
+			}
+			if info, err := os.Stat(m.Path(code)); err == nil && info.Mode().IsRegular() {
+				mu.Lock()
+				report.Skipped = append(report.Skipped, code)
+				mu.Unlock()
+				continue
+			}
+			select {
+			case jobs <- code:
+			case <-ctx.Done():
+			}
+		}
+	})
+	close(jobs)
+	wg.Wait()
+	report.Referenced = len(referenced)
+	sort.Strings(report.Downloaded)
+	sort.Strings(report.Skipped)
+	if err != nil {
+		return report, err
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	orphans, err := m.orphans(referenced)
+	report.Orphans = orphans
+	return report, err
+}
+
+// eachProduct calls fn for every product until the listing ends or fails
+func (m *MediaSync) eachProduct(ctx context.Context, fn func(Product)) error {
+	prodChan, errChan := m.client.Product.GetAllProducts(ctx, m.ProductOptions)
+	for prodChan != nil || errChan != nil {
+		select {
+		case p, ok := <-prodChan:
+			if !ok {
+				prodChan = nil
+				continue
+			}
+			fn(p)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				return errors.Wrap(err, "unable to list products")
+			}
+		}
+	}
+	return nil
+}
+
+// orphans lists the cached files which are not referenced, the temporary files of the downloads are ignored
+func (m *MediaSync) orphans(referenced map[string]struct{}) ([]string, error) {
+	var orphans []string
+	err := filepath.WalkDir(m.CacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == m.CacheDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(d.Name(), ".part") {
+			return nil
+		}
+		rel, err := filepath.Rel(m.CacheDir, path)
+		if err != nil {
+			return err
+		}
+		code := filepath.ToSlash(rel)
+		if _, ok := referenced[code]; !ok {
+			orphans = append(orphans, code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to walk cache %s", m.CacheDir)
+	}
+	return orphans, nil
+}
+
+// productMediaCodes returns the codes of the media and media set values of a product
+func productMediaCodes(p Product) []string {
+	var codes []string
+	for _, values := range p.Values {
+		for _, v := range values {
+			if v.Links == nil {
+				continue
+			}
+			parsed, err := v.ParseValue()
+			if err != nil {
+				continue
+			}
+			switch mv := parsed.(type) {
+			case MediaValue:
+				codes = append(codes, strings.TrimPrefix(mv.Data, "/"))
+			case MediaSetValue:
+				for _, code := range mv.Data {
+					codes = append(codes, strings.TrimPrefix(code, "/"))
+				}
+			}
+		}
+	}
+	return codes
+}
+
+// isLocalCode reports whether a media file code stays inside the cache directory
+func isLocalCode(code string) bool {
+	return code != "" && filepath.IsLocal(filepath.FromSlash(code))
+}
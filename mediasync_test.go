@@ -0,0 +1,92 @@
+package goakeneo
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaSync_Run(t *testing.T) {
+	c, srv := newFakeClient(t)
+	sync := NewMediaSync(c, t.TempDir())
+	jpeg := "1/3/e/d/13ed17a77f6ff8748758083641d3a33e4c651d7e_0______.jpeg"
+	png := "b/a/7/9/ba795607155860d543ab1d1f97a91a0dba7d98a8_____________.png"
+
+	report, err := sync.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Referenced)
+	assert.Equal(t, []string{jpeg, png}, report.Downloaded)
+	assert.Empty(t, report.Skipped)
+	assert.Empty(t, report.Failed)
+	assert.Empty(t, report.Orphans)
+	content, err := os.ReadFile(sync.Path(jpeg))
+	assert.NoError(t, err)
+	expected, _ := srv.MediaContent(jpeg)
+	assert.Equal(t, expected, content)
+
+	// a second run only reports the cached files, and the files no product references anymore
+	orphan := "0/0/0/0/0000000000000000000000000000000000000000_old.png"
+	assert.NoError(t, os.MkdirAll(filepath.Dir(sync.Path(orphan)), 0755))
+	assert.NoError(t, os.WriteFile(sync.Path(orphan), []byte("old"), 0644))
+	report, err = sync.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, report.Downloaded)
+	assert.Equal(t, []string{jpeg, png}, report.Skipped)
+	assert.Equal(t, []string{orphan}, report.Orphans)
+}
+
+func TestMediaSync_RunMissingMedia(t *testing.T) {
+	c, srv := newFakeClient(t)
+	png := "b/a/7/9/ba795607155860d543ab1d1f97a91a0dba7d98a8_____________.png"
+	srv.Delete("media-files", png)
+	sync := NewMediaSync(c, t.TempDir())
+	sync.Concurrency = 1
+
+	report, err := sync.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, report.Downloaded, 1)
+	assert.Contains(t, report.Failed, png)
+	_, err = os.Stat(sync.Path(png))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// cancellingMedia cancels the context of the synchronization before each download
+type cancellingMedia struct {
+	MediaFileService
+	cancel context.CancelFunc
+}
+
+func (m cancellingMedia) DownloadTo(ctx context.Context, code string, w io.Writer) error {
+	m.cancel()
+	return m.MediaFileService.DownloadTo(ctx, code, w)
+}
+
+func TestMediaSync_RunCancelled(t *testing.T) {
+	c, _ := newFakeClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.MediaFile = cancellingMedia{MediaFileService: c.MediaFile, cancel: cancel}
+	sync := NewMediaSync(c, t.TempDir())
+	sync.Concurrency = 1
+
+	report, err := sync.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, report.Downloaded)
+	assert.NotEmpty(t, report.Failed)
+	for code, err := range report.Failed {
+		assert.ErrorIs(t, err, context.Canceled, code)
+	}
+	files := 0
+	assert.NoError(t, filepath.WalkDir(sync.CacheDir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+		return err
+	}))
+	assert.Zero(t, files, "no partial file is left in the cache")
+}
@@ -0,0 +1,218 @@
+package goakeneo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets are the default histogram buckets in seconds, the same as the Prometheus client
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics is an Instrumentation collecting Prometheus style metrics:
+//
+//	akeneo_request_duration_seconds histogram by method and endpoint
+//	akeneo_requests_total counter by method, endpoint and status code
+//	akeneo_request_retries_total counter by method and endpoint
+//	akeneo_rate_limit_wait_seconds histogram
+//	akeneo_token_grants_total counter by grant type and result
+//
+// Metrics implements http.Handler to be exposed as a scrape endpoint
+type Metrics struct {
+	mu            sync.Mutex
+	buckets       []float64
+	durations     map[string]*histogram // key: method, endpoint
+	requests      map[string]uint64     // key: method, endpoint, status
+	retries       map[string]uint64     // key: method, endpoint
+	rateLimitWait *histogram
+	grants        map[string]uint64 // key: grant type, result
+}
+
+// NewMetrics creates a new Metrics, DefaultMetricsBuckets are used when no buckets are given
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{
+		buckets:       buckets,
+		durations:     make(map[string]*histogram),
+		requests:      make(map[string]uint64),
+		retries:       make(map[string]uint64),
+		rateLimitWait: newHistogram(buckets),
+		grants:        make(map[string]uint64),
+	}
+}
+
+// OnRequestStart implements Instrumentation
+func (m *Metrics) OnRequestStart(ctx context.Context, _ RequestInfo) context.Context {
+	return ctx
+}
+
+// OnRequestEnd implements Instrumentation
+func (m *Metrics) OnRequestEnd(_ context.Context, info RequestInfo, result RequestResult) {
+	status := "error"
+	if result.StatusCode != 0 {
+		status = strconv.Itoa(result.StatusCode)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := labels("method", info.Method, "endpoint", info.Endpoint)
+	h, ok := m.durations[key]
+	if !ok {
+		h = newHistogram(m.buckets)
+		m.durations[key] = h
+	}
+	h.observe(result.Duration.Seconds())
+	m.requests[labels("method", info.Method, "endpoint", info.Endpoint, "status", status)]++
+	m.rateLimitWait.observe(result.RateLimitWait.Seconds())
+}
+
+// OnRetry implements Instrumentation
+func (m *Metrics) OnRetry(_ context.Context, info RequestInfo, _ int, _ int, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[labels("method", info.Method, "endpoint", info.Endpoint)]++
+}
+
+// OnAuth implements Instrumentation
+func (m *Metrics) OnAuth(grantType string, _ time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.grants[labels("grant_type", grantType, "result", result)]++
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	cw.header("akeneo_request_duration_seconds", "histogram", "Duration of the akeneo API requests including retries.")
+	for _, key := range sortedKeys(m.durations) {
+		m.durations[key].write(cw, "akeneo_request_duration_seconds", key)
+	}
+	cw.header("akeneo_requests_total", "counter", "Number of akeneo API requests by status code.")
+	cw.counters("akeneo_requests_total", m.requests)
+	cw.header("akeneo_request_retries_total", "counter", "Number of retried akeneo API requests.")
+	cw.counters("akeneo_request_retries_total", m.retries)
+	cw.header("akeneo_rate_limit_wait_seconds", "histogram", "Time spent waiting for the rate limiter.")
+	m.rateLimitWait.write(cw, "akeneo_rate_limit_wait_seconds", "")
+	cw.header("akeneo_token_grants_total", "counter", "Number of token grants by grant type and result.")
+	cw.counters("akeneo_token_grants_total", m.grants)
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP implements http.Handler
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(cw *countingWriter, name, key string) {
+	sep := ""
+	if key != "" {
+		sep = ","
+	}
+	for i, b := range h.buckets {
+		cw.printf("%s_bucket{%s%sle=\"%s\"} %d\n", name, key, sep, formatFloat(b), h.counts[i])
+	}
+	cw.printf("%s_bucket{%s%sle=\"+Inf\"} %d\n", name, key, sep, h.count)
+	cw.printf("%s_sum%s %s\n", name, braces(key), formatFloat(h.sum))
+	cw.printf("%s_count%s %d\n", name, braces(key), h.count)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) printf(format string, args ...any) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) header(name, typ, help string) {
+	cw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (cw *countingWriter) counters(name string, values map[string]uint64) {
+	for _, key := range sortedKeys(values) {
+		cw.printf("%s%s %d\n", name, braces(key), values[key])
+	}
+}
+
+// labels formats label pairs, i.e. method="GET",endpoint="/api/rest/v1/products"
+func labels(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+func braces(key string) string {
+	if key == "" {
+		return ""
+	}
+	return "{" + key + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
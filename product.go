@@ -3,6 +3,7 @@ package goakeneo
 import (
 	"context"
 	"path"
+	"sort"
 
 	"github.com/pkg/errors"
 )
@@ -21,6 +22,12 @@ type ProductService interface {
 	UpdateOrCreateProducts(products []Product) (PatchProductResponse, error)
 }
 
+// ProductPatcher is implemented by the product services which can send product patches,
+// see Client.PatchProducts
+type ProductPatcher interface {
+	PatchProducts(patches []ProductPatch) (PatchProductResponse, error)
+}
+
 type productOp struct {
 	client *Client
 }
@@ -100,17 +107,66 @@ func (p *productOp) GetProduct(id string, options any) (*Product, error) {
 	return product, nil
 }
 
+// UpdateOrCreateProducts updates or creates several products at once,
+// the response holds the status of each product
 func (p *productOp) UpdateOrCreateProducts(products []Product) (PatchProductResponse, error) {
-	result := new(PatchProductResponse)
-	if err := p.client.PATCH(
-		productBasePath,
-		nil,
-		products,
-		result,
-	); err != nil {
-		return nil, err
+	result, err := p.client.patchCollection(productBasePath, products)
+	if err != nil {
+		return nil, errors.Wrap(err, "PATCH error")
+	}
+	return result, nil
+}
+
+// PatchProducts sends product patches such as those of DiffProducts with the product service,
+// unlike UpdateOrCreateProducts it can clear values and properties.
+// The product service must implement ProductPatcher, the default one does
+func (c *Client) PatchProducts(patches []ProductPatch) (PatchProductResponse, error) {
+	patcher, ok := c.Product.(ProductPatcher)
+	if !ok {
+		return nil, errors.Errorf("the product service %T can not patch products", c.Product)
+	}
+	return patcher.PatchProducts(patches)
+}
+
+// PatchProducts sends the patches keyed by identifier to the products endpoint and those keyed by uuid
+// to the products-uuid endpoint, in requests of MaxPatchBatchSize patches. The lines of the response are those of patches
+func (p *productOp) PatchProducts(patches []ProductPatch) (PatchProductResponse, error) {
+	var byIdentifier, byUUID []ProductPatch
+	var identifierLines, uuidLines []int
+	for i, patch := range patches {
+		if patch.ByUUID() {
+			byUUID = append(byUUID, patch)
+			uuidLines = append(uuidLines, i+1)
+		} else {
+			byIdentifier = append(byIdentifier, patch)
+			identifierLines = append(identifierLines, i+1)
+		}
+	}
+	result := make(PatchProductResponse, 0, len(patches))
+	for _, batch := range []struct {
+		path    string
+		patches []ProductPatch
+		lines   []int
+	}{
+		{productBasePath, byIdentifier, identifierLines},
+		{productUUIDBasePath, byUUID, uuidLines},
+	} {
+		for start := 0; start < len(batch.patches); start += MaxPatchBatchSize {
+			end := min(start+MaxPatchBatchSize, len(batch.patches))
+			lines, err := p.client.patchCollection(batch.path, batch.patches[start:end])
+			if err != nil {
+				return nil, errors.Wrap(err, "PATCH error")
+			}
+			for _, line := range lines {
+				if line.Line >= 1 && line.Line <= end-start {
+					line.Line = batch.lines[start+line.Line-1]
+				}
+				result = append(result, line)
+			}
+		}
 	}
-	return *result, nil
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Line < result[j].Line })
+	return result, nil
 }
 
 // ProductsResponse is the struct for an akeneo products response
@@ -127,6 +183,7 @@ type productItems struct {
 type PatchProductResponseLine struct {
 	Line       int    `json:"line,omitempty" mapstructure:"line"`
 	Identifier string `json:"identifier,omitempty" mapstructure:"identifier"`
+	UUID       string `json:"uuid,omitempty" mapstructure:"uuid"` // UUID is set for the lines of the products-uuid endpoint
 	Code       string `json:"code,omitempty" mapstructure:"code"`
 	StatusCode int    `json:"status_code,omitempty" mapstructure:"status_code"`
 	Message    string `json:"message,omitempty" mapstructure:"message"`
@@ -9,7 +9,7 @@ import (
 )
 
 func TestProducts(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	code := strings.ToLower("CODE-A90521134-6R948KM3PCWXNVDY")
 	p, err := c.Product.GetProduct(code, nil)
 	assert.NoError(t, err)
@@ -29,7 +29,7 @@ func TestProducts(t *testing.T) {
 }
 
 func TestProductOp_GetAllProducts(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	prodChan, errChan := c.Product.GetAllProducts(context.Background(), nil)
 	go func() {
 		for err := range errChan {
@@ -47,3 +47,26 @@ func TestProductOp_GetAllProducts(t *testing.T) {
 
 	}
 }
+
+func TestProductOp_UpdateOrCreateProducts(t *testing.T) {
+	c, srv := newFakeClient(t)
+	resp, err := c.Product.UpdateOrCreateProducts([]Product{
+		{Identifier: "code-9200-eprcg", Values: map[string][]ProductValue{
+			"name": {{Locale: "en_US", Data: "Renamed city shoe"}},
+		}},
+		{Identifier: "new-product", Family: "accessories", Enabled: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, PatchProductResponse{
+		{Line: 1, Identifier: "code-9200-eprcg", StatusCode: 204},
+		{Line: 2, Identifier: "new-product", StatusCode: 201},
+	}, resp)
+
+	p, err := c.Product.GetProduct("code-9200-eprcg", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed city shoe", p.Values["name"][0].Data)
+	// values which are not in the patch are kept
+	assert.Equal(t, "spu-9200", p.Values["<spu>"][0].Data)
+	_, ok := srv.Get("products", "new-product")
+	assert.True(t, ok)
+}
@@ -0,0 +1,309 @@
+package goakeneo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// searchDateLayout is the layout of the dates in the search filters
+const searchDateLayout = "2006-01-02 15:04:05"
+
+const (
+	defaultExportConcurrency = 4
+	defaultExportPageSize    = 100
+)
+
+// ProductPartition is a part of the catalog listed by one worker of a parallel export
+type ProductPartition struct {
+	Name   string
+	Filter SearchFilter
+}
+
+// PartitionByFamily returns one partition per family
+func PartitionByFamily(families ...string) []ProductPartition {
+	partitions := make([]ProductPartition, 0, len(families))
+	for _, family := range families {
+		sf := make(SearchFilter)
+		sf.Add("family", "IN", []string{family})
+		partitions = append(partitions, ProductPartition{Name: "family:" + family, Filter: sf})
+	}
+	return partitions
+}
+
+// PartitionByCategory returns one partition per category,
+// a product classified in several categories is listed by several partitions
+func PartitionByCategory(categories ...string) []ProductPartition {
+	partitions := make([]ProductPartition, 0, len(categories))
+	for _, category := range categories {
+		sf := make(SearchFilter)
+		sf.Add("categories", "IN", []string{category})
+		partitions = append(partitions, ProductPartition{Name: "category:" + category, Filter: sf})
+	}
+	return partitions
+}
+
+// PartitionByUpdated splits [from, to] in n ranges of the updated date,
+// two more partitions list the products updated before from and after to so the whole catalog is covered
+func PartitionByUpdated(from, to time.Time, n int) []ProductPartition {
+	if n < 1 {
+		n = 1
+	}
+	format := func(t time.Time) string {
+		return t.UTC().Format(searchDateLayout)
+	}
+	step := to.Sub(from) / time.Duration(n)
+	partitions := make([]ProductPartition, 0, n+2)
+	before := make(SearchFilter)
+	before.Add("updated", "<", format(from))
+	partitions = append(partitions, ProductPartition{Name: "updated:<" + format(from), Filter: before})
+	for i := 0; i < n; i++ {
+		start, end := from.Add(step*time.Duration(i)), from.Add(step*time.Duration(i+1))
+		if i == n-1 {
+			end = to
+		}
+		sf := make(SearchFilter)
+		sf.Add("updated", "BETWEEN", []string{format(start), format(end)})
+		partitions = append(partitions, ProductPartition{Name: fmt.Sprintf("updated:%s..%s", format(start), format(end)), Filter: sf})
+	}
+	after := make(SearchFilter)
+	after.Add("updated", ">", format(to))
+	partitions = append(partitions, ProductPartition{Name: "updated:>" + format(to), Filter: after})
+	return partitions
+}
+
+// ParallelExportOptions are the options of a parallel product export
+type ParallelExportOptions struct {
+	Partitions  []ProductPartition // Partitions are listed concurrently, at least one is required
+	Filter      SearchFilter       // Filter is added to the filter of every partition
+	Options     any                // Options such as ProductListOptions are added to every list request, their search is ignored
+	Concurrency int                // Concurrency is the number of partitions listed at once, 4 by default
+	Ordered     bool               // Ordered emits the partitions one after the other in the given order
+}
+
+// ExportProducts lists the partitions concurrently under the rate limiter of the client and merges them in one stream,
+// a product listed by several partitions is emitted once. The products channel is closed at the end,
+// the first error stops the export and is sent to the error channel, which is closed afterwards
+func (c *Client) ExportProducts(ctx context.Context, opts ParallelExportOptions) (<-chan Product, <-chan error) {
+	out := make(chan Product, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(errChan)
+		defer close(out)
+		if err := c.exportProducts(ctx, opts, out); err != nil {
+			errChan <- err
+		}
+	}()
+	return out, errChan
+}
+
+func (c *Client) exportProducts(ctx context.Context, opts ParallelExportOptions, out chan<- Product) error {
+	if len(opts.Partitions) == 0 {
+		return errors.New("at least one partition is required")
+	}
+	base, err := exportBaseOptions(opts.Options)
+	if err != nil {
+		return err
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultExportConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// one channel per partition, the ordered merge drains them one after the other
+	streams := make([]chan Product, len(opts.Partitions))
+	for i := range streams {
+		streams[i] = make(chan Product, defaultExportPageSize)
+	}
+	var (
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				partition := opts.Partitions[i]
+				err := listAll(ctx, partitionOptions(base, opts.Filter, partition.Filter), c.Product.ListWithPagination, func(p Product) error {
+					select {
+					case streams[i] <- p:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				})
+				close(streams[i])
+				if err != nil && ctx.Err() == nil {
+					fail(errors.Wrapf(err, "unable to list partition %s", partition.Name))
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range opts.Partitions {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				// the partitions which never started are empty
+				for _, s := range streams[i:] {
+					close(s)
+				}
+				return
+			}
+		}
+	}()
+
+	seen := make(map[string]struct{})
+	emit := func(p Product) {
+		key := p.UUID
+		if key == "" {
+			key = p.Identifier
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		select {
+		case out <- p:
+		case <-ctx.Done():
+		}
+	}
+	if opts.Ordered {
+		for _, s := range streams {
+			for p := range s {
+				emit(p)
+			}
+		}
+	} else {
+		merged := make(chan Product)
+		var mergeWG sync.WaitGroup
+		for _, s := range streams {
+			mergeWG.Add(1)
+			go func(s chan Product) {
+				defer mergeWG.Done()
+				for p := range s {
+					merged <- p
+				}
+			}(s)
+		}
+		go func() {
+			mergeWG.Wait()
+			close(merged)
+		}()
+		for p := range merged {
+			emit(p)
+		}
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// exportBaseOptions converts the list options of an export to url.Values, the search is set per partition
+func exportBaseOptions(options any) (url.Values, error) {
+	base, err := copyOptionValues(options)
+	if err != nil {
+		return nil, err
+	}
+	base.Del("search")
+	base.Del("page")
+	setSearchAfter(base)
+	return base, nil
+}
+
+// searchAfterOptions converts the list options of products or product models to url.Values
+// listing every page with the search_after pagination, see setSearchAfter
+func searchAfterOptions(options any) (url.Values, error) {
+	values, err := copyOptionValues(options)
+	if err != nil {
+		return nil, err
+	}
+	setSearchAfter(values)
+	return values, nil
+}
+
+// setSearchAfter selects the search_after pagination unless a page is requested, the page pagination
+// of products and product models stops at 10 000 items, and pages of the maximum size by default
+func setSearchAfter(values url.Values) {
+	if values.Get("page") == "" && values.Get("pagination_type") == "" {
+		values.Set("pagination_type", "search_after")
+	}
+	if values.Get("limit") == "" {
+		values.Set("limit", fmt.Sprint(defaultExportPageSize))
+	}
+}
+
+// copyOptionValues converts list options, url.Values or a struct with url tags, to url.Values
+// the caller can modify
+func copyOptionValues(options any) (url.Values, error) {
+	switch o := options.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		values := url.Values{}
+		for k, v := range o {
+			values[k] = append([]string(nil), v...)
+		}
+		return values, nil
+	default:
+		return structToURLValues(options)
+	}
+}
+
+// partitionOptions returns the list options of a partition, filters on the same property are all applied
+func partitionOptions(base url.Values, filters ...SearchFilter) url.Values {
+	sf := make(SearchFilter)
+	for _, f := range filters {
+		for key, conditions := range f {
+			sf[key] = append(sf[key], conditions...)
+		}
+	}
+	options := url.Values{}
+	for k, v := range base {
+		options[k] = v
+	}
+	if len(sf) > 0 {
+		options.Set("search", sf.String())
+	}
+	return options
+}
+
+// listAll calls fn for every item of every page returned by list, starting with options
+func listAll[T any](ctx context.Context, options any, list func(options any) ([]T, Links, error), fn func(T) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		items, links, err := list(options)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if !links.HasNext() {
+			return nil
+		}
+		options = links.NextOptions()
+	}
+}
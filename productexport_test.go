@@ -0,0 +1,160 @@
+package goakeneo
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectExport reads an export until both channels are closed
+func collectExport(prodChan <-chan Product, errChan <-chan error) ([]string, error) {
+	var identifiers []string
+	for p := range prodChan {
+		identifiers = append(identifiers, p.Identifier)
+	}
+	return identifiers, <-errChan
+}
+
+func TestClient_ExportProductsOrdered(t *testing.T) {
+	c, _ := newFakeClient(t)
+	identifiers, err := collectExport(c.ExportProducts(context.Background(), ParallelExportOptions{
+		Partitions: PartitionByFamily("accessories", "shoes"),
+		Options:    ProductListOptions{ListOptions: ListOptions{Limit: 3}},
+		Ordered:    true,
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 12)
+	for i, identifier := range identifiers {
+		// the accessories partition is emitted first
+		assert.Equal(t, i < 8, identifier[:len("accessory")] == "accessory", identifier)
+	}
+}
+
+func TestClient_ExportProductsDeduplicates(t *testing.T) {
+	c, _ := newFakeClient(t)
+	partitions := append(PartitionByCategory("master_shoes", "master_shoes_sneakers"), PartitionByFamily("shoes")...)
+	identifiers, err := collectExport(c.ExportProducts(context.Background(), ParallelExportOptions{
+		Partitions:  partitions,
+		Concurrency: 3,
+	}))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"code-a90521134-6r948km3pcwxnvdy", "code-9200-eprcg", "runner-40", "runner-41"}, identifiers)
+}
+
+func TestClient_ExportProductsFilter(t *testing.T) {
+	c, _ := newFakeClient(t)
+	from := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)
+	filter := make(SearchFilter)
+	filter.Add("family", "IN", []string{"accessories"})
+	identifiers, err := collectExport(c.ExportProducts(context.Background(), ParallelExportOptions{
+		Partitions: PartitionByUpdated(from, to, 2),
+		Filter:     filter,
+		Ordered:    true,
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 8)
+	assert.Equal(t, "accessory-01", identifiers[0])
+	// the last partition holds the products updated after to
+	assert.ElementsMatch(t, []string{"accessory-06", "accessory-07", "accessory-08"}, identifiers[5:])
+}
+
+func TestClient_ExportProductsError(t *testing.T) {
+	c, _ := newFakeClient(t)
+	invalid := make(SearchFilter)
+	invalid.Add("family", "UNKNOWN OPERATOR", "shoes")
+	_, err := collectExport(c.ExportProducts(context.Background(), ParallelExportOptions{
+		Partitions: append(PartitionByFamily("accessories"), ProductPartition{Name: "invalid", Filter: invalid}),
+	}))
+	assert.ErrorContains(t, err, "unable to list partition invalid")
+}
+
+func TestPartitionByUpdated(t *testing.T) {
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	partitions := PartitionByUpdated(from, from.Add(48*time.Hour), 2)
+	assert.Len(t, partitions, 4)
+	var filters []string
+	for _, p := range partitions {
+		filters = append(filters, p.Filter.String())
+	}
+	assert.Equal(t, []string{
+		`{"updated":[{"operator":"<","value":"2023-01-01 00:00:00"}]}`,
+		`{"updated":[{"operator":"BETWEEN","value":["2023-01-01 00:00:00","2023-01-02 00:00:00"]}]}`,
+		`{"updated":[{"operator":"BETWEEN","value":["2023-01-02 00:00:00","2023-01-03 00:00:00"]}]}`,
+		`{"updated":[{"operator":">","value":"2023-01-03 00:00:00"}]}`,
+	}, filters)
+}
+
+func TestPartitionOptions(t *testing.T) {
+	base, err := exportBaseOptions(ProductListOptions{Scope: "ecommerce", ListOptions: ListOptions{Search: "ignored", Page: 3}})
+	assert.NoError(t, err)
+	family := PartitionByFamily("shoes")[0].Filter
+	enabled := make(SearchFilter)
+	enabled.Add("enabled", "=", true)
+	options := partitionOptions(base, enabled, family)
+	var search SearchFilter
+	assert.NoError(t, json.Unmarshal([]byte(options.Get("search")), &search))
+	assert.Len(t, search, 2)
+	assert.Equal(t, url.Values{"scope": {"ecommerce"}, "limit": {"100"}}, url.Values{"scope": options["scope"], "limit": options["limit"]})
+	assert.Empty(t, options.Get("page"))
+	assert.Equal(t, "search_after", options.Get("pagination_type"))
+}
+
+func TestSearchAfterOptions(t *testing.T) {
+	options, err := searchAfterOptions(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"pagination_type": {"search_after"}, "limit": {"100"}}, options)
+	options, err = searchAfterOptions(ProductListOptions{ListOptions: ListOptions{Limit: 20}})
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"pagination_type": {"search_after"}, "limit": {"20"}}, options)
+	// an explicit page keeps the page pagination
+	options, err = searchAfterOptions(url.Values{"page": {"2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"page": {"2"}, "limit": {"100"}}, options)
+}
+
+func TestClient_ExportProductsCancel(t *testing.T) {
+	c, _ := newFakeClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	prodChan, errChan := c.ExportProducts(ctx, ParallelExportOptions{
+		Partitions: PartitionByFamily("accessories", "shoes"),
+		Options:    ListOptions{Limit: 1},
+		Ordered:    true,
+	})
+	<-prodChan
+	cancel()
+	_, err := collectExport(prodChan, errChan)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// authCounter counts the token grants
+type authCounter struct {
+	nopInstrumentation
+	grants atomic.Int32
+}
+
+func (a *authCounter) OnAuth(string, time.Duration, error) {
+	a.grants.Add(1)
+}
+
+func TestClient_ExportProductsRefreshesTokenOnce(t *testing.T) {
+	counter := &authCounter{}
+	c, _ := newFakeClient(t, WithInstrumentation(counter), WithRateLimit(1000, time.Second))
+	auth := c.Auth.(*authOp)
+	auth.mu.Lock()
+	auth.tokenExp = time.Time{} // the workers all find an expired token
+	auth.mu.Unlock()
+	identifiers, err := collectExport(c.ExportProducts(context.Background(), ParallelExportOptions{
+		Partitions:  PartitionByFamily("accessories", "shoes", "accessories", "shoes"),
+		Options:     ProductListOptions{ListOptions: ListOptions{Limit: 2}},
+		Concurrency: 4,
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 12)
+	assert.Equal(t, int32(2), counter.grants.Load(), "one grant by NewClient and a single refresh")
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func TestGetProductModel(t *testing.T) {
-	c := MockDLClient()
+	c, _ := newFakeClient(t)
 	pms, links, err := c.ProductModel.ListWithPagination(nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, pms)
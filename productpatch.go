@@ -0,0 +1,394 @@
+package goakeneo
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ProductPatch is the body of a product PATCH request, only the changed properties are set.
+// Unlike Product it holds explicit nulls, i.e. the data of a cleared value or a removed family
+type ProductPatch map[string]any
+
+// Empty reports whether the patch changes nothing, the key of the product is always set:
+// the identifier, or the uuid with the identifier when the identifier changes
+func (p ProductPatch) Empty() bool {
+	_, byUUID := p["uuid"]
+	for k := range p {
+		if k != "uuid" && (k != "identifier" || byUUID) {
+			return false
+		}
+	}
+	return true
+}
+
+// ByUUID reports whether the patch is keyed by the uuid of the product, it is sent to the products-uuid endpoint
+func (p ProductPatch) ByUUID() bool {
+	_, ok := p["uuid"]
+	return ok
+}
+
+// DiffProducts returns the minimal patch turning old into new: the changed properties,
+// the changed values per locale and scope with a null data for the removed ones,
+// the new categories and groups when they changed and the changed association lists.
+// The patch is keyed by the identifier of old, or by its uuid when the identifier changes,
+// an identifier can only be changed through the uuid of the product
+func DiffProducts(old, new Product) ProductPatch {
+	patch := make(ProductPatch)
+	switch {
+	case old.Identifier != "" && (old.Identifier == new.Identifier || old.UUID == ""):
+		patch["identifier"] = old.Identifier
+	case old.UUID != "":
+		patch["uuid"] = old.UUID
+		if old.Identifier != new.Identifier {
+			patch["identifier"] = nullable(new.Identifier)
+		}
+	case new.Identifier != "":
+		patch["identifier"] = new.Identifier
+	default:
+		patch["uuid"] = new.UUID
+	}
+	if old.Enabled != new.Enabled {
+		patch["enabled"] = new.Enabled
+	}
+	if old.Family != new.Family {
+		patch["family"] = nullable(new.Family)
+	}
+	if old.Parent != new.Parent {
+		patch["parent"] = nullable(new.Parent)
+	}
+	if !sameSet(old.Categories, new.Categories) {
+		patch["categories"] = nonNil(new.Categories)
+	}
+	if !sameSet(old.Groups, new.Groups) {
+		patch["groups"] = nonNil(new.Groups)
+	}
+	if values := diffValues(old.Values, new.Values); len(values) > 0 {
+		patch["values"] = values
+	}
+	if associations := diffAssociations(old.Associations, new.Associations); len(associations) > 0 {
+		patch["associations"] = associations
+	}
+	if quantified := diffQuantifiedAssociations(old.QuantifiedAssociations, new.QuantifiedAssociations); len(quantified) > 0 {
+		patch["quantified_associations"] = quantified
+	}
+	return patch
+}
+
+// diffValues returns the changed values by attribute
+func diffValues(old, new map[string][]ProductValue) map[string][]map[string]any {
+	changed := make(map[string][]map[string]any)
+	for _, attribute := range unionKeys(old, new) {
+		previous := make(map[[2]string]ProductValue, len(old[attribute]))
+		for _, v := range old[attribute] {
+			previous[[2]string{v.Locale, v.Scope}] = v
+		}
+		var values []map[string]any
+		for _, v := range new[attribute] {
+			key := [2]string{v.Locale, v.Scope}
+			o, ok := previous[key]
+			delete(previous, key)
+			if ok && jsonEqual(o.Data, v.Data) {
+				continue
+			}
+			if !ok && v.Data == nil {
+				continue
+			}
+			values = append(values, patchValue(v.Locale, v.Scope, v.Data))
+		}
+		for _, v := range old[attribute] {
+			if _, removed := previous[[2]string{v.Locale, v.Scope}]; removed && v.Data != nil {
+				values = append(values, patchValue(v.Locale, v.Scope, nil))
+			}
+		}
+		if len(values) > 0 {
+			changed[attribute] = values
+		}
+	}
+	return changed
+}
+
+// patchValue keeps the null locale, scope and data which are omitted by ProductValue
+func patchValue(locale, scope string, data any) map[string]any {
+	return map[string]any{"locale": nullable(locale), "scope": nullable(scope), "data": data}
+}
+
+// diffAssociations returns the changed lists by association type, a removed list is sent empty
+func diffAssociations(old, new map[string]Association) map[string]map[string][]string {
+	changed := make(map[string]map[string][]string)
+	for _, kind := range unionKeys(old, new) {
+		o, n := old[kind], new[kind]
+		lists := make(map[string][]string)
+		if !sameSet(o.Groups, n.Groups) {
+			lists["groups"] = nonNil(n.Groups)
+		}
+		if !sameSet(o.Products, n.Products) {
+			lists["products"] = nonNil(n.Products)
+		}
+		if !sameSet(o.ProductModels, n.ProductModels) {
+			lists["product_models"] = nonNil(n.ProductModels)
+		}
+		if len(lists) > 0 {
+			changed[kind] = lists
+		}
+	}
+	return changed
+}
+
+// diffQuantifiedAssociations returns the changed lists by quantified association type
+func diffQuantifiedAssociations(old, new map[string]QuantifiedAssociation) map[string]map[string]any {
+	changed := make(map[string]map[string]any)
+	for _, kind := range unionKeys(old, new) {
+		o, n := old[kind], new[kind]
+		lists := make(map[string]any)
+		if !sameQuantities(o.Products, n.Products, func(q ProductQuantity) string { return q.Identifier }) {
+			lists["products"] = nonNil(n.Products)
+		}
+		if !sameQuantities(o.ProductModels, n.ProductModels, func(q ProductModelQuantity) string { return q.Code }) {
+			lists["product_models"] = nonNil(n.ProductModels)
+		}
+		if len(lists) > 0 {
+			changed[kind] = lists
+		}
+	}
+	return changed
+}
+
+// nullable returns nil for the empty string, which the PIM expects as null
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nonNil returns an empty list instead of nil, which the PIM expects to clear a list
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// sameSet reports whether two lists hold the same codes in any order
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		if counts[s] == 0 {
+			return false
+		}
+		counts[s]--
+	}
+	return true
+}
+
+func sameQuantities[T comparable](a, b []T, key func(T) string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byKey := make(map[string]T, len(a))
+	for _, q := range a {
+		byKey[key(q)] = q
+	}
+	for _, q := range b {
+		if o, ok := byKey[key(q)]; !ok || o != q {
+			return false
+		}
+	}
+	return true
+}
+
+// unionKeys returns the keys of two maps sorted
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonEqual compares two values as JSON so decoded and hand built data are equal,
+// i.e. float64(1) and 1 or []any{"a"} and []string{"a"}
+func jsonEqual(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ab, errA := json.Marshal(a)
+	bb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	var av, bv any
+	if json.Unmarshal(ab, &av) != nil || json.Unmarshal(bb, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// ProductPatchBuilder changes a copy of a product and builds the patch of the changes
+type ProductPatchBuilder struct {
+	original Product
+	product  Product
+}
+
+// Patch returns a builder of a patch of the product
+func (p Product) Patch() *ProductPatchBuilder {
+	return &ProductPatchBuilder{original: p, product: cloneProduct(p)}
+}
+
+// SetEnabled enables or disables the product
+func (b *ProductPatchBuilder) SetEnabled(enabled bool) *ProductPatchBuilder {
+	b.product.Enabled = enabled
+	return b
+}
+
+// SetFamily sets the family, the empty string removes it
+func (b *ProductPatchBuilder) SetFamily(family string) *ProductPatchBuilder {
+	b.product.Family = family
+	return b
+}
+
+// SetParent sets the parent product model, the empty string removes it
+func (b *ProductPatchBuilder) SetParent(parent string) *ProductPatchBuilder {
+	b.product.Parent = parent
+	return b
+}
+
+// SetValue sets the data of a value, locale and scope are empty for a value neither localizable nor scopable
+func (b *ProductPatchBuilder) SetValue(attribute, locale, scope string, data any) *ProductPatchBuilder {
+	if data == nil {
+		return b.ClearValue(attribute, locale, scope)
+	}
+	if b.product.Values == nil {
+		b.product.Values = make(map[string][]ProductValue)
+	}
+	values := b.product.Values[attribute]
+	for i, v := range values {
+		if v.Locale == locale && v.Scope == scope {
+			values[i].Data = data
+			return b
+		}
+	}
+	b.product.Values[attribute] = append(values, ProductValue{Locale: locale, Scope: scope, Data: data})
+	return b
+}
+
+// ClearValue removes a value, the patch sends a null data
+func (b *ProductPatchBuilder) ClearValue(attribute, locale, scope string) *ProductPatchBuilder {
+	var kept []ProductValue
+	for _, v := range b.product.Values[attribute] {
+		if v.Locale != locale || v.Scope != scope {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		delete(b.product.Values, attribute)
+	} else {
+		b.product.Values[attribute] = kept
+	}
+	return b
+}
+
+// AddCategories classifies the product in categories
+func (b *ProductPatchBuilder) AddCategories(codes ...string) *ProductPatchBuilder {
+	b.product.Categories = addCodes(b.product.Categories, codes)
+	return b
+}
+
+// RemoveCategories removes the product from categories
+func (b *ProductPatchBuilder) RemoveCategories(codes ...string) *ProductPatchBuilder {
+	b.product.Categories = removeCodes(b.product.Categories, codes)
+	return b
+}
+
+// Associate associates products to the product with an association type, i.e. "X_SELL"
+func (b *ProductPatchBuilder) Associate(associationType string, identifiers ...string) *ProductPatchBuilder {
+	if b.product.Associations == nil {
+		b.product.Associations = make(map[string]Association)
+	}
+	a := b.product.Associations[associationType]
+	a.Products = addCodes(a.Products, identifiers)
+	b.product.Associations[associationType] = a
+	return b
+}
+
+// Dissociate removes associated products of an association type
+func (b *ProductPatchBuilder) Dissociate(associationType string, identifiers ...string) *ProductPatchBuilder {
+	a, ok := b.product.Associations[associationType]
+	if !ok {
+		return b
+	}
+	a.Products = removeCodes(a.Products, identifiers)
+	b.product.Associations[associationType] = a
+	return b
+}
+
+// Product returns the changed product
+func (b *ProductPatchBuilder) Product() Product {
+	return cloneProduct(b.product)
+}
+
+// Build returns the patch of the changes, see DiffProducts
+func (b *ProductPatchBuilder) Build() ProductPatch {
+	return DiffProducts(b.original, b.product)
+}
+
+func addCodes(list, codes []string) []string {
+	for _, code := range codes {
+		found := false
+		for _, c := range list {
+			found = found || c == code
+		}
+		if !found {
+			list = append(list, code)
+		}
+	}
+	return list
+}
+
+func removeCodes(list, codes []string) []string {
+	kept := make([]string, 0, len(list))
+	for _, c := range list {
+		removed := false
+		for _, code := range codes {
+			removed = removed || c == code
+		}
+		if !removed {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// cloneProduct copies the lists and maps changed by ProductPatchBuilder
+func cloneProduct(p Product) Product {
+	p.Categories = append([]string(nil), p.Categories...)
+	if p.Values != nil {
+		values := make(map[string][]ProductValue, len(p.Values))
+		for attribute, list := range p.Values {
+			values[attribute] = append([]ProductValue(nil), list...)
+		}
+		p.Values = values
+	}
+	if p.Associations != nil {
+		associations := make(map[string]Association, len(p.Associations))
+		for kind, a := range p.Associations {
+			a.Products = append([]string(nil), a.Products...)
+			associations[kind] = a
+		}
+		p.Associations = associations
+	}
+	return p
+}
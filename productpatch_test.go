@@ -0,0 +1,159 @@
+package goakeneo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffProducts(t *testing.T) {
+	old := Product{
+		Identifier: "sku-1",
+		Enabled:    true,
+		Family:     "shoes",
+		Categories: []string{"master", "sale"},
+		Values: map[string][]ProductValue{
+			"name":   {{Locale: "en_US", Data: "Sneaker"}, {Locale: "fr_FR", Data: "Basket"}},
+			"weight": {{Data: map[string]any{"amount": float64(1), "unit": "KILOGRAM"}}},
+			"color":  {{Data: "red"}},
+		},
+		Associations: map[string]Association{"X_SELL": {Products: []string{"sku-2", "sku-3"}, Groups: []string{"promo"}}},
+	}
+	new := Product{
+		Identifier: "sku-1",
+		Enabled:    true,
+		Categories: []string{"sale", "master", "new"},
+		Values: map[string][]ProductValue{
+			"name":   {{Locale: "fr_FR", Data: "Basket"}, {Locale: "en_US", Data: "Runner"}},
+			"weight": {{Data: map[string]any{"amount": 1, "unit": "KILOGRAM"}}},
+			"size":   {{Scope: "ecommerce", Data: "size_40"}},
+		},
+		Associations: map[string]Association{"X_SELL": {Products: []string{"sku-3", "sku-2"}}},
+	}
+	patch := DiffProducts(old, new)
+	b, err := json.Marshal(patch)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"identifier": "sku-1",
+		"family": null,
+		"categories": ["sale", "master", "new"],
+		"values": {
+			"name": [{"locale": "en_US", "scope": null, "data": "Runner"}],
+			"size": [{"locale": null, "scope": "ecommerce", "data": "size_40"}],
+			"color": [{"locale": null, "scope": null, "data": null}]
+		},
+		"associations": {"X_SELL": {"groups": []}}
+	}`, string(b))
+	assert.False(t, patch.Empty())
+
+	assert.True(t, DiffProducts(old, old).Empty())
+}
+
+func TestProductPatchBuilder(t *testing.T) {
+	c, _ := newFakeClient(t)
+	p, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	patch := p.Patch().
+		SetValue("name", "en_US", "", "Runner 40").
+		ClearValue("weight", "", "").
+		RemoveCategories("master_shoes_sneakers").
+		AddCategories("master_shoes").
+		Associate("X_SELL", "accessory-01").
+		Build()
+	b, err := json.Marshal(patch)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"identifier": "runner-40",
+		"categories": ["master_shoes"],
+		"values": {
+			"name": [{"locale": "en_US", "scope": null, "data": "Runner 40"}],
+			"weight": [{"locale": null, "scope": null, "data": null}]
+		},
+		"associations": {"X_SELL": {"products": ["accessory-01"]}}
+	}`, string(b))
+
+	resp, err := c.PatchProducts([]ProductPatch{patch})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp[0].StatusCode)
+	updated, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master_shoes"}, updated.Categories)
+	assert.Equal(t, "Runner 40", updated.Values["name"][0].Data)
+	assert.NotContains(t, updated.Values, "weight")
+	assert.Equal(t, "size_40", updated.Values["size"][0].Data)
+	assert.Equal(t, []string{"accessory-01"}, updated.Associations["X_SELL"].Products)
+	// the builder works on a copy
+	assert.Contains(t, p.Values, "weight")
+}
+
+func TestDiffProductsKey(t *testing.T) {
+	old := Product{UUID: "0b7d0f2c-5f1a-4d0a-9f8e-1c2d3e4f5a6b", Identifier: "sku-1", Enabled: true}
+	assert.Equal(t, ProductPatch{"identifier": "sku-1", "enabled": false}, DiffProducts(old, Product{UUID: old.UUID, Enabled: false, Identifier: "sku-1"}))
+	// the identifier is changed through the uuid
+	renamed := old
+	renamed.Identifier = "sku-2"
+	patch := DiffProducts(old, renamed)
+	assert.Equal(t, ProductPatch{"uuid": old.UUID, "identifier": "sku-2"}, patch)
+	assert.True(t, patch.ByUUID())
+	assert.False(t, patch.Empty())
+	assert.Equal(t, ProductPatch{"uuid": old.UUID}, DiffProducts(Product{UUID: old.UUID}, Product{UUID: old.UUID}))
+	assert.True(t, DiffProducts(Product{UUID: old.UUID}, Product{UUID: old.UUID}).Empty())
+}
+
+func TestClient_PatchProductsByUUID(t *testing.T) {
+	c, _ := newFakeClient(t)
+	old, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	if !assert.NotEmpty(t, old.UUID) {
+		return
+	}
+	renamed := cloneProduct(*old)
+	renamed.Identifier = "runner-40-eu"
+	resp, err := c.PatchProducts([]ProductPatch{
+		{"identifier": "runner-41", "enabled": false},
+		DiffProducts(*old, renamed),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, PatchProductResponse{
+		{Line: 1, Identifier: "runner-41", StatusCode: http.StatusNoContent},
+		{Line: 2, UUID: old.UUID, StatusCode: http.StatusNoContent},
+	}, resp)
+	p, err := c.Product.GetProduct("runner-40-eu", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, old.UUID, p.UUID)
+	assert.Equal(t, old.Values["size"], p.Values["size"])
+}
+
+func TestClient_PatchProductsChunked(t *testing.T) {
+	c, _ := newFakeClient(t)
+	old, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	renamed := cloneProduct(*old)
+	renamed.Identifier = "runner-40-eu"
+	// more patches than a request accepts, with one keyed by uuid in the middle
+	var patches []ProductPatch
+	for i := 0; i < 2*MaxPatchBatchSize+10; i++ {
+		if i == MaxPatchBatchSize+5 {
+			patches = append(patches, DiffProducts(*old, renamed))
+			continue
+		}
+		patches = append(patches, ProductPatch{"identifier": fmt.Sprintf("bulk-%03d", i), "family": "shoes"})
+	}
+	resp, err := c.PatchProducts(patches)
+	assert.NoError(t, err)
+	if assert.Len(t, resp, len(patches)) {
+		for i, line := range resp {
+			assert.Equal(t, i+1, line.Line)
+			if i == MaxPatchBatchSize+5 {
+				assert.Equal(t, old.UUID, line.UUID)
+				assert.Equal(t, http.StatusNoContent, line.StatusCode)
+				continue
+			}
+			assert.Equal(t, fmt.Sprintf("bulk-%03d", i), line.Identifier)
+			assert.Equal(t, http.StatusCreated, line.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package goakeneo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const identifierAttributeType = "pim_catalog_identifier"
+
+// SnapshotStore persists the keys of the items of each reconciled resource
+type SnapshotStore interface {
+	// LoadSnapshot returns the keys of a resource, ok is false when the resource was never reconciled
+	LoadSnapshot(resource string) (keys []string, ok bool, err error)
+	// SaveSnapshot stores the keys of a resource
+	SaveSnapshot(resource string, keys []string) error
+}
+
+// MemorySnapshotStore keeps the snapshots in memory
+type MemorySnapshotStore struct {
+	mu        sync.Mutex
+	snapshots map[string][]string
+}
+
+// NewMemorySnapshotStore returns an empty memory store
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{snapshots: make(map[string][]string)}
+}
+
+// LoadSnapshot implements SnapshotStore
+func (s *MemorySnapshotStore) LoadSnapshot(resource string) ([]string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys, ok := s.snapshots[resource]
+	return append([]string(nil), keys...), ok, nil
+}
+
+// SaveSnapshot implements SnapshotStore
+func (s *MemorySnapshotStore) SaveSnapshot(resource string, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[resource] = append([]string(nil), keys...)
+	return nil
+}
+
+// FileSnapshotStore keeps one file per resource in a directory, with one key per line
+type FileSnapshotStore struct {
+	dir string
+}
+
+// NewFileSnapshotStore returns a store writing the snapshots to dir
+func NewFileSnapshotStore(dir string) *FileSnapshotStore {
+	return &FileSnapshotStore{dir: dir}
+}
+
+func (s *FileSnapshotStore) path(resource string) string {
+	return filepath.Join(s.dir, resource+".txt")
+}
+
+// LoadSnapshot implements SnapshotStore
+func (s *FileSnapshotStore) LoadSnapshot(resource string) ([]string, bool, error) {
+	b, err := os.ReadFile(s.path(resource))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "unable to read snapshot of %s", resource)
+	}
+	keys := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(keys) == 1 && keys[0] == "" {
+		keys = nil
+	}
+	return keys, true, nil
+}
+
+// SaveSnapshot implements SnapshotStore
+func (s *FileSnapshotStore) SaveSnapshot(resource string, keys []string) error {
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte('\n')
+	}
+	return writeFileAtomic(s.path(resource), []byte(b.String()))
+}
+
+// Reconciler detects the items deleted from the PIM, which the list endpoints never report,
+// by comparing the keys listed now with the snapshot of the previous run
+type Reconciler struct {
+	client *Client
+	store  SnapshotStore
+	// ProductAttributes restricts the values returned while listing the products and the product models,
+	// the identifier attribute is looked up and used when it is empty
+	ProductAttributes string
+}
+
+// Deletions is the result of one reconciliation, keys are sorted
+type Deletions struct {
+	Resource string
+	FirstRun bool     // FirstRun is true when there was no snapshot to compare with
+	Current  int      // Current is the number of items in the PIM
+	Deleted  []string // Deleted are the keys of the snapshot which are not in the PIM anymore
+}
+
+// NewReconciler returns a reconciler of the client persisting its snapshots in store
+func NewReconciler(c *Client, store SnapshotStore) *Reconciler {
+	return &Reconciler{client: c, store: store}
+}
+
+// Products returns the products deleted since the previous run, products are keyed by UUID,
+// or by identifier when the PIM does not return the UUID
+func (r *Reconciler) Products(ctx context.Context) (Deletions, error) {
+	attributes, err := r.productAttributes()
+	if err != nil {
+		return Deletions{Resource: DeltaResourceProducts}, err
+	}
+	options := ProductListOptions{
+		Attributes:     attributes,
+		PaginationType: "search_after",
+		ListOptions:    ListOptions{Limit: defaultDeltaPageSize},
+	}
+	return reconcile(ctx, r, DeltaResourceProducts, options, r.client.Product.ListWithPagination, func(p Product) string {
+		if p.UUID != "" {
+			return p.UUID
+		}
+		return p.Identifier
+	})
+}
+
+// ProductModels returns the codes of the product models deleted since the previous run
+func (r *Reconciler) ProductModels(ctx context.Context) (Deletions, error) {
+	attributes, err := r.productAttributes()
+	if err != nil {
+		return Deletions{Resource: DeltaResourceProductModels}, err
+	}
+	options := ProductModelListOptions{
+		Attributes:     attributes,
+		PaginationType: "search_after",
+		ListOptions:    ListOptions{Limit: defaultDeltaPageSize},
+	}
+	return reconcile(ctx, r, DeltaResourceProductModels, options, r.client.ProductModel.ListWithPagination,
+		func(pm ProductModel) string { return pm.Code })
+}
+
+// Categories returns the codes of the categories deleted since the previous run
+func (r *Reconciler) Categories(ctx context.Context) (Deletions, error) {
+	return reconcile(ctx, r, DeltaResourceCategories, ListOptions{Limit: defaultDeltaPageSize}, r.client.Category.ListWithPagination,
+		func(c Category) string { return c.Code })
+}
+
+// productAttributes returns ProductAttributes, or looks up the code of the identifier attribute
+func (r *Reconciler) productAttributes() (string, error) {
+	if r.ProductAttributes != "" {
+		return r.ProductAttributes, nil
+	}
+	sf := make(SearchFilter)
+	sf.Add("type", "IN", []string{identifierAttributeType})
+	attributes, _, err := r.client.Attribute.ListWithPagination(ListOptions{Search: sf.String(), Limit: 1})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to find the identifier attribute")
+	}
+	for _, a := range attributes {
+		if a.Type == identifierAttributeType {
+			r.ProductAttributes = a.Code
+		}
+	}
+	return r.ProductAttributes, nil
+}
+
+// reconcile lists the keys of a resource, compares them with the snapshot and saves the new snapshot
+func reconcile[T any](ctx context.Context, r *Reconciler, resource string, options any,
+	list func(options any) ([]T, Links, error), key func(T) string) (Deletions, error) {
+	result := Deletions{Resource: resource}
+	current := make(map[string]struct{})
+	err := listAll(ctx, options, list, func(item T) error {
+		current[key(item)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to list %s", resource)
+	}
+	result.Current = len(current)
+	previous, ok, err := r.store.LoadSnapshot(resource)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to load the snapshot of %s", resource)
+	}
+	result.FirstRun = !ok
+	for _, k := range previous {
+		if _, found := current[k]; !found {
+			result.Deleted = append(result.Deleted, k)
+		}
+	}
+	sort.Strings(result.Deleted)
+	keys := make([]string, 0, len(current))
+	for k := range current {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if err := r.store.SaveSnapshot(resource, keys); err != nil {
+		return result, errors.Wrapf(err, "unable to save the snapshot of %s", resource)
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package goakeneo
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciler_Products(t *testing.T) {
+	c, srv := newFakeClient(t)
+	r := NewReconciler(c, NewFileSnapshotStore(t.TempDir()))
+
+	result, err := r.Products(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, result.FirstRun)
+	assert.Equal(t, 12, result.Current)
+	assert.Empty(t, result.Deleted)
+	assert.Equal(t, "sku", r.ProductAttributes)
+
+	deleted, err := c.Product.GetProduct("accessory-03", nil)
+	assert.NoError(t, err)
+	assert.True(t, srv.Delete("products", "accessory-03"))
+	result, err = r.Products(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.FirstRun)
+	assert.Equal(t, 11, result.Current)
+	assert.Equal(t, []string{deleted.UUID}, result.Deleted)
+
+	// the snapshot was replaced, the deletion is reported once
+	result, err = r.Products(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, result.Deleted)
+}
+
+func TestReconciler_Categories(t *testing.T) {
+	c, srv := newFakeClient(t)
+	r := NewReconciler(c, NewMemorySnapshotStore())
+	_, err := r.Categories(context.Background())
+	assert.NoError(t, err)
+	srv.Delete("categories", "master_accessories")
+	result, err := r.Categories(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master_accessories"}, result.Deleted)
+}
+
+func TestFileSnapshotStore(t *testing.T) {
+	store := NewFileSnapshotStore(filepath.Join(t.TempDir(), "snapshots"))
+	_, ok, err := store.LoadSnapshot(DeltaResourceProductModels)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, store.SaveSnapshot(DeltaResourceProductModels, nil))
+	keys, ok, err := store.LoadSnapshot(DeltaResourceProductModels)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, keys)
+	assert.NoError(t, store.SaveSnapshot(DeltaResourceProductModels, []string{"a", "b"}))
+	keys, _, err = store.LoadSnapshot(DeltaResourceProductModels)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
@@ -0,0 +1,385 @@
+package goakeneo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// RecorderMode is the mode of the record-and-replay transport
+type RecorderMode int
+
+const (
+	// RecorderModeRecord sends the requests to the PIM and writes them to the cassette
+	RecorderModeRecord RecorderMode = iota + 1
+	// RecorderModeReplay answers the requests from the cassette without calling the PIM
+	RecorderModeReplay
+	// RecorderModeReplayOrRecord replays the cassette when it exists and records it otherwise
+	RecorderModeReplayOrRecord
+)
+
+const cassetteVersion = 1
+
+// ErrNotRecorded is returned in replay mode when the cassette has no interaction matching the request
+var ErrNotRecorded = errors.New("recorder: no interaction recorded")
+
+// WithRecorder records the requests and responses of the client to a cassette file,
+// or replays them from it. Tokens, passwords and Authorization headers are scrubbed,
+// requests are matched on method, path, normalized query and normalized body
+func WithRecorder(path string, mode RecorderMode) Option {
+	return func(c *Client) {
+		transport := c.httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		r := &recorder{path: path, mode: mode, next: transport}
+		r.err = r.load()
+		c.recorder = r
+		c.httpClient.Transport = r
+	}
+}
+
+// cassette is the file format of the recorder
+type cassette struct {
+	Version      int           `json:"version"`
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+	used     bool
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	StatusCode   int                 `json:"status_code"`
+	Headers      map[string][]string `json:"headers,omitempty"`
+	Body         string              `json:"body,omitempty"`
+	BodyEncoding string              `json:"body_encoding,omitempty"` // BodyEncoding is base64 for binary bodies
+}
+
+// recorder is a record-and-replay http.RoundTripper
+type recorder struct {
+	mu       sync.Mutex
+	path     string
+	mode     RecorderMode
+	next     http.RoundTripper
+	cassette cassette
+	replay   bool
+	end      int64 // end is the offset of the end of the last recorded interaction in the cassette file
+	err      error
+}
+
+// load reads the cassette when replaying
+func (r *recorder) load() error {
+	r.cassette = cassette{Version: cassetteVersion}
+	switch r.mode {
+	case RecorderModeRecord:
+		return nil
+	case RecorderModeReplay, RecorderModeReplayOrRecord:
+	default:
+		return errors.Errorf("invalid recorder mode %d", r.mode)
+	}
+	b, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) && r.mode == RecorderModeReplayOrRecord {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "unable to read cassette %s", r.path)
+	}
+	if err := json.Unmarshal(b, &r.cassette); err != nil {
+		return errors.Wrapf(err, "invalid cassette %s", r.path)
+	}
+	if r.cassette.Version != cassetteVersion {
+		return errors.Errorf("unsupported cassette version %d", r.cassette.Version)
+	}
+	r.replay = true
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, body, err := newRecordedRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.replay {
+		return r.replayResponse(req, recorded)
+	}
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err := r.record(recorded, resp, respBody); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *recorder) replayResponse(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var match *interaction
+	for i := range r.cassette.Interactions {
+		it := &r.cassette.Interactions[i]
+		if it.Request != recorded {
+			continue
+		}
+		// the same request is answered in the recorded order, the last answer is repeated
+		match = it
+		if !it.used {
+			break
+		}
+	}
+	if match == nil {
+		return nil, errors.Wrapf(ErrNotRecorded, "%s %s?%s", recorded.Method, recorded.Path, recorded.Query)
+	}
+	match.used = true
+	body := []byte(match.Response.Body)
+	if match.Response.BodyEncoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(match.Response.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "recorder: invalid recorded body")
+		}
+		body = decoded
+	}
+	header := make(http.Header, len(match.Response.Headers))
+	for k, v := range match.Response.Headers {
+		header[k] = append([]string(nil), v...)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *recorder) record(recorded recordedRequest, resp *http.Response, body []byte) error {
+	response := recordedResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    make(map[string][]string),
+	}
+	for k, v := range resp.Header {
+		switch http.CanonicalHeaderKey(k) {
+		case "Authorization", "Set-Cookie", "Date":
+		default:
+			response.Headers[k] = v
+		}
+	}
+	if utf8.Valid(body) {
+		response.Body = string(scrubJSON(body))
+	} else {
+		response.Body = base64.StdEncoding.EncodeToString(body)
+		response.BodyEncoding = "base64"
+	}
+	b, err := json.MarshalIndent(interaction{Request: recorded, Response: response}, "    ", "  ")
+	if err != nil {
+		return errors.Wrap(err, "recorder: unable to marshal interaction")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.appendInteraction(b)
+}
+
+// appendInteraction writes an interaction over the end of the cassette file, which is rewritten after it,
+// so the file stays a valid cassette after every request without being written again entirely
+func (r *recorder) appendInteraction(b []byte) error {
+	var buf bytes.Buffer
+	flag := os.O_WRONLY
+	if r.end == 0 {
+		if dir := filepath.Dir(r.path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return errors.Wrapf(err, "recorder: unable to create dir %s", dir)
+			}
+		}
+		flag |= os.O_CREATE | os.O_TRUNC
+		fmt.Fprintf(&buf, "{\n  \"version\": %d,\n  \"interactions\": [\n", cassetteVersion)
+	} else {
+		buf.WriteString(",\n")
+	}
+	buf.WriteString("    ")
+	buf.Write(b)
+	end := r.end + int64(buf.Len())
+	buf.WriteString("\n  ]\n}\n")
+	f, err := os.OpenFile(r.path, flag, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "recorder: unable to open cassette %s", r.path)
+	}
+	if _, err := f.WriteAt(buf.Bytes(), r.end); err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "recorder: unable to write cassette %s", r.path)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "recorder: unable to write cassette %s", r.path)
+	}
+	r.end = end
+	return nil
+}
+
+// newRecordedRequest normalizes and scrubs a request, it returns the read body to send it again
+func newRecordedRequest(req *http.Request) (recordedRequest, []byte, error) {
+	recorded := recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(), // Encode sorts the keys
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return recorded, nil, errors.Wrap(err, "recorder: unable to read request body")
+	}
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if strings.HasPrefix(mediaType, "multipart/") {
+		recorded.Body, err = normalizeMultipart(body, params["boundary"])
+		return recorded, body, err
+	}
+	recorded.Body = string(scrubJSON(body))
+	return recorded, body, nil
+}
+
+// normalizeMultipart replaces the random boundary of a multipart body with a stable description of its parts
+func normalizeMultipart(body []byte, boundary string) (string, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "recorder: invalid multipart body")
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return "", errors.Wrap(err, "recorder: invalid multipart body")
+		}
+		if part.FileName() != "" {
+			sum := sha256.Sum256(content)
+			parts = append(parts, fmt.Sprintf("%s=@%s;sha256=%s", part.FormName(), part.FileName(), hex.EncodeToString(sum[:])))
+			continue
+		}
+		parts = append(parts, part.FormName()+"="+string(scrubJSON(content)))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n"), nil
+}
+
+// scrubJSON redacts the sensitive values of a JSON document or of one JSON document per line,
+// the documents are compacted keeping their key order and their numbers, other bodies are returned unchanged
+func scrubJSON(body []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var out bytes.Buffer
+	for n := 0; dec.More(); n++ {
+		if n > 0 {
+			out.WriteByte('\n')
+		}
+		if err := scrubToken(dec, &out); err != nil {
+			return body
+		}
+	}
+	if _, err := dec.Token(); err != io.EOF || out.Len() == 0 {
+		return body
+	}
+	return out.Bytes()
+}
+
+// scrubToken copies the next value of dec to out, redacting the values of the sensitive keys
+func scrubToken(dec *json.Decoder, out *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return writeJSON(out, tok)
+	}
+	out.WriteRune(rune(delim))
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if delim == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := writeJSON(out, key); err != nil {
+				return err
+			}
+			out.WriteByte(':')
+			k, _ := key.(string)
+			if _, sensitive := sensitiveKeys[strings.ToLower(k)]; sensitive {
+				var skipped json.RawMessage
+				if err := dec.Decode(&skipped); err != nil {
+					return err
+				}
+				if err := writeJSON(out, redacted); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := scrubToken(dec, out); err != nil {
+			return err
+		}
+	}
+	// the closing delimiter
+	end, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	out.WriteRune(rune(end.(json.Delim)))
+	return nil
+}
+
+// writeJSON writes a scalar without escaping the HTML characters
+func writeJSON(out *bytes.Buffer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	out.Truncate(out.Len() - 1) // Encode ends with a newline
+	return nil
+}
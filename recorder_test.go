@@ -0,0 +1,61 @@
+package goakeneo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	c, srv := newFakeClient(t, WithRecorder(cassettePath, RecorderModeRecord))
+	recorded, err := c.Product.GetProduct("code-9200-eprcg", nil)
+	assert.NoError(t, err)
+	families, _, err := c.Family.ListWithPagination(ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	srv.Close()
+
+	b, err := os.ReadFile(cassettePath)
+	assert.NoError(t, err)
+	auth := c.Auth.(*authOp)
+	for _, secret := range []string{srv.Password, srv.Secret, auth.token, auth.refreshToken} {
+		assert.NotContains(t, string(b), secret)
+	}
+	assert.False(t, strings.Contains(string(b), "Authorization"))
+	var recordedCassette cassette
+	assert.NoError(t, json.Unmarshal(b, &recordedCassette), "the cassette is valid after every request")
+	assert.Len(t, recordedCassette.Interactions, 3)
+
+	// the server is closed, every answer comes from the cassette
+	con := Connector{ClientID: "other", Secret: "other", UserName: srv.Username, Password: "other"}
+	replay, err := con.NewClient(WithBaseURL("http://127.0.0.1:1"), WithRecorder(cassettePath, RecorderModeReplay))
+	assert.NoError(t, err)
+	p, err := replay.Product.GetProduct("code-9200-eprcg", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, recorded, p)
+	replayed, _, err := replay.Family.ListWithPagination(ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, families, replayed)
+
+	_, err = replay.Product.GetProduct("unknown", nil)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
+
+func TestScrubJSON(t *testing.T) {
+	assert.Equal(t, `{"z":1,"id":12345678901234567890,"password":"REDACTED","html":"<b>&</b>","list":[1.50,{"token":"REDACTED"}]}`,
+		string(scrubJSON([]byte(`{"z": 1, "id": 12345678901234567890, "password": {"a": 1}, "html": "<b>&</b>", "list": [1.50, {"token": "abc"}]}`))))
+	assert.Equal(t, "{\"a\":1}\n{\"secret\":\"REDACTED\"}", string(scrubJSON([]byte("{\"a\":1}\n{\"secret\":\"s\"}\n"))))
+	for _, body := range []string{"", "OK", `{"a":1}}`, `{"a":`} {
+		assert.Equal(t, body, string(scrubJSON([]byte(body))))
+	}
+}
+
+func TestRecorderMissingCassette(t *testing.T) {
+	con := Connector{ClientID: "id", Secret: "secret", UserName: "user", Password: "password"}
+	_, err := con.NewClient(WithBaseURL("http://127.0.0.1:1"), WithRecorder(filepath.Join(t.TempDir(), "missing.json"), RecorderModeReplay))
+	assert.ErrorContains(t, err, "unable to read cassette")
+}
@@ -0,0 +1,158 @@
+package goakeneo
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy defines how the failed requests are retried,
+// it is shared by the API requests, the media downloads and the media uploads
+type RetryPolicy struct {
+	MaxAttempts       int           // MaxAttempts is the total number of attempts, 1 disables the retries
+	InitialBackoff    time.Duration // InitialBackoff is the wait time before the first retry
+	MaxBackoff        time.Duration // MaxBackoff caps the exponential backoff and the Retry-After header
+	Jitter            float64       // Jitter is the random fraction removed from the backoff, between 0 and 1
+	RetryableStatuses []int         // RetryableStatuses are the status codes to retry, 429 is always retried
+	// RetryNonIdempotent replays the non-idempotent requests (POST) on network errors and retryable statuses,
+	// they are only retried on 429 otherwise since the server may already have processed them
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by the client when none is given
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    defaultRetry + 1,
+		InitialBackoff: defaultRetryWaitTime,
+		MaxBackoff:     defaultRetryMaxWaitTime,
+		Jitter:         defaultRetryJitter,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the client
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+func (p RetryPolicy) validate() error {
+	switch {
+	case p.MaxAttempts < 1:
+		return errors.New("retry policy max attempts must be at least 1")
+	case p.InitialBackoff < 0 || p.MaxBackoff < p.InitialBackoff:
+		return errors.New("retry policy backoff must be positive and max backoff greater than initial backoff")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return errors.New("retry policy jitter must be between 0 and 1")
+	default:
+		return nil
+	}
+}
+
+// apply sets the policy on a resty client
+func (p RetryPolicy) apply(client *resty.Client) *resty.Client {
+	return client.
+		SetRetryCount(p.MaxAttempts - 1).
+		SetRetryWaitTime(p.InitialBackoff).
+		SetRetryMaxWaitTime(p.MaxBackoff).
+		SetRetryAfter(func(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+			return p.backoff(resp), nil
+		}).
+		AddRetryCondition(p.shouldRetry)
+}
+
+// shouldRetry is a resty retry condition
+func (p RetryPolicy) shouldRetry(resp *resty.Response, err error) bool {
+	idempotent := p.RetryNonIdempotent
+	if resp != nil && resp.Request != nil {
+		if ctxErr := resp.Request.Context().Err(); ctxErr != nil {
+			return false
+		}
+		idempotent = idempotent || isIdempotent(resp.Request.Method)
+	}
+	if errors.Is(err, ErrNotRecorded) {
+		return false
+	}
+	if err != nil {
+		// connection resets and timeouts, the request may have reached the server
+		return idempotent
+	}
+	if resp == nil {
+		return false
+	}
+	status := resp.StatusCode()
+	if status == http.StatusTooManyRequests {
+		// throttled requests are rejected before being processed
+		return true
+	}
+	for _, s := range p.RetryableStatuses {
+		if s == status {
+			return idempotent
+		}
+	}
+	return false
+}
+
+// backoff returns the wait time before the next attempt,
+// the Retry-After header wins over the exponential backoff
+func (p RetryPolicy) backoff(resp *resty.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header().Get("Retry-After"), time.Now()); ok {
+			return capDuration(d, p.MaxBackoff)
+		}
+	}
+	attempt := 1
+	if resp != nil && resp.Request != nil && resp.Request.Attempt > 0 {
+		attempt = resp.Request.Attempt
+	}
+	d := float64(p.InitialBackoff) * math.Exp2(float64(attempt-1))
+	d = math.Min(d, float64(p.MaxBackoff))
+	d -= d * p.Jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+// parseRetryAfter parses a Retry-After header, either a number of seconds or an HTTP date
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// isIdempotent returns true if the request can be replayed safely,
+// akeneo PATCH requests are upserts by code so they are idempotent
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+func capDuration(d, max time.Duration) time.Duration {
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,175 @@
+package goakeneo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialBackoff = time.Millisecond
+	p.MaxBackoff = 5 * time.Millisecond
+	return p
+}
+
+func TestRetryPolicy_TransientStatuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		status    int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"GET retried on 502", http.MethodGet, http.StatusBadGateway, 2, false},
+		{"GET retried on 503", http.MethodGet, http.StatusServiceUnavailable, 2, false},
+		{"PATCH retried on 504", http.MethodPatch, http.StatusGatewayTimeout, 2, false},
+		{"POST retried on 429", http.MethodPost, http.StatusTooManyRequests, 2, false},
+		{"POST not replayed on 503", http.MethodPost, http.StatusServiceUnavailable, 1, true},
+		{"GET not retried on 500", http.MethodGet, http.StatusInternalServerError, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				if calls == 1 {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(`{"code":1,"message":"transient"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{}`))
+			})
+			c := newTestClient(t, srv, WithRetryPolicy(testRetryPolicy()))
+			_, err := c.createAndDoGetHeaders(tt.method, familyBasePath, nil, nil, nil)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestRetryPolicy_MaxAttempts(t *testing.T) {
+	calls := 0
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	p := testRetryPolicy()
+	p.MaxAttempts = 4
+	c := newTestClient(t, srv, WithRetryPolicy(p))
+	_, err := c.Family.GetFamily("shoes", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 4, calls)
+
+	calls = 0
+	c = newTestClient(t, srv, WithRetryPolicy(testRetryPolicy()), WithRetry(0))
+	_, err = c.Family.GetFamily("shoes", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+// retryCounter records the retries and the limiter feedback of a client
+type retryCounter struct {
+	nopInstrumentation
+	mu        sync.Mutex
+	attempts  []int // attempts are the attempts reported to OnRetry
+	throttles int
+	takes     int
+}
+
+func (r *retryCounter) OnRetry(_ context.Context, _ RequestInfo, attempt int, _ int, _ error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+}
+
+func (r *retryCounter) Take() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.takes++
+	return time.Now()
+}
+
+func (r *retryCounter) OnSuccess() {}
+
+func (r *retryCounter) OnThrottle(time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.throttles++
+}
+
+func TestRetryPolicy_HooksCount(t *testing.T) {
+	calls := 0
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	p := testRetryPolicy()
+	p.MaxAttempts = 3
+	counter := &retryCounter{}
+	c := newTestClient(t, srv, WithRetryPolicy(p), WithInstrumentation(counter), WithRateLimiter(counter))
+	_, err := c.Family.GetFamily("shoes", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+	// the last attempt is not a retry
+	assert.Equal(t, []int{1, 2}, counter.attempts)
+	assert.Equal(t, 3, counter.throttles, "every response is reported once to the limiter")
+	assert.Equal(t, 3, counter.takes, "every attempt waits for the rate limit")
+}
+
+func TestRetryPolicy_NetworkError(t *testing.T) {
+	calls := 0
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// reset the connection without answering
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":"shoes"}`))
+	})
+	c := newTestClient(t, srv, WithRetryPolicy(testRetryPolicy()))
+	f, err := c.Family.GetFamily("shoes", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "shoes", f.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{"Sun, 01 Jan 2023 00:00:30 GMT", 30 * time.Second, true},
+		{"Sat, 31 Dec 2022 23:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		d, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.wantOK, ok, tt.value)
+		assert.Equal(t, tt.want, d, tt.value)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(nil))
+	p.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		d := p.backoff(nil)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond, d)
+	}
+	assert.Error(t, RetryPolicy{}.validate())
+	assert.NoError(t, DefaultRetryPolicy().validate())
+}
@@ -0,0 +1,78 @@
+package goakeneo
+
+// WithAuthService replaces the auth service of the client, NewClient grants the token through it
+func WithAuthService(s AuthService) Option {
+	return func(c *Client) {
+		c.Auth = s
+	}
+}
+
+// WithProductService replaces the product service of the client
+func WithProductService(s ProductService) Option {
+	return func(c *Client) {
+		c.Product = s
+	}
+}
+
+// WithFamilyService replaces the family service of the client
+func WithFamilyService(s FamilyService) Option {
+	return func(c *Client) {
+		c.Family = s
+	}
+}
+
+// WithAttributeService replaces the attribute service of the client
+func WithAttributeService(s AttributeService) Option {
+	return func(c *Client) {
+		c.Attribute = s
+	}
+}
+
+// WithAttributeGroupService replaces the attribute group service of the client
+func WithAttributeGroupService(s AttributeGroupService) Option {
+	return func(c *Client) {
+		c.AttributeGroup = s
+	}
+}
+
+// WithCategoryService replaces the category service of the client
+func WithCategoryService(s CategoryService) Option {
+	return func(c *Client) {
+		c.Category = s
+	}
+}
+
+// WithChannelService replaces the channel service of the client
+func WithChannelService(s ChannelService) Option {
+	return func(c *Client) {
+		c.Channel = s
+	}
+}
+
+// WithLocaleService replaces the locale service of the client
+func WithLocaleService(s LocaleService) Option {
+	return func(c *Client) {
+		c.Locale = s
+	}
+}
+
+// WithCurrencyService replaces the currency service of the client
+func WithCurrencyService(s CurrencyService) Option {
+	return func(c *Client) {
+		c.Currency = s
+	}
+}
+
+// WithMediaFileService replaces the media file service of the client
+func WithMediaFileService(s MediaFileService) Option {
+	return func(c *Client) {
+		c.MediaFile = s
+	}
+}
+
+// WithProductModelService replaces the product model service of the client
+func WithProductModelService(s ProductModelService) Option {
+	return func(c *Client) {
+		c.ProductModel = s
+	}
+}
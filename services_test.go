@@ -0,0 +1,35 @@
+package goakeneo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAuth struct {
+	grants int
+}
+
+func (s *stubAuth) GrantByPassword() error     { s.grants++; return nil }
+func (s *stubAuth) GrantByRefreshToken() error { return nil }
+func (s *stubAuth) ShouldRefreshToken() bool   { return false }
+func (s *stubAuth) AutoRefreshToken() error    { return nil }
+
+type stubLocales struct{}
+
+func (stubLocales) ListWithPagination(options any) ([]Locale, Links, error) {
+	return []Locale{{Code: "en_US", Enabled: true}}, Links{}, nil
+}
+
+func TestServiceOptions(t *testing.T) {
+	auth := &stubAuth{}
+	con := Connector{ClientID: "id", Secret: "secret", UserName: "user", Password: "password"}
+	c, err := con.NewClient(WithBaseURL("http://127.0.0.1:1"), WithAuthService(auth), WithLocaleService(stubLocales{}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, auth.grants)
+	locales, _, err := c.Locale.ListWithPagination(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "en_US", locales[0].Code)
+	// the services which are not replaced call the PIM
+	assert.IsType(t, &productOp{}, c.Product)
+}
@@ -0,0 +1,45 @@
+package goakeneo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const systemInformationBasePath = "/api/rest/v1/system-information"
+
+// SystemInformation is the version and the edition of a PIM
+type SystemInformation struct {
+	Version string `json:"version" mapstructure:"version"`
+	Edition string `json:"edition" mapstructure:"edition"` // Edition is CE, EE or Serenity
+}
+
+// SystemInformation reads the version of the PIM, it returns nil without error
+// when the PIM has no system information endpoint, which was added in Akeneo 7
+func (c *Client) SystemInformation(ctx context.Context) (*SystemInformation, error) {
+	if err := c.Auth.AutoRefreshToken(); err != nil {
+		return nil, err
+	}
+	info := new(SystemInformation)
+	var errResp ErrorResponse
+	request := c.restyClient().R().
+		SetHeader("Accept", defaultAccept).
+		SetHeader("User-Agent", defaultUserAgent).
+		SetAuthToken(c.accessToken()).
+		SetResult(info).
+		SetError(&errResp)
+	u := c.baseURL.ResolveReference(&url.URL{Path: systemInformationBasePath})
+	resp, err := c.execute(ctx, request, http.MethodGet, u)
+	if err != nil {
+		return nil, errors.Wrap(err, "resty execute error")
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.IsError() {
+		return nil, errors.Errorf("request error : %s", errResp.Message)
+	}
+	return info, nil
+}
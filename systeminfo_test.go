@@ -0,0 +1,26 @@
+package goakeneo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SystemInformation(t *testing.T) {
+	c, srv := newFakeClient(t, WithRateLimit(1000, time.Second))
+	info, err := c.SystemInformation(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &SystemInformation{Version: "7.0.0", Edition: "CE"}, info)
+
+	// before Akeneo 7 the endpoint does not exist, the manifest only has the version of the client
+	srv.Version = ""
+	info, err = c.SystemInformation(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+	manifest, err := c.Export(context.Background(), t.TempDir(), ExportOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, manifest.PIMVersion)
+	assert.Equal(t, "6.0", manifest.ClientVersion)
+}
@@ -2,6 +2,8 @@ package goakeneo
 
 import (
 	"net/url"
+	"os"
+	"path/filepath"
 
 	"github.com/google/go-querystring/query"
 	"github.com/pkg/errors"
@@ -15,3 +17,28 @@ func structToURLValues(s interface{}) (url.Values, error) {
 	}
 	return v, nil
 }
+
+// writeFileAtomic writes b to a temporary file next to path and renames it,
+// readers never see a partially written file
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create dir, path: %s", dir)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file, path: %s", path)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "failed to write file, path: %s", path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write file, path: %s", path)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed to rename file, path: %s", path)
+	}
+	return nil
+}
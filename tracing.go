@@ -0,0 +1,93 @@
+package goakeneo
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer starts spans, it is the subset of an OpenTelemetry tracer used by SpanHooks,
+// an OpenTelemetry trace.Tracer can be adapted in a few lines
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes map[string]any) (context.Context, Span)
+}
+
+// Span is a started span
+type Span interface {
+	// AddEvent records an event on the span, i.e. a retry
+	AddEvent(name string, attributes map[string]any)
+	// End ends the span with the final attributes, err is nil when the request succeeded
+	End(attributes map[string]any, err error)
+}
+
+// Span attribute keys, they follow the OpenTelemetry semantic conventions when one exists
+const (
+	SpanAttrResourceType = "akeneo.resource.type"
+	SpanAttrResourceID   = "akeneo.resource.id"
+	SpanAttrMethod       = "http.request.method"
+	SpanAttrPath         = "url.path"
+	SpanAttrRoute        = "http.route"
+	SpanAttrStatusCode   = "http.response.status_code"
+	SpanAttrAttempts     = "http.request.resend_count"
+	SpanAttrBytes        = "http.response.body.size"
+	SpanAttrWait         = "akeneo.rate_limit.wait_ms"
+)
+
+// SpanHooks is an Instrumentation creating one span per request,
+// spans carry the akeneo resource type and identifier
+type SpanHooks struct {
+	Tracer Tracer
+}
+
+type spanContextKey struct{}
+
+// OnRequestStart implements Instrumentation
+func (s SpanHooks) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	ctx, span := s.Tracer.Start(ctx, "akeneo "+info.Method+" "+info.Endpoint, map[string]any{
+		SpanAttrResourceType: info.ResourceType,
+		SpanAttrResourceID:   info.ResourceID,
+		SpanAttrMethod:       info.Method,
+		SpanAttrPath:         info.Path,
+		SpanAttrRoute:        info.Endpoint,
+	})
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// OnRequestEnd implements Instrumentation
+func (s SpanHooks) OnRequestEnd(ctx context.Context, _ RequestInfo, result RequestResult) {
+	span, ok := ctx.Value(spanContextKey{}).(Span)
+	if !ok {
+		return
+	}
+	span.End(map[string]any{
+		SpanAttrStatusCode: result.StatusCode,
+		SpanAttrAttempts:   result.Attempts,
+		SpanAttrBytes:      result.Bytes,
+		SpanAttrWait:       result.RateLimitWait.Milliseconds(),
+	}, result.Err)
+}
+
+// OnRetry implements Instrumentation
+func (s SpanHooks) OnRetry(ctx context.Context, _ RequestInfo, attempt int, statusCode int, err error) {
+	span, ok := ctx.Value(spanContextKey{}).(Span)
+	if !ok {
+		return
+	}
+	attributes := map[string]any{
+		"attempt":          attempt,
+		SpanAttrStatusCode: statusCode,
+	}
+	if err != nil {
+		attributes["error"] = err.Error()
+	}
+	span.AddEvent("retry", attributes)
+}
+
+// OnAuth implements Instrumentation
+func (s SpanHooks) OnAuth(grantType string, duration time.Duration, err error) {
+	_, span := s.Tracer.Start(context.Background(), "akeneo auth", map[string]any{
+		SpanAttrResourceType: "token",
+		"akeneo.grant_type":  grantType,
+		"duration_ms":        duration.Milliseconds(),
+	})
+	span.End(nil, err)
+}
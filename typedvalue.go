@@ -0,0 +1,116 @@
+package goakeneo
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Decimal is a number as sent by the PIM, integers are numbers and decimals are strings in the API
+type Decimal string
+
+// Float64 returns the decimal as a float64
+func (d Decimal) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(string(d), 64)
+	return f, errors.Wrapf(err, "invalid decimal %q", string(d))
+}
+
+// Metric is the data of a pim_catalog_metric value
+type Metric struct {
+	Amount Decimal `json:"amount"`
+	Unit   string  `json:"unit"`
+}
+
+// Price is an amount in a currency of a pim_catalog_price_collection value
+type Price struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// DecodeText decodes the data of a text, date, file, image or simple select value
+func DecodeText(data any) (string, error) {
+	s, ok := data.(string)
+	if !ok {
+		return "", errors.Errorf("string expected, got %T", data)
+	}
+	return s, nil
+}
+
+// DecodeTexts decodes the data of a multi select or asset collection value
+func DecodeTexts(data any) ([]string, error) {
+	s, ok := toStrings(data)
+	if !ok {
+		return nil, errors.Errorf("list of strings expected, got %T", data)
+	}
+	return s, nil
+}
+
+// DecodeBool decodes the data of a boolean value
+func DecodeBool(data any) (bool, error) {
+	b, ok := data.(bool)
+	if !ok {
+		return false, errors.Errorf("boolean expected, got %T", data)
+	}
+	return b, nil
+}
+
+// DecodeDecimal decodes the data of a number value
+func DecodeDecimal(data any) (Decimal, error) {
+	switch n := data.(type) {
+	case string:
+		return Decimal(n), nil
+	case json.Number:
+		return Decimal(n), nil
+	case float64:
+		return Decimal(strconv.FormatFloat(n, 'f', -1, 64)), nil
+	case int:
+		return Decimal(strconv.Itoa(n)), nil
+	case Decimal:
+		return n, nil
+	default:
+		return "", errors.Errorf("number expected, got %T", data)
+	}
+}
+
+// DecodeMetric decodes the data of a metric value
+func DecodeMetric(data any) (Metric, error) {
+	if m, ok := data.(Metric); ok {
+		return m, nil
+	}
+	obj, ok := data.(map[string]any)
+	if !ok {
+		return Metric{}, errors.Errorf("metric expected, got %T", data)
+	}
+	amount, err := DecodeDecimal(obj["amount"])
+	if err != nil {
+		return Metric{}, err
+	}
+	unit, _ := obj["unit"].(string)
+	return Metric{Amount: amount, Unit: unit}, nil
+}
+
+// DecodePrices decodes the data of a price collection value
+func DecodePrices(data any) ([]Price, error) {
+	if prices, ok := data.([]Price); ok {
+		return prices, nil
+	}
+	list, ok := data.([]any)
+	if !ok {
+		return nil, errors.Errorf("list of prices expected, got %T", data)
+	}
+	prices := make([]Price, 0, len(list))
+	for _, e := range list {
+		obj, ok := e.(map[string]any)
+		if !ok {
+			return nil, errors.Errorf("price expected, got %T", e)
+		}
+		amount, err := DecodeDecimal(obj["amount"])
+		if err != nil {
+			return nil, err
+		}
+		currency, _ := obj["currency"].(string)
+		prices = append(prices, Price{Amount: amount, Currency: currency})
+	}
+	return prices, nil
+}
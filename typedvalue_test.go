@@ -0,0 +1,35 @@
+package goakeneo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeTypedValues(t *testing.T) {
+	d, err := DecodeDecimal(float64(12))
+	assert.NoError(t, err)
+	assert.Equal(t, Decimal("12"), d)
+	d, err = DecodeDecimal(json.Number("0.8500"))
+	assert.NoError(t, err)
+	f, err := d.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.85, f)
+	_, err = DecodeDecimal(true)
+	assert.Error(t, err)
+
+	m, err := DecodeMetric(map[string]any{"amount": "0.8500", "unit": "KILOGRAM"})
+	assert.NoError(t, err)
+	assert.Equal(t, Metric{Amount: "0.8500", Unit: "KILOGRAM"}, m)
+	prices, err := DecodePrices([]any{map[string]any{"amount": float64(10), "currency": "EUR"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []Price{{Amount: "10", Currency: "EUR"}}, prices)
+	texts, err := DecodeTexts([]any{"red", "blue"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"red", "blue"}, texts)
+	_, err = DecodeText(12)
+	assert.Error(t, err)
+	_, err = DecodeBool("yes")
+	assert.Error(t, err)
+}
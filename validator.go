@@ -0,0 +1,485 @@
+package goakeneo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CatalogDefinitions are the definitions used by ProductValidator and CompletenessCalculator
+type CatalogDefinitions struct {
+	Families       []Family
+	FamilyVariants map[string][]FamilyVariant // FamilyVariants are the variants by family code
+	Attributes     []Attribute
+	Options        []AttributeOption // Options are the options of the select attributes with their Attribute set
+	Channels       []Channel
+	Locales        []Locale
+}
+
+// LoadCatalogDefinitions lists the definitions of the PIM, they are meant to be cached by the caller
+func (c *Client) LoadCatalogDefinitions(ctx context.Context) (*CatalogDefinitions, error) {
+	defs := &CatalogDefinitions{FamilyVariants: make(map[string][]FamilyVariant)}
+	err := listAll(ctx, nil, c.Family.ListWithPagination, func(f Family) error {
+		defs.Families = append(defs.Families, f)
+		variants, err := allFamilyVariants(ctx, c.Family, f.Code)
+		if err != nil {
+			return err
+		}
+		if len(variants) > 0 {
+			defs.FamilyVariants[f.Code] = variants
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load families")
+	}
+	err = listAll(ctx, nil, c.Attribute.ListWithPagination, func(a Attribute) error {
+		defs.Attributes = append(defs.Attributes, a)
+		if !selectAttributeTypes[a.Type] {
+			return nil
+		}
+		list := func(options any) ([]AttributeOption, Links, error) {
+			return c.Attribute.GetAttributeOptions(a.Code, options)
+		}
+		return listAll(ctx, AttributeOptionListOptions{Limit: defaultExportPageSize}, list, func(o AttributeOption) error {
+			o.Attribute = a.Code
+			defs.Options = append(defs.Options, o)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load attributes")
+	}
+	err = listAll(ctx, nil, c.Channel.ListWithPagination, func(ch Channel) error {
+		defs.Channels = append(defs.Channels, ch)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load channels")
+	}
+	err = listAll(ctx, nil, c.Locale.ListWithPagination, func(l Locale) error {
+		defs.Locales = append(defs.Locales, l)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load locales")
+	}
+	return defs, nil
+}
+
+// ValidationError is a value or a property of a product the PIM would reject
+type ValidationError struct {
+	Property  string `json:"property"` // Property is "values" for the errors of a value
+	Attribute string `json:"attribute,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Attribute == "" {
+		return e.Property + ": " + e.Message
+	}
+	return fmt.Sprintf("%s[%s,locale=%s,scope=%s]: %s", e.Property, e.Attribute, e.Locale, e.Scope, e.Message)
+}
+
+// ValidationErrors are the errors of a product
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ProductValidator checks products locally against the definitions of the catalog,
+// it is safe for concurrent use
+type ProductValidator struct {
+	families   map[string]Family
+	attributes map[string]Attribute
+	options    map[string]map[string]bool // options by attribute, only for the attributes having options
+	channels   map[string]Channel
+	locales    map[string]bool           // locales are the enabled locales
+	regexps    map[string]*regexp.Regexp // regexps are the validation regexps which compile in Go
+}
+
+// NewProductValidator returns a validator of the definitions
+func NewProductValidator(defs CatalogDefinitions) *ProductValidator {
+	v := &ProductValidator{
+		families:   make(map[string]Family, len(defs.Families)),
+		attributes: make(map[string]Attribute, len(defs.Attributes)),
+		options:    make(map[string]map[string]bool),
+		channels:   make(map[string]Channel, len(defs.Channels)),
+		locales:    make(map[string]bool, len(defs.Locales)),
+		regexps:    make(map[string]*regexp.Regexp),
+	}
+	for _, f := range defs.Families {
+		v.families[f.Code] = f
+	}
+	for _, a := range defs.Attributes {
+		v.attributes[a.Code] = a
+		if a.ValidationRegexp == "" {
+			continue
+		}
+		// PCRE features such as lookarounds are not supported by Go, the PIM checks those values
+		if re, err := compilePIMRegexp(a.ValidationRegexp); err == nil {
+			v.regexps[a.Code] = re
+		}
+	}
+	for _, o := range defs.Options {
+		if v.options[o.Attribute] == nil {
+			v.options[o.Attribute] = make(map[string]bool)
+		}
+		v.options[o.Attribute][o.Code] = true
+	}
+	for _, c := range defs.Channels {
+		v.channels[c.Code] = c
+	}
+	for _, l := range defs.Locales {
+		if l.Enabled {
+			v.locales[l.Code] = true
+		}
+	}
+	return v
+}
+
+// Validate returns the ValidationErrors of a product, nil when the PIM should accept it.
+// The required attributes of the family are not checked for the variant products,
+// their values may be held by the parent product models
+func (v *ProductValidator) Validate(p Product) error {
+	var errs ValidationErrors
+	if p.Identifier == "" && p.UUID == "" {
+		errs = append(errs, ValidationError{Property: "identifier", Message: "the identifier or the uuid is required"})
+	}
+	family, hasFamily := v.families[p.Family]
+	if p.Family != "" && !hasFamily {
+		errs = append(errs, ValidationError{Property: "family", Message: fmt.Sprintf("family %q does not exist", p.Family)})
+	}
+	var inFamily map[string]bool
+	if hasFamily {
+		inFamily = make(map[string]bool, len(family.Attributes))
+		for _, code := range family.Attributes {
+			inFamily[code] = true
+		}
+	}
+	for _, code := range sortedKeys(p.Values) {
+		attribute, ok := v.attributes[code]
+		for _, value := range p.Values[code] {
+			fail := func(format string, args ...any) {
+				errs = append(errs, ValidationError{
+					Property: "values", Attribute: code, Locale: value.Locale, Scope: value.Scope,
+					Message: fmt.Sprintf(format, args...),
+				})
+			}
+			switch {
+			case !ok:
+				fail("attribute %q does not exist", code)
+				continue
+			case inFamily != nil && !inFamily[code] && attribute.Type != identifierAttributeType:
+				fail("attribute %q is not in family %q", code, p.Family)
+			}
+			v.checkContext(attribute, value, fail)
+			if value.Data != nil {
+				v.checkData(attribute, value.Data, fail)
+			}
+		}
+	}
+	if hasFamily && p.Parent == "" {
+		errs = append(errs, v.checkRequirements(family, p.Values)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkContext checks the locale and the scope of a value
+func (v *ProductValidator) checkContext(a Attribute, value ProductValue, fail func(string, ...any)) {
+	switch {
+	case a.Localizable && value.Locale == "":
+		fail("a locale is required, the attribute is localizable")
+	case !a.Localizable && value.Locale != "":
+		fail("the attribute is not localizable, the locale must be null")
+	case a.Localizable && !v.locales[value.Locale]:
+		fail("locale %q is not enabled", value.Locale)
+	case a.Localizable && len(a.AvailableLocales) > 0 && !contains(a.AvailableLocales, value.Locale):
+		fail("locale %q is not available for the attribute", value.Locale)
+	}
+	switch {
+	case a.Scopable && value.Scope == "":
+		fail("a scope is required, the attribute is scopable")
+	case !a.Scopable && value.Scope != "":
+		fail("the attribute is not scopable, the scope must be null")
+	case a.Scopable:
+		channel, ok := v.channels[value.Scope]
+		if !ok {
+			fail("channel %q does not exist", value.Scope)
+		} else if a.Localizable && value.Locale != "" && !contains(channel.Locales, value.Locale) {
+			fail("locale %q is not activated for channel %q", value.Locale, value.Scope)
+		}
+	}
+}
+
+// checkData checks the data of a value according to the type of its attribute
+func (v *ProductValidator) checkData(a Attribute, data any, fail func(string, ...any)) {
+	switch a.Type {
+	case "pim_catalog_text", "pim_catalog_textarea", identifierAttributeType:
+		s, ok := data.(string)
+		if !ok {
+			fail("a string is expected")
+			return
+		}
+		if a.MaxCharacters > 0 && utf8.RuneCountInString(s) > a.MaxCharacters {
+			fail("%d characters exceed the maximum of %d", utf8.RuneCountInString(s), a.MaxCharacters)
+		}
+		if re := v.regexps[a.Code]; re != nil && !re.MatchString(s) {
+			fail("%q does not match %s", s, a.ValidationRegexp)
+		}
+	case "pim_catalog_number":
+		checkNumber(a, data, fail)
+	case "pim_catalog_metric":
+		m, ok := data.(map[string]any)
+		if !ok {
+			fail("an object with an amount and a unit is expected")
+			return
+		}
+		if unit, _ := m["unit"].(string); unit == "" {
+			fail("a unit is required")
+		}
+		checkNumber(a, m["amount"], fail)
+	case "pim_catalog_price_collection":
+		prices, ok := data.([]any)
+		if !ok {
+			fail("a list of prices is expected")
+			return
+		}
+		for _, p := range prices {
+			m, _ := p.(map[string]any)
+			if currency, _ := m["currency"].(string); currency == "" {
+				fail("a currency is required")
+				continue
+			}
+			checkNumber(a, m["amount"], fail)
+		}
+	case "pim_catalog_date":
+		checkDate(a, data, fail)
+	case "pim_catalog_file", "pim_catalog_image":
+		s, ok := data.(string)
+		if !ok {
+			fail("a file path is expected")
+			return
+		}
+		ext := strings.TrimPrefix(strings.ToLower(path.Ext(s)), ".")
+		if len(a.AllowedExtensions) > 0 && !contains(a.AllowedExtensions, ext) {
+			fail("extension %q is not allowed, allowed extensions are %s", ext, strings.Join(a.AllowedExtensions, ", "))
+		}
+	case "pim_catalog_boolean":
+		if _, ok := data.(bool); !ok {
+			fail("a boolean is expected")
+		}
+	case "pim_catalog_simpleselect":
+		s, ok := data.(string)
+		if !ok {
+			fail("an option code is expected")
+			return
+		}
+		v.checkOptions(a, []string{s}, fail)
+	case "pim_catalog_multiselect":
+		codes, ok := toStrings(data)
+		if !ok {
+			fail("a list of option codes is expected")
+			return
+		}
+		v.checkOptions(a, codes, fail)
+	}
+}
+
+// checkOptions checks the options exist, when the options of the attribute are known
+func (v *ProductValidator) checkOptions(a Attribute, codes []string, fail func(string, ...any)) {
+	options, known := v.options[a.Code]
+	if !known {
+		return
+	}
+	for _, code := range codes {
+		// option codes are case insensitive in the PIM
+		if !options[code] && !containsFold(options, code) {
+			fail("option %q does not exist", code)
+		}
+	}
+}
+
+// checkRequirements checks the required attributes of the family have a value for each channel and locale
+func (v *ProductValidator) checkRequirements(f Family, values map[string][]ProductValue) ValidationErrors {
+	var errs ValidationErrors
+	for _, scope := range sortedKeys(f.AttributeRequirements) {
+		channel, ok := v.channels[scope]
+		if !ok {
+			continue
+		}
+		for _, code := range f.AttributeRequirements[scope] {
+			a, ok := v.attributes[code]
+			if !ok {
+				continue
+			}
+			locales := []string{""}
+			if a.Localizable {
+				locales = channel.Locales
+			}
+			for _, locale := range locales {
+				if !hasValue(values[code], a, locale, scope) {
+					errs = append(errs, ValidationError{
+						Property: "values", Attribute: code, Locale: locale, Scope: scope,
+						Message: fmt.Sprintf("attribute %q is required by family %q", code, f.Code),
+					})
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func hasValue(values []ProductValue, a Attribute, locale, scope string) bool {
+	for _, v := range values {
+		if a.Scopable && v.Scope != scope || v.Locale != locale {
+			continue
+		}
+		if !isEmptyValue(v.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNumber checks a number against the numeric constraints of the attribute
+func checkNumber(a Attribute, data any, fail func(string, ...any)) {
+	n, ok := toFloat(data)
+	if !ok {
+		fail("a number is expected")
+		return
+	}
+	if !a.DecimalsAllowed && n != math.Trunc(n) {
+		fail("decimals are not allowed")
+	}
+	if !a.NegativeAllowed && n < 0 {
+		fail("negative numbers are not allowed")
+	}
+	if lo, ok := toFloat(a.NumberMin); ok && n < lo {
+		fail("%v is lower than the minimum %s", data, a.NumberMin)
+	}
+	if hi, ok := toFloat(a.NumberMax); ok && n > hi {
+		fail("%v is greater than the maximum %s", data, a.NumberMax)
+	}
+}
+
+// checkDate checks a date against the date range of the attribute
+func checkDate(a Attribute, data any, fail func(string, ...any)) {
+	s, _ := data.(string)
+	date, ok := parsePIMDate(s)
+	if !ok {
+		fail("a date in ISO-8601 format is expected")
+		return
+	}
+	if lo, ok := parsePIMDate(a.DateMin); ok && date.Before(lo) {
+		fail("%s is before the minimum %s", s, a.DateMin)
+	}
+	if hi, ok := parsePIMDate(a.DateMax); ok && date.After(hi) {
+		fail("%s is after the maximum %s", s, a.DateMax)
+	}
+}
+
+// parsePIMDate parses the dates of the values and of the attribute definitions
+func parsePIMDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compilePIMRegexp compiles a PHP regexp such as "/^[A-Z]+$/i"
+func compilePIMRegexp(expr string) (*regexp.Regexp, error) {
+	pattern, flags := expr, ""
+	if end := strings.LastIndex(expr, "/"); strings.HasPrefix(expr, "/") && end > 0 {
+		pattern, flags = expr[1:end], expr[end+1:]
+	}
+	var prefix string
+	for _, f := range flags {
+		if strings.ContainsRune("imsU", f) {
+			prefix += string(f)
+		}
+	}
+	if prefix != "" {
+		pattern = "(?" + prefix + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toStrings(v any) ([]string, bool) {
+	switch t := v.(type) {
+	case []string:
+		return t, true
+	case []any:
+		s := make([]string, len(t))
+		for i, e := range t {
+			code, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			s[i] = code
+		}
+		return s, true
+	default:
+		return nil, false
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(set map[string]bool, s string) bool {
+	for e := range set {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package goakeneo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_LoadCatalogDefinitions(t *testing.T) {
+	c, _ := newFakeClient(t, WithRateLimit(1000, time.Second))
+	defs, err := c.LoadCatalogDefinitions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, defs.Families, 2)
+	assert.Len(t, defs.Attributes, 10)
+	assert.Len(t, defs.Options, 4)
+	assert.Len(t, defs.Channels, 2)
+	assert.Len(t, defs.Locales, 3)
+
+	p, err := c.Product.GetProduct("runner-40", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, NewProductValidator(*defs).Validate(*p))
+}
+
+func testDefinitions() CatalogDefinitions {
+	return CatalogDefinitions{
+		Families: []Family{{
+			Code:                  "shoes",
+			Attributes:            []string{"sku", "name", "ean", "weight", "price", "release", "image", "color"},
+			AttributeRequirements: map[string][]string{"ecommerce": {"sku", "name"}},
+		}},
+		Attributes: []Attribute{
+			{Code: "sku", Type: "pim_catalog_identifier"},
+			{Code: "name", Type: "pim_catalog_text", Localizable: true, Scopable: true, MaxCharacters: 10},
+			{Code: "ean", Type: "pim_catalog_text", ValidationRule: "regexp", ValidationRegexp: "/^[0-9]{13}$/"},
+			{Code: "weight", Type: "pim_catalog_metric", DecimalsAllowed: true, NumberMax: "100"},
+			{Code: "price", Type: "pim_catalog_price_collection"},
+			{Code: "release", Type: "pim_catalog_date", DateMin: "2020-01-01T00:00:00+00:00"},
+			{Code: "image", Type: "pim_catalog_image", AllowedExtensions: []string{"jpg", "png"}},
+			{Code: "color", Type: "pim_catalog_simpleselect"},
+			{Code: "legacy", Type: "pim_catalog_text", Localizable: true, AvailableLocales: []string{"en_US"}},
+		},
+		Options:  []AttributeOption{{Attribute: "color", Code: "red"}},
+		Channels: []Channel{{Code: "ecommerce", Locales: []string{"en_US", "fr_FR"}}},
+		Locales:  []Locale{{Code: "en_US", Enabled: true}, {Code: "fr_FR", Enabled: true}, {Code: "de_DE"}},
+	}
+}
+
+func TestProductValidator_Validate(t *testing.T) {
+	v := NewProductValidator(testDefinitions())
+	valid := Product{
+		Identifier: "sku-1",
+		Family:     "shoes",
+		Values: map[string][]ProductValue{
+			"sku": {{Data: "sku-1"}},
+			"name": {
+				{Locale: "en_US", Scope: "ecommerce", Data: "Runner"},
+				{Locale: "fr_FR", Scope: "ecommerce", Data: "Coureur"},
+			},
+			"ean":     {{Data: "4006381333931"}},
+			"weight":  {{Data: map[string]any{"amount": "0.85", "unit": "KILOGRAM"}}},
+			"price":   {{Data: []any{map[string]any{"amount": 10, "currency": "EUR"}}}},
+			"release": {{Data: "2023-03-01T00:00:00+00:00"}},
+			"image":   {{Data: "1/2/3/4/1234_runner.PNG"}},
+			"color":   {{Data: "RED"}},
+		},
+	}
+	assert.NoError(t, v.Validate(valid))
+
+	invalid := Product{
+		Family: "shoes",
+		Values: map[string][]ProductValue{
+			"sku":     {{Data: "sku-1"}},
+			"name":    {{Locale: "en_US", Data: "A name much too long"}, {Locale: "de_DE", Scope: "ecommerce", Data: "Läufer"}},
+			"ean":     {{Locale: "en_US", Data: "123"}},
+			"weight":  {{Data: map[string]any{"amount": 150, "unit": "KILOGRAM"}}},
+			"price":   {{Data: []any{map[string]any{"amount": "-9.99", "currency": "EUR"}}}},
+			"release": {{Data: "2019-12-31"}},
+			"image":   {{Data: "manual.pdf"}},
+			"color":   {{Data: "green"}},
+			"legacy":  {{Locale: "fr_FR", Data: "Ancien"}},
+			"unknown": {{Data: "x"}},
+		},
+	}
+	err := v.Validate(invalid)
+	errs, ok := err.(ValidationErrors)
+	if !assert.True(t, ok, "%v", err) {
+		return
+	}
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Error()
+	}
+	assert.Equal(t, []string{
+		"identifier: the identifier or the uuid is required",
+		`values[color,locale=,scope=]: option "green" does not exist`,
+		"values[ean,locale=en_US,scope=]: the attribute is not localizable, the locale must be null",
+		`values[ean,locale=en_US,scope=]: "123" does not match /^[0-9]{13}$/`,
+		`values[image,locale=,scope=]: extension "pdf" is not allowed, allowed extensions are jpg, png`,
+		`values[legacy,locale=fr_FR,scope=]: attribute "legacy" is not in family "shoes"`,
+		`values[legacy,locale=fr_FR,scope=]: locale "fr_FR" is not available for the attribute`,
+		"values[name,locale=en_US,scope=]: a scope is required, the attribute is scopable",
+		"values[name,locale=en_US,scope=]: 20 characters exceed the maximum of 10",
+		`values[name,locale=de_DE,scope=ecommerce]: locale "de_DE" is not enabled`,
+		`values[name,locale=de_DE,scope=ecommerce]: locale "de_DE" is not activated for channel "ecommerce"`,
+		"values[price,locale=,scope=]: decimals are not allowed",
+		"values[price,locale=,scope=]: negative numbers are not allowed",
+		`values[release,locale=,scope=]: 2019-12-31 is before the minimum 2020-01-01T00:00:00+00:00`,
+		`values[unknown,locale=,scope=]: attribute "unknown" does not exist`,
+		"values[weight,locale=,scope=]: 150 is greater than the maximum 100",
+		`values[name,locale=en_US,scope=ecommerce]: attribute "name" is required by family "shoes"`,
+		`values[name,locale=fr_FR,scope=ecommerce]: attribute "name" is required by family "shoes"`,
+	}, messages)
+}
+
+func TestCompilePIMRegexp(t *testing.T) {
+	re, err := compilePIMRegexp("/^[a-z]+$/i")
+	assert.NoError(t, err)
+	assert.True(t, re.MatchString("ABC"))
+	re, err = compilePIMRegexp("^[0-9]+$")
+	assert.NoError(t, err)
+	assert.False(t, re.MatchString("12a"))
+	_, err = compilePIMRegexp("/[/")
+	assert.Error(t, err)
+}
+
+func TestProductValidator_UnsupportedRegexp(t *testing.T) {
+	defs := testDefinitions()
+	defs.Attributes[2].ValidationRegexp = `/^(?!000)[0-9]{13}$/`
+	v := NewProductValidator(defs)
+	p := Product{Identifier: "sku-1", Family: "shoes", Values: map[string][]ProductValue{
+		"sku":  {{Data: "sku-1"}},
+		"name": {{Locale: "en_US", Scope: "ecommerce", Data: "Runner"}, {Locale: "fr_FR", Scope: "ecommerce", Data: "Coureur"}},
+		"ean":  {{Data: "4006381333931"}},
+	}}
+	assert.NoError(t, v.Validate(p), "a lookahead can not be checked in Go and is left to the PIM")
+}
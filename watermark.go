@@ -0,0 +1,99 @@
+package goakeneo
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WatermarkStore persists the high-watermark of each synchronized resource
+type WatermarkStore interface {
+	// Load returns the watermark of a resource, ok is false when the resource was never synchronized
+	Load(resource string) (watermark time.Time, ok bool, err error)
+	// Save stores the watermark of a resource
+	Save(resource string, watermark time.Time) error
+}
+
+// MemoryWatermarkStore keeps the watermarks in memory
+type MemoryWatermarkStore struct {
+	mu         sync.Mutex
+	watermarks map[string]time.Time
+}
+
+// NewMemoryWatermarkStore returns an empty memory store
+func NewMemoryWatermarkStore() *MemoryWatermarkStore {
+	return &MemoryWatermarkStore{watermarks: make(map[string]time.Time)}
+}
+
+// Load implements WatermarkStore
+func (s *MemoryWatermarkStore) Load(resource string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.watermarks[resource]
+	return w, ok, nil
+}
+
+// Save implements WatermarkStore
+func (s *MemoryWatermarkStore) Save(resource string, watermark time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watermarks[resource] = watermark
+	return nil
+}
+
+// FileWatermarkStore keeps the watermarks in a JSON file, the file is replaced atomically on save
+type FileWatermarkStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileWatermarkStore returns a store backed by the JSON file at path, the file is created on the first save
+func NewFileWatermarkStore(path string) *FileWatermarkStore {
+	return &FileWatermarkStore{path: path}
+}
+
+// Load implements WatermarkStore
+func (s *FileWatermarkStore) Load(resource string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	watermarks, err := s.read()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	w, ok := watermarks[resource]
+	return w, ok, nil
+}
+
+// Save implements WatermarkStore
+func (s *FileWatermarkStore) Save(resource string, watermark time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	watermarks, err := s.read()
+	if err != nil {
+		return err
+	}
+	watermarks[resource] = watermark
+	b, err := json.MarshalIndent(watermarks, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal watermarks")
+	}
+	return writeFileAtomic(s.path, b)
+}
+
+func (s *FileWatermarkStore) read() (map[string]time.Time, error) {
+	watermarks := make(map[string]time.Time)
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return watermarks, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read watermarks %s", s.path)
+	}
+	if err := json.Unmarshal(b, &watermarks); err != nil {
+		return nil, errors.Wrapf(err, "invalid watermarks %s", s.path)
+	}
+	return watermarks, nil
+}